@@ -1,6 +1,7 @@
 package dsc
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 	"time"
@@ -117,6 +118,77 @@ type Manager interface {
 
 	//TableDescriptorRegistry returns Table Descriptor Registry
 	TableDescriptorRegistry() TableDescriptorRegistry
+
+	//WithTransaction runs handler in a transaction, transaction is committed if handler returns no error, otherwise (or on panic) it is rolled back.
+	WithTransaction(handler func(tx Connection) error) error
+
+	//WithTransactionOnConnection runs handler in a transaction on passed in connection, if connection has already an active transaction, a savepoint is used if dialect supports it.
+	WithTransactionOnConnection(connection Connection, handler func(tx Connection) error) error
+}
+
+//ContextManager represents datastore manager that honours context cancellation and deadlines.
+type ContextManager interface {
+	Manager
+
+	//ExecuteContext executes provided sql, with the arguments, '?' is used as placeholder for and arguments
+	ExecuteContext(ctx context.Context, sql string, parameters ...interface{}) (sql.Result, error)
+
+	//ExecuteAllContext executes all provided sql
+	ExecuteAllContext(ctx context.Context, sqls []string) ([]sql.Result, error)
+
+	//ExecuteOnConnectionContext executes sql on passed in connection, this allowes to maintain transaction if supported
+	ExecuteOnConnectionContext(ctx context.Context, connection Connection, sql string, parameters []interface{}) (sql.Result, error)
+
+	//ExecuteAllOnConnectionContext executes all sql on passed in connection, this allowes to maintain transaction if supported
+	ExecuteAllOnConnectionContext(ctx context.Context, connection Connection, sqls []string) ([]sql.Result, error)
+
+	//ReadSingleContext fetches a single record of data, it takes pointer to the result, sql query, binding parameters, record to application instance mapper
+	ReadSingleContext(ctx context.Context, resultPointer interface{}, query string, parameters []interface{}, mapper RecordMapper) (success bool, err error)
+
+	//ReadSingleOnConnectionContext fetches a single record of data on connection, it takes connection, pointer to the result, sql query, binding parameters, record to application instance mapper
+	ReadSingleOnConnectionContext(ctx context.Context, connection Connection, resultPointer interface{}, query string, parameters []interface{}, mapper RecordMapper) (success bool, err error)
+
+	//ReadAllContext reads all records, it takes pointer to the result slice , sql query, binding parameters, record to application instance mapper
+	ReadAllContext(ctx context.Context, resultSlicePointer interface{}, query string, parameters []interface{}, mapper RecordMapper) error
+
+	//ReadAllOnConnectionContext reads all records, it takes connection, pointer to the result slice , sql query, binding parameters, record to application instance mapper
+	ReadAllOnConnectionContext(ctx context.Context, connection Connection, resultSlicePointer interface{}, query string, parameters []interface{}, mapper RecordMapper) error
+
+	//ReadAllWithHandlerContext reads data for passed in query and parameters, for each row reading handler will be called, to continue reading next row it needs to return true
+	ReadAllWithHandlerContext(ctx context.Context, query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error
+
+	//ReadAllOnWithHandlerOnConnectionContext reads data for passed in query and parameters, on connection,  for each row reading handler will be called, to continue reading next row, it needs to return true
+	ReadAllOnWithHandlerOnConnectionContext(ctx context.Context, connection Connection, query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error
+
+	//PersistAllContext persists all passed in data to the table, it uses dml provider to generate DML for each row.
+	PersistAllContext(ctx context.Context, slicePointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error)
+
+	//PersistAllOnConnectionContext persists all passed in data on connection to the table, it uses dml provider to generate DML for each row.
+	PersistAllOnConnectionContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error)
+
+	//PersistSingleContext persists single row into table, it uses dml provider to generate DML to the row.
+	PersistSingleContext(ctx context.Context, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error)
+
+	//PersistSingleOnConnectionContext persists single row on connection into table, it uses dml provider to generate DML to the row.
+	PersistSingleOnConnectionContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error)
+
+	//PersistDataContext persists all all row of data to passed in table, it uses key setter to optionally set back autoincrement value, and func to generate parametrized sql for the row.
+	PersistDataContext(ctx context.Context, connection Connection, data interface{}, table string, keySetter KeySetter, sqlProvider func(item interface{}) *ParametrizedSQL) (int, error)
+
+	//DeleteAllContext deletes all record for passed in slice pointer from table, it uses key provider to take id/key for the record.
+	DeleteAllContext(ctx context.Context, slicePointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error)
+
+	//DeleteAllOnConnectionContext deletes all record on connection for passed in slice pointer from table, it uses key provider to take id/key for the record.
+	DeleteAllOnConnectionContext(ctx context.Context, connection Connection, resultPointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error)
+
+	//DeleteSingleContext deletes single row of data from table, it uses key provider to take id/key for the record.
+	DeleteSingleContext(ctx context.Context, resultPointer interface{}, table string, keyProvider KeyGetter) (success bool, err error)
+
+	//DeleteSingleOnConnectionContext deletes single row of data on connection  from table, it uses key provider to take id/key for the record.
+	DeleteSingleOnConnectionContext(ctx context.Context, connection Connection, resultPointer interface{}, table string, keyProvider KeyGetter) (success bool, err error)
+
+	//ClassifyDataAsInsertableOrUpdatableContext classifies records are inserable and what are updatable.
+	ClassifyDataAsInsertableOrUpdatableContext(ctx context.Context, connection Connection, slicePointer interface{}, table string, provider DmlProvider) (insertables, updatables []interface{}, err error)
 }
 
 //DatastoreDialect represents datastore dialects.
@@ -148,6 +220,15 @@ type DatastoreDialect interface {
 	//GetColumns returns TableColumn info
 	GetColumns(manager Manager, datastore, table string) ([]Column, error)
 
+	//GetIndexes returns table indexes with columns in index order
+	GetIndexes(manager Manager, datastore, table string) ([]*Index, error)
+
+	//GetForeignKeys returns table foreign keys
+	GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error)
+
+	//GetConstraints returns table primary key, unique, foreign key and check constraints
+	GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error)
+
 	//IsAutoincrement returns true if autoicrement
 	IsAutoincrement(manager Manager, datastore, table string) bool
 
@@ -181,6 +262,36 @@ type DatastoreDialect interface {
 	//CanHandleTransaction returns true if driver can handle transaction
 	CanHandleTransaction() bool
 
+	//CanUseSavepoint returns true if dialect supports savepoints within a transaction
+	CanUseSavepoint() bool
+
+	//SavepointSQL returns savepoint statement for passed in action (SavepointCreate, SavepointRollback, SavepointRelease) and name, empty string if action is not needed
+	SavepointSQL(action int, name string) string
+
+	//DeferConstraintsSQL returns statement deferring foreign key checks to the end of current transaction, empty string if dialect can not defer constraints
+	DeferConstraintsSQL() string
+
+	//CanUseRowValueIn returns true if dialect supports row value IN criteria, i.e. (a, b) IN ((?, ?), (?, ?)) for composite keys
+	CanUseRowValueIn() bool
+
+	//ReturningSQL returns clause appended to insert statement to return passed in columns, empty string if dialect does not support it
+	ReturningSQL(columns []string) string
+
+	//UpsertSQL returns insert or update statement for passed in table, columns and pk columns, values are bound in columns order, empty string if dialect does not support upsert
+	UpsertSQL(table string, columns, pkColumns []string) string
+
+	//CreateTableSQL returns CREATE TABLE statement with dialect column types, primary key and autoincrement (identity) syntax for passed in descriptor
+	CreateTableSQL(descriptor *TableDescriptor) (string, error)
+
+	//AlterTableSQL returns statements reconciling live table with descriptor for passed in schema diff
+	AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error)
+
+	//TranslateError returns one of Err* sentinels (ErrDuplicateKey, ErrDeadlock, ...) for passed in driver error, or nil if error is not recognised
+	TranslateError(err error) error
+
+	//IsRetryable returns true if passed in translated error is transient and failed operation can be retried
+	IsRetryable(err error) bool
+
 	//Checks if database is online
 	Ping(manager Manager) error
 }
@@ -265,6 +376,14 @@ type ConnectionProvider interface {
 	Close() error
 }
 
+//ContextConnectionProvider represents a datastore connection provider that honours context cancellation and deadlines.
+type ContextConnectionProvider interface {
+	ConnectionProvider
+
+	//GetContext returns a connection or error if context is done before connection was acquired.
+	GetContext(ctx context.Context) (Connection, error)
+}
+
 //ManagerFactory represents a manager factory.
 type ManagerFactory interface {
 	//Creates manager, takes config pointer.
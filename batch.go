@@ -2,18 +2,26 @@ package dsc
 
 import (
 	"compress/gzip"
+	"context"
+	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 )
 
 type batch struct {
+	ctx            context.Context
 	processed      int
 	tempDir        string
 	tempFile       string
 	size           int
 	sql            string
+	suffix         string
+	returning      string
+	upsert         bool
+	rows           [][]interface{}
 	writer         *gzip.Writer
 	values         []interface{}
 	placeholders   string
@@ -48,11 +56,15 @@ func (b *batch) flush() (int, error) {
 		}
 	case BulkInsertAllType:
 		b.sql += " SELECT 1 FROM DUAL"
+	case CopyFromStdinInsert:
+		return b.flushCopyFromStdin()
 	}
-	result, err := b.manager.ExecuteOnConnection(b.connection, b.sql, b.values)
-	b.dataIndexes = []int{}
-	b.sql = ""
-	b.values = []interface{}{}
+	if b.returning != "" && !b.upsert {
+		return b.flushReturning(dataIndexes)
+	}
+	b.sql += b.suffix
+	result, err := b.manager.contextManager().ExecuteOnConnectionContext(withOperationHint(b.ctx, OperationBatch, b.table), b.connection, b.sql, b.values)
+	b.reset()
 	if err != nil {
 		return 0, err
 	}
@@ -60,6 +72,16 @@ func (b *batch) flush() (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if b.upsert { //i.e. mysql reports 2 affected rows for updated row
+		b.processed += len(dataIndexes)
+		return len(dataIndexes), nil
+	}
+	b.processed += int(affected)
+	if b.firstSeq == 0 { //mysql returns the first generated id of multi row insert
+		if seq, err := result.LastInsertId(); err == nil && seq > 0 {
+			b.firstSeq = seq - 1
+		}
+	}
 	for _, i := range dataIndexes {
 		b.firstSeq++
 		b.updateId(i, b.firstSeq)
@@ -68,6 +90,80 @@ func (b *batch) flush() (int, error) {
 	return int(affected), nil
 }
 
+func (b *batch) reset() {
+	b.dataIndexes = []int{}
+	b.sql = ""
+	b.suffix = ""
+	b.values = []interface{}{}
+	b.rows = nil
+}
+
+//flushReturning executes insert returning generated keys, which are set back with updateId.
+//RETURNING rows order is not guaranteed, but sequence assigns increasing keys to rows in VALUES order within one statement,
+//thus keys are sorted before being matched with rows, it assumes autoincrement sequence with positive increment.
+func (b *batch) flushReturning(dataIndexes []int) (int, error) {
+	var seqs = make([]int64, 0, len(dataIndexes))
+	err := b.manager.contextManager().ReadAllOnWithHandlerOnConnectionContext(withOperationHint(b.ctx, OperationBatch, b.table), b.connection, b.sql+b.returning, b.values, func(scanner Scanner) (bool, error) {
+		var seq int64
+		err := scanner.Scan(&seq)
+		seqs = append(seqs, seq)
+		return err == nil, err
+	})
+	b.reset()
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(seqs, func(i, j int) bool {
+		return seqs[i] < seqs[j]
+	})
+	for i, seq := range seqs {
+		if i < len(dataIndexes) {
+			b.updateId(dataIndexes[i], seq)
+		}
+	}
+	b.processed += len(seqs)
+	return len(seqs), nil
+}
+
+//flushCopyFromStdin streams buffered rows with COPY FROM STDIN prepared statement, the protocol used by lib/pq driver, it requires transaction,
+//it is not used for table with autoincrement key since generated keys can not be read back
+func (b *batch) flushCopyFromStdin() (int, error) {
+	var rows = b.rows
+	result, err := b.manager.interceptExecute(withOperationHint(b.ctx, OperationBatch, b.table), b.connection, b.sql, nil, func(ctx context.Context, connection Connection, SQL string, args []interface{}) (sql.Result, error) {
+		tx, err := asSQLTx(connection.Unwrap(sqlTxtPointer))
+		if err != nil {
+			return nil, err
+		}
+		if tx == nil {
+			return nil, fmt.Errorf("failed to execute %v: transaction is required", SQL)
+		}
+		statement, err := tx.PrepareContext(ctx, SQL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare %v: %w", SQL, b.manager.translateError(err))
+		}
+		defer statement.Close()
+		for _, row := range rows {
+			if _, err = statement.ExecContext(ctx, row...); err != nil {
+				return nil, fmt.Errorf("failed to copy row: %w", b.manager.translateError(err))
+			}
+		}
+		if _, err = statement.ExecContext(ctx); err != nil {
+			return nil, fmt.Errorf("failed to execute %v: %w", SQL, b.manager.translateError(err))
+		}
+		return NewSQLResult(int64(len(rows)), 0), nil
+	})
+	b.reset()
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	b.processed += int(affected)
+	return int(affected), nil
+}
+
 func (b *batch) expandedValues(parametrizedSQL *ParametrizedSQL) string {
 	recordLine := b.manager.ExpandSQL(b.placeholders, parametrizedSQL.Values)
 	if breakCount := strings.Count(recordLine, "\n"); breakCount > 0 {
@@ -81,6 +177,14 @@ func (b *batch) transformFirst(parametrizedSQL *ParametrizedSQL) error {
 	b.values = parametrizedSQL.Values
 	fragment := " VALUES"
 	valuesIndex := strings.Index(parametrizedSQL.SQL, fragment)
+	if parametrizedSQL.Type == SQLTypeUpsert { //move conflict clause after the last row values
+		if endIndex := strings.Index(b.sql[valuesIndex:], ")"); endIndex != -1 {
+			endIndex += valuesIndex + 1
+			b.suffix = b.sql[endIndex:]
+			b.sql = b.sql[:endIndex]
+			parametrizedSQL = &ParametrizedSQL{SQL: b.sql, Values: parametrizedSQL.Values, Type: parametrizedSQL.Type}
+		}
+	}
 	if beginIndex := strings.Index(parametrizedSQL.SQL, "("); beginIndex != -1 {
 		names := string(parametrizedSQL.SQL[beginIndex+1:])
 		if endIndex := strings.Index(names, ")"); endIndex != -1 {
@@ -121,6 +225,13 @@ ENCLOSED BY ''''
 
 	case BulkInsertAllType:
 		b.sql = strings.Replace(b.sql, "INSERT ", "INSERT ALL ", 1)
+	case CopyFromStdinInsert:
+		if b.columns == "" {
+			return fmt.Errorf("columns were empty")
+		}
+		b.sql = fmt.Sprintf("COPY %v (%v) FROM STDIN", b.table, b.columns)
+		b.rows = [][]interface{}{parametrizedSQL.Values}
+		b.values = []interface{}{}
 	default:
 
 	}
@@ -137,6 +248,8 @@ func (b *batch) transformNext(parametrizedSQL *ParametrizedSQL) error {
 	case BulkInsertAllType:
 		b.sql += fmt.Sprintf("\nINTO %v(%v) VALUES(%v)", b.table, b.columns, b.placeholders)
 		b.values = append(b.values, parametrizedSQL.Values...)
+	case CopyFromStdinInsert:
+		b.rows = append(b.rows, parametrizedSQL.Values)
 	default:
 		b.sql += fmt.Sprintf(",(%v)", b.placeholders)
 		b.values = append(b.values, parametrizedSQL.Values...)
@@ -150,7 +263,8 @@ func (b *batch) persist(index int, item interface{}) error {
 		//nothing to udpate, one parameter is ID=? without values to update
 		return nil
 	}
-	if parametrizedSQL.Type == SQLTypeInsert && b.size > 0 {
+	b.upsert = parametrizedSQL.Type == SQLTypeUpsert
+	if b.size > 0 && (parametrizedSQL.Type == SQLTypeInsert || b.canBatchUpsert(parametrizedSQL)) {
 		if len(b.dataIndexes) > b.size {
 			if _, err := b.flush(); err != nil {
 				return err
@@ -162,7 +276,12 @@ func (b *batch) persist(index int, item interface{}) error {
 		}
 		return b.transformNext(parametrizedSQL)
 	}
-	result, err := b.manager.ExecuteOnConnection(b.connection, parametrizedSQL.SQL, parametrizedSQL.Values)
+	if b.returning != "" && parametrizedSQL.Type == SQLTypeInsert {
+		b.sql, b.values, b.dataIndexes = parametrizedSQL.SQL, parametrizedSQL.Values, []int{index}
+		_, err := b.flushReturning(b.dataIndexes)
+		return err
+	}
+	result, err := b.manager.contextManager().ExecuteOnConnectionContext(withOperationHint(b.ctx, OperationPersist, b.table), b.connection, parametrizedSQL.SQL, parametrizedSQL.Values)
 	if err != nil {
 		return err
 	}
@@ -170,6 +289,9 @@ func (b *batch) persist(index int, item interface{}) error {
 	if err != nil {
 		return err
 	}
+	if b.upsert && affected > 1 {
+		affected = 1
+	}
 	b.processed += int(affected)
 	seq, _ := result.LastInsertId()
 	if b.size > 0 && b.firstSeq == 0 {
@@ -179,19 +301,54 @@ func (b *batch) persist(index int, item interface{}) error {
 	return nil
 }
 
-func newBatch(table string, connection Connection, manager *AbstractManager, sqlProvider func(item interface{}) *ParametrizedSQL, updateId func(index int, seq int64)) *batch {
-	dialect := GetDatastoreDialect(manager.Config().DriverName)
-	var batchSize = manager.Config().GetInt(BatchSizeKey, defaultBatchSize)
-	Logf("batch size: %v\n", batchSize)
-	canUseBatch := dialect != nil && dialect.CanPersistBatch() && batchSize > 1
-	if !canUseBatch {
-		batchSize = 0
+//canBatchUpsert returns true if upsert statement uses multi row VALUES clause
+func (b *batch) canBatchUpsert(parametrizedSQL *ParametrizedSQL) bool {
+	if parametrizedSQL.Type != SQLTypeUpsert || b.bulkInsertType != "" {
+		return false
+	}
+	return strings.HasPrefix(parametrizedSQL.SQL, "INSERT ") && strings.Contains(parametrizedSQL.SQL, " VALUES(")
+}
+
+//maxBatchParameters represents max number of bind parameters in one batched update or delete statement
+const maxBatchParameters = 32767
+
+//persistBatchSize returns number of rows persisted with one statement or 0 if dialect can not batch
+func (m *AbstractManager) persistBatchSize(dialect DatastoreDialect) int {
+	var batchSize = m.Config().GetInt(BatchSizeKey, defaultBatchSize)
+	if dialect == nil || !dialect.CanPersistBatch() || batchSize <= 1 {
+		return 0
+	}
+	return batchSize
+}
+
+//rowsPerStatement returns number of rows in one batched statement, so that bind parameters do not exceed maxBatchParameters
+func rowsPerStatement(batchSize, rowParameters int) int {
+	if rowParameters > 0 && batchSize*rowParameters > maxBatchParameters {
+		batchSize = maxBatchParameters / rowParameters
 	}
-	insertType := ""
+	if batchSize < 1 {
+		return 1
+	}
+	return batchSize
+}
+
+func newBatch(ctx context.Context, table string, connection Connection, manager *AbstractManager, sqlProvider func(item interface{}) *ParametrizedSQL, updateId func(index int, seq int64)) *batch {
+	dialect := GetDatastoreDialect(manager.Config().DriverName)
+	batchSize := manager.persistBatchSize(dialect)
+	insertType, returning := "", ""
 	if dialect != nil {
-		insertType = dialect.BulkInsertType()
+		insertType = manager.Config().GetString(BulkInsertTypeKey, dialect.BulkInsertType())
+		if manager.tableDescriptorRegistry != nil && manager.tableDescriptorRegistry.Has(table) {
+			if descriptor := manager.tableDescriptorRegistry.Get(table); descriptor.Autoincrement && len(descriptor.PkColumns) == 1 {
+				returning = dialect.ReturningSQL(descriptor.PkColumns)
+			}
+		}
+		if insertType == CopyFromStdinInsert && returning != "" { //COPY can not return generated keys, multi row VALUES with RETURNING is used instead
+			insertType = ""
+		}
 	}
 	return &batch{
+		ctx:            ctx,
 		connection:     connection,
 		updateId:       updateId,
 		sqlProvider:    sqlProvider,
@@ -199,6 +356,7 @@ func newBatch(table string, connection Connection, manager *AbstractManager, sql
 		values:         []interface{}{},
 		dataIndexes:    []int{},
 		bulkInsertType: insertType,
+		returning:      returning,
 		manager:        manager,
 		table:          table,
 	}
@@ -11,6 +11,17 @@ const (
 	SQLTypeUpdate = 1
 	//SQLTypeDelete 2 constant for DML delete statement provider.
 	SQLTypeDelete = 2
+	//SQLTypeUpsert 3 constant for DML insert or update statement provider.
+	SQLTypeUpsert = 3
+)
+
+const (
+	//SavepointCreate 0 constant for create savepoint statement.
+	SavepointCreate = 0
+	//SavepointRollback 1 constant for rollback to savepoint statement.
+	SavepointRollback = 1
+	//SavepointRelease 2 constant for release savepoint statement.
+	SavepointRelease = 2
 )
 
 var sqlDbPointer = (*sql.DB)(nil)
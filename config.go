@@ -15,6 +15,15 @@ import (
 // BatchSizeKey represents a config batch size parameter
 const BatchSizeKey = "batchSize"
 
+// BulkInsertTypeKey represents a config parameter overriding dialect bulk insert type, i.e. copyFromStdin for postgres
+const BulkInsertTypeKey = "bulkInsertType"
+
+// UpsertKey represents a config parameter enabling dialect upsert in PersistAll, default false
+const UpsertKey = "upsert"
+
+// SoftDeleteColumnKey represents a config parameter with soft delete column name, used by tables having that column
+const SoftDeleteColumnKey = "softDeleteColumn"
+
 // Config represent datastore config.
 type Config struct {
 	URL string
@@ -46,6 +55,18 @@ type Config struct {
 	// ReservedKeywords extends the default reserved identifiers list.
 	// Items can also be provided in parameters under "reservedKeywords" or "reserved" (comma/space separated).
 	ReservedKeywords []string `json:"reservedKeywords,omitempty"`
+	// Interceptors are run around every manager execute and read operation.
+	Interceptors []Interceptor `json:"-"`
+	// Logger is used to log operations, *slog.Logger can be used, if not set legacy Logf function is used.
+	Logger Logger `json:"-"`
+	// Redactor redacts logged statement arguments, if not set arguments are redacted unless logArgs parameter is enabled.
+	Redactor Redactor `json:"-"`
+	// RetryPolicy retries transient failures, if not set policy is created from retryMaxAttempts, retryBackoffMs and retryMaxBackoffMs parameters.
+	RetryPolicy *RetryPolicy `json:"-"`
+	// Replicas are read replicas configs, when set manager factory creates ReplicatedManager routing reads to replicas.
+	Replicas []*Config `json:"replicas,omitempty"`
+	// QueryCacheStore stores cached query results when queryCacheTtlMs parameter is set, if not set in-process LRU store limited by queryCacheSize parameter is used.
+	QueryCacheStore QueryCacheStore `json:"-"`
 }
 
 // Get returns value for passed in parameter name or panic - please use Config.Has to check if value is present.
@@ -255,12 +276,26 @@ func (c *Config) Clone() *Config {
 		cred:                c.cred,
 		QuoteReserved:       c.QuoteReserved,
 		ReservedKeywords:    append([]string(nil), c.ReservedKeywords...),
+		Interceptors:        append([]Interceptor(nil), c.Interceptors...),
+		Logger:              c.Logger,
+		Redactor:            c.Redactor,
+		QueryCacheStore:     c.QueryCacheStore,
 	}
 	if len(c.Parameters) > 0 {
 		for k, v := range c.Parameters {
 			result.Parameters[k] = v
 		}
 	}
+	if c.RetryPolicy != nil {
+		retryPolicy := *c.RetryPolicy
+		result.RetryPolicy = &retryPolicy
+	}
+	if len(c.Replicas) > 0 {
+		result.Replicas = make([]*Config, len(c.Replicas))
+		for i, replica := range c.Replicas {
+			result.Replicas[i] = replica.Clone()
+		}
+	}
 	return result
 }
 
@@ -1,16 +1,36 @@
 package dsc
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"time"
 )
 
+//transactionalConnection represents a connection tracking its active transaction
+type transactionalConnection interface {
+	inTransaction() bool
+
+	nextSavepoint() string
+
+	//afterTransaction registers callback run once active transaction is committed or rolled back
+	afterTransaction(callback func(committed bool))
+
+	//callbackMark returns number of registered after transaction callbacks
+	callbackMark() int
+
+	//rollbackCallbacks runs callbacks registered after passed in mark as rolled back and removes them, used by savepoint rollback
+	rollbackCallbacks(mark int)
+}
+
 //AbstractConnection represents an abstract connection
 type AbstractConnection struct {
 	Connection
 	lastUsed       *time.Time
 	config         *Config
 	connectionPool chan Connection
+	pool           *connectionPool
+	created        time.Time
 }
 
 //Config returns a datastore config
@@ -33,20 +53,32 @@ func (ac *AbstractConnection) SetLastUsed(ts *time.Time) {
 	ac.lastUsed = ts
 }
 
+func (ac *AbstractConnection) setPool(pool *connectionPool, created time.Time) {
+	ac.pool = pool
+	ac.created = created
+}
+
+func (ac *AbstractConnection) createdAt() time.Time {
+	return ac.created
+}
+
 //Close closes connection if pool is full or send it back to the pool
 func (ac *AbstractConnection) Close() error {
+	if ac.pool != nil {
+		return ac.pool.release(ac.Connection)
+	}
 	channel := ac.Connection.ConnectionPool()
 	config := ac.config
-	if len(ac.Connection.ConnectionPool()) < config.MaxPoolSize {
-		var connection = ac.Connection
-		channel <- connection
+	if len(channel) < config.MaxPoolSize {
 		var ts = time.Now()
-		connection.SetLastUsed(&ts)
-
-	} else {
-		return ac.Connection.CloseNow()
+		ac.Connection.SetLastUsed(&ts)
+		select {
+		case channel <- ac.Connection:
+			return nil
+		default:
+		}
 	}
-	return nil
+	return ac.Connection.CloseNow()
 }
 
 //Begin starts a transaction  - this method is an abstract method
@@ -68,6 +100,7 @@ type AbstractConnectionProvider struct {
 	ConnectionProvider
 	config         *Config
 	connectionPool chan Connection
+	pool           *connectionPool
 }
 
 //Config returns a datastore config,
@@ -80,77 +113,64 @@ func (cp *AbstractConnectionProvider) ConnectionPool() chan Connection {
 	return cp.connectionPool
 }
 
-//SpawnConnectionIfNeeded creates a new connection if connection pool has not reached size controlled by Config.PoolSize
+//PoolStats returns connection pool statistics
+func (cp *AbstractConnectionProvider) PoolStats() PoolStats {
+	return cp.pool.stats()
+}
+
+//SpawnConnectionIfNeeded creates a new connection if connection pool has not reached size controlled by Config.PoolSize, it never exceeds Config.MaxPoolSize pooled connections
 func (cp *AbstractConnectionProvider) SpawnConnectionIfNeeded() {
 	config := cp.ConnectionProvider.Config()
 	if config.PoolSize == 0 {
 		config.PoolSize = 1
 	}
-	connectionPool := cp.ConnectionProvider.ConnectionPool()
-	for i := len(connectionPool); i < config.PoolSize; i++ {
+	for len(cp.pool.idle) < config.PoolSize {
+		if !cp.pool.reserve() {
+			return
+		}
 		connection, err := cp.ConnectionProvider.NewConnection()
 		if err != nil {
+			cp.pool.unreserve()
 			log.Printf("failed to create connection %v\n", err)
-			break
+			return
 		}
-
+		cp.pool.track(connection)
+		var ts = time.Now()
+		connection.SetLastUsed(&ts)
 		select {
-		case <-time.After(1 * time.Second):
-			log.Fatalf("failed to add connection to queue (size: %v, cap:%v)", len(connectionPool), cap(connectionPool))
-		case connectionPool <- connection:
+		case cp.pool.idle <- connection:
+		default:
+			_ = cp.pool.discard(connection)
+			return
 		}
-
 	}
 }
 
-//Close closes a datastore connection or returns it to the pool (Config.PoolSize and Config.MaxPoolSize).
+//Close closes all idle datastore connections and stops pool health checker, connections in use are closed once released.
 func (cp *AbstractConnectionProvider) Close() error {
-
-	poolsize := len(cp.connectionPool)
-	for i := 0; i < poolsize; i++ {
-		var connection Connection
-		select {
-		case <-time.After(1 * time.Second):
-		case connection = <-cp.connectionPool:
-			err := connection.CloseNow()
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	// 防止池子中再回收进新连接
-	if len(cp.connectionPool) > 0 {
-		cp.Close()
-	}
-
-	return nil
+	return cp.pool.close()
 }
 
 //Get returns a new datastore connection or error.
 func (cp *AbstractConnectionProvider) Get() (Connection, error) {
-	cp.ConnectionProvider.SpawnConnectionIfNeeded()
-	connectionPool := cp.ConnectionProvider.ConnectionPool()
+	return cp.GetContext(context.Background())
+}
 
-	var result Connection
-	select {
-	case <-time.After(100 * time.Millisecond):
-		{
-			Logf("unable to acquire connection from pool, creating new connection ...")
-		}
-	case result = <-connectionPool:
+//GetContext returns a pooled datastore connection or error, if pool reached Config.MaxPoolSize it waits for a released connection,
+//bounded pool waits till context is done or pool acquire timeout elapsed, otherwise a new connection is opened.
+func (cp *AbstractConnectionProvider) GetContext(ctx context.Context) (Connection, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	if result == nil {
-		var err error
-		result, err = cp.ConnectionProvider.NewConnection()
-		if err != nil {
-			return nil, err
-		}
+	if cp.pool.wasClosed() {
+		return nil, fmt.Errorf("failed to get connection: %w", errPoolClosed)
 	}
-	return result, nil
+	cp.pool.startHealthCheckIfNeeded(cp.ConnectionProvider)
+	cp.ConnectionProvider.SpawnConnectionIfNeeded()
+	return cp.pool.acquire(ctx, cp.ConnectionProvider)
 }
 
 //NewAbstractConnectionProvider create a new AbstractConnectionProvider
 func NewAbstractConnectionProvider(config *Config, connectionPool chan Connection, connectionProvider ConnectionProvider) *AbstractConnectionProvider {
-	return &AbstractConnectionProvider{config: config, connectionPool: connectionPool, ConnectionProvider: connectionProvider}
+	return &AbstractConnectionProvider{config: config, connectionPool: connectionPool, ConnectionProvider: connectionProvider, pool: newConnectionPool(config, connectionPool)}
 }
@@ -1,6 +1,7 @@
 package dsc_test
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/dsc"
@@ -60,6 +61,123 @@ func TestConnectionProvider(t *testing.T) {
 
 }
 
+func TestConnectionProvider_PoolStats(t *testing.T) {
+	config := dsc.NewConfig("test", "", "poolBounded:true,poolAcquireTimeoutMs:50")
+	config.PoolSize = 1
+	config.MaxPoolSize = 2
+	provider := newTestConnectionProvider(config)
+	defer provider.Close()
+
+	first, err := provider.Get()
+	assert.Nil(t, err)
+	second, err := provider.Get()
+	assert.Nil(t, err)
+
+	stats := provider.PoolStats()
+	assert.Equal(t, 2, stats.MaxSize)
+	assert.Equal(t, 2, stats.InUse)
+	assert.Equal(t, 0, stats.Idle)
+
+	startTime := time.Now()
+	_, err = provider.Get()
+	assert.NotNil(t, err, "acquire should time out when pool is exhausted")
+	assert.True(t, time.Now().Sub(startTime) >= 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err = provider.GetContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		first.Close()
+	}()
+	third, err := provider.Get()
+	assert.Nil(t, err)
+	assert.True(t, third == first, "released connection should be reused")
+
+	assert.Nil(t, third.Close())
+	assert.Nil(t, second.Close())
+	stats = provider.PoolStats()
+	assert.Equal(t, 0, stats.InUse)
+	assert.Equal(t, 2, stats.Idle)
+	assert.EqualValues(t, 2, stats.Created)
+	assert.EqualValues(t, 3, stats.Waits)
+	assert.EqualValues(t, 1, stats.Timeouts)
+	assert.True(t, stats.WaitTime > 0)
+
+	assert.Nil(t, provider.Close())
+	stats = provider.PoolStats()
+	assert.Equal(t, 0, stats.Open)
+	assert.EqualValues(t, 2, stats.Closed)
+}
+
+func TestConnectionProvider_Overflow(t *testing.T) {
+	config := dsc.NewConfig("test", "", "")
+	config.MaxPoolSize = 1
+	provider := newTestConnectionProvider(config)
+	defer provider.Close()
+	first, err := provider.Get()
+	assert.Nil(t, err)
+	second, err := provider.Get()
+	assert.Nil(t, err, "unbounded pool should open a new connection")
+	assert.False(t, first == second)
+	assert.Equal(t, 2, provider.PoolStats().InUse)
+	assert.Nil(t, first.Close())
+	assert.Nil(t, second.Close())
+	stats := provider.PoolStats()
+	assert.Equal(t, 1, stats.Idle)
+	assert.EqualValues(t, 1, stats.Closed)
+}
+
+func TestConnectionProvider_CloseInUse(t *testing.T) {
+	config := dsc.NewConfig("test", "", "")
+	config.MaxPoolSize = 2
+	provider := newTestConnectionProvider(config)
+	connection, err := provider.Get()
+	assert.Nil(t, err)
+	assert.Nil(t, provider.Close())
+	closed := provider.PoolStats().Closed
+	assert.Nil(t, connection.Close())
+	stats := provider.PoolStats()
+	assert.Equal(t, closed+1, stats.Closed, "connection released after close should be closed")
+	assert.Equal(t, 0, stats.Open)
+	assert.Equal(t, 0, stats.Idle)
+	_, err = provider.Get()
+	assert.NotNil(t, err)
+}
+
+func TestConnectionProvider_Eviction(t *testing.T) {
+	{ //idle timeout
+		config := dsc.NewConfig("test", "", "poolIdleTimeoutMs:20")
+		config.MaxPoolSize = 2
+		provider := newTestConnectionProvider(config)
+		connection, err := provider.Get()
+		assert.Nil(t, err)
+		connection.Close()
+		time.Sleep(30 * time.Millisecond)
+		next, err := provider.Get()
+		assert.Nil(t, err)
+		assert.False(t, next == connection, "idle connection should be evicted")
+		assert.EqualValues(t, 1, provider.PoolStats().Closed)
+		provider.Close()
+	}
+	{ //max lifetime with background health check
+		config := dsc.NewConfig("test", "", "poolMaxLifetimeMs:20,poolHealthCheckMs:10")
+		config.MaxPoolSize = 2
+		provider := newTestConnectionProvider(config)
+		connection, err := provider.Get()
+		assert.Nil(t, err)
+		connection.Close()
+		time.Sleep(60 * time.Millisecond)
+		assert.True(t, provider.PoolStats().Closed >= 1, "expired connection should be closed by health checker")
+		provider.Close()
+	}
+}
+
 type testConnection struct {
 	*dsc.AbstractConnection
 }
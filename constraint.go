@@ -0,0 +1,148 @@
+package dsc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/viant/toolbox"
+)
+
+const (
+	//ConstraintPrimaryKey represents primary key constraint type
+	ConstraintPrimaryKey = "PRIMARY KEY"
+	//ConstraintUnique represents unique constraint type
+	ConstraintUnique = "UNIQUE"
+	//ConstraintForeignKey represents foreign key constraint type
+	ConstraintForeignKey = "FOREIGN KEY"
+	//ConstraintCheck represents check constraint type
+	ConstraintCheck = "CHECK"
+)
+
+//Index represents table index
+type Index struct {
+	Name    string
+	Table   string
+	Columns []string
+	Unique  bool
+	Primary bool
+}
+
+//ForeignKey represents table foreign key, Columns and ReferencedColumns are in key order
+type ForeignKey struct {
+	Name              string
+	Table             string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+	OnDelete          string
+	OnUpdate          string
+}
+
+//Constraint represents table constraint, Expression is only set for check constraint
+type Constraint struct {
+	Name       string
+	Table      string
+	Type       string
+	Columns    []string
+	Expression string
+}
+
+//readCatalogRecords reads catalog query records with lower case keys
+func readCatalogRecords(manager Manager, SQL string) ([]map[string]interface{}, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, SQL, []interface{}{}, nil); err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %v, %w", SQL, err)
+	}
+	var result = make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		var normalized = make(map[string]interface{}, len(record))
+		for key, value := range record {
+			normalized[strings.ToLower(key)] = value
+		}
+		result = append(result, normalized)
+	}
+	return result, nil
+}
+
+//catalogString returns record value as string, or empty string for NULL
+func catalogString(record map[string]interface{}, key string) string {
+	value, ok := record[key]
+	if !ok || value == nil {
+		return ""
+	}
+	return toolbox.AsString(value)
+}
+
+//readIndexes reads indexes from catalog query returning name, column_name, is_unique and is_primary columns, one row per index column
+func readIndexes(manager Manager, table, SQL string) ([]*Index, error) {
+	records, err := readCatalogRecords(manager, SQL)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*Index, 0)
+	var indexes = make(map[string]*Index)
+	for _, record := range records {
+		name := catalogString(record, "name")
+		index, ok := indexes[name]
+		if !ok {
+			index = &Index{Name: name, Table: table, Columns: make([]string, 0), Unique: toolbox.AsBoolean(record["is_unique"]), Primary: toolbox.AsBoolean(record["is_primary"])}
+			indexes[name] = index
+			result = append(result, index)
+		}
+		index.Columns = append(index.Columns, catalogString(record, "column_name"))
+	}
+	return result, nil
+}
+
+//readForeignKeys reads foreign keys from catalog query returning name, column_name, referenced_table, referenced_column, on_delete and on_update columns, one row per key column
+func readForeignKeys(manager Manager, table, SQL string) ([]*ForeignKey, error) {
+	records, err := readCatalogRecords(manager, SQL)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*ForeignKey, 0)
+	var keys = make(map[string]*ForeignKey)
+	for _, record := range records {
+		name := catalogString(record, "name")
+		key, ok := keys[name]
+		if !ok {
+			key = &ForeignKey{
+				Name:              name,
+				Table:             table,
+				Columns:           make([]string, 0),
+				ReferencedTable:   catalogString(record, "referenced_table"),
+				ReferencedColumns: make([]string, 0),
+				OnDelete:          strings.ToUpper(catalogString(record, "on_delete")),
+				OnUpdate:          strings.ToUpper(catalogString(record, "on_update")),
+			}
+			keys[name] = key
+			result = append(result, key)
+		}
+		key.Columns = append(key.Columns, catalogString(record, "column_name"))
+		key.ReferencedColumns = append(key.ReferencedColumns, catalogString(record, "referenced_column"))
+	}
+	return result, nil
+}
+
+//readConstraints reads constraints from catalog query returning name, constraint_type, column_name and expression columns, one row per constraint column
+func readConstraints(manager Manager, table, SQL string) ([]*Constraint, error) {
+	records, err := readCatalogRecords(manager, SQL)
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*Constraint, 0)
+	var constraints = make(map[string]*Constraint)
+	for _, record := range records {
+		name := catalogString(record, "name")
+		constraint, ok := constraints[name]
+		if !ok {
+			constraint = &Constraint{Name: name, Table: table, Type: strings.ToUpper(catalogString(record, "constraint_type")), Columns: make([]string, 0), Expression: catalogString(record, "expression")}
+			constraints[name] = constraint
+			result = append(result, constraint)
+		}
+		if column := catalogString(record, "column_name"); column != "" && !hasColumn(constraint.Columns, column) {
+			constraint.Columns = append(constraint.Columns, column)
+		}
+	}
+	return result, nil
+}
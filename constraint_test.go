@@ -0,0 +1,102 @@
+package dsc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestSqlLiteDialect_Constraints(t *testing.T) {
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/constraint.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS con_order_items",
+		"DROP TABLE IF EXISTS con_orders",
+		"CREATE TABLE con_orders (id INTEGER NOT NULL PRIMARY KEY, code VARCHAR(32) UNIQUE, customer VARCHAR(64))",
+		"CREATE INDEX con_orders_customer ON con_orders(customer, code)",
+		"CREATE TABLE con_order_items (order_id INTEGER NOT NULL, line INTEGER NOT NULL, sku VARCHAR(32), PRIMARY KEY(order_id, line), " +
+			"FOREIGN KEY(order_id) REFERENCES con_orders ON DELETE CASCADE)",
+	} {
+		_, err = manager.Execute(SQL)
+		if !assert.Nil(t, err, SQL) {
+			return
+		}
+	}
+	dialect := dsc.GetDatastoreDialect("sqlite3")
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	assert.Nil(t, err)
+
+	indexes, err := dialect.GetIndexes(manager, datastore, "con_orders")
+	assert.Nil(t, err)
+	var indexByName = make(map[string]*dsc.Index)
+	for _, index := range indexes {
+		indexByName[index.Name] = index
+	}
+	if index, ok := indexByName["con_orders_customer"]; assert.True(t, ok) {
+		assert.Equal(t, []string{"customer", "code"}, index.Columns)
+		assert.False(t, index.Unique)
+	}
+	assert.Equal(t, 2, len(indexes))
+
+	keys, err := dialect.GetForeignKeys(manager, datastore, "con_order_items")
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(keys)) {
+		assert.Equal(t, "con_orders", keys[0].ReferencedTable)
+		assert.Equal(t, []string{"order_id"}, keys[0].Columns)
+		assert.Equal(t, []string{"id"}, keys[0].ReferencedColumns)
+		assert.Equal(t, "CASCADE", keys[0].OnDelete)
+	}
+
+	constraints, err := dialect.GetConstraints(manager, datastore, "con_order_items")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(constraints)) { //composite primary key index is not reported as unique constraint
+		assert.Equal(t, dsc.ConstraintPrimaryKey, constraints[0].Type)
+		assert.Equal(t, []string{"order_id", "line"}, constraints[0].Columns)
+		assert.Equal(t, dsc.ConstraintForeignKey, constraints[1].Type)
+	}
+	constraints, err = dialect.GetConstraints(manager, datastore, "con_orders")
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(constraints)) {
+		assert.Equal(t, dsc.ConstraintUnique, constraints[1].Type)
+		assert.Equal(t, []string{"code"}, constraints[1].Columns)
+	}
+}
+
+func TestMsSQLDialect_GetForeignKeys(t *testing.T) {
+	var catalogSQL string
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/constraint.db")
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			if strings.Contains(operation.SQL, "sys.foreign_keys") { //replace SQL Server catalog query with catalog rows
+				catalogSQL = operation.SQL
+				operation.SQL = "SELECT 'fk_items_orders' AS name, 'order_id' AS column_name, 'orders' AS referenced_table, 'id' AS referenced_column, " +
+					"'CASCADE' AS on_delete, 'NO ACTION' AS on_update"
+			}
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	keys, err := dsc.GetDatastoreDialect("sqlserver").GetForeignKeys(manager, "dbo", "items")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Contains(t, catalogSQL, "JOIN sys.foreign_key_columns fc ON fc.constraint_object_id = f.object_id")
+	assert.Contains(t, catalogSQL, "WHERE t.name = 'items' AND s.name = 'dbo'")
+	assert.NotContains(t, catalogSQL, "position_in_unique_constraint")
+	if assert.Equal(t, 1, len(keys)) {
+		assert.Equal(t, "orders", keys[0].ReferencedTable)
+		assert.Equal(t, []string{"order_id"}, keys[0].Columns)
+		assert.Equal(t, []string{"id"}, keys[0].ReferencedColumns)
+		assert.Equal(t, "CASCADE", keys[0].OnDelete)
+		assert.Equal(t, "NO ACTION", keys[0].OnUpdate)
+	}
+}
@@ -0,0 +1,451 @@
+package dsc
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/viant/toolbox"
+)
+
+const defaultTransferBatchSize = 1000
+
+//RecordTransformer transforms a copied record, it returns nil record to skip it
+type RecordTransformer func(record map[string]interface{}) (map[string]interface{}, error)
+
+//Transfer represents a copy of source query rows into destination table
+type Transfer struct {
+	ID          string              //checkpoint id, if empty it is derived from source SQL and destination table
+	Source      Manager             //source manager
+	SourceSQL   string              //source query, use ORDER BY to make resume deterministic
+	SourceArgs  []interface{}       //source query parameters
+	SourceTable string              //source table used to create missing destination table, if empty it is discovered from source SQL
+	Dest        Manager             //destination manager
+	DestTable   string              //destination table
+	PkColumns   []string            //destination primary key columns, if empty destination dialect key is used
+	Mapping     map[string]string   //source to destination column mapping, source column mapped to empty name is dropped
+	Transforms  []RecordTransformer //record transformers applied after column mapping
+	BatchSize   int                 //number of rows persisted in one transaction, default 1000
+	Merge       bool                //if set rows are inserted or updated by primary key, otherwise rows are inserted
+}
+
+//TransferResult represents transfer result
+type TransferResult struct {
+	Read    int  //number of rows read from source
+	Skipped int  //number of rows skipped as already copied or filtered out by transformer
+	Written int  //number of rows written to destination
+	Created bool //true if destination table was created
+}
+
+//Checkpoint represents transfer progress
+type Checkpoint struct {
+	Rows    int //number of source rows already copied
+	Pending int //number of source rows after Rows being written, they might have been committed if copy was interrupted
+	Done    bool
+	Updated time.Time
+}
+
+//CheckpointStore represents transfer checkpoint store
+type CheckpointStore interface {
+	//Load returns checkpoint for passed in transfer id or nil
+	Load(id string) (*Checkpoint, error)
+
+	//Save saves checkpoint for passed in transfer id
+	Save(id string, checkpoint *Checkpoint) error
+}
+
+type fileCheckpointStore struct {
+	directory string
+	mux       *sync.Mutex
+}
+
+func (s *fileCheckpointStore) filename(id string) string {
+	return path.Join(s.directory, id+".json")
+}
+
+//Load returns checkpoint for passed in transfer id or nil
+func (s *fileCheckpointStore) Load(id string) (*Checkpoint, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	payload, err := os.ReadFile(s.filename(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	result := &Checkpoint{}
+	if err = json.Unmarshal(payload, result); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %v: %v", id, err)
+	}
+	return result, nil
+}
+
+//Save saves checkpoint for passed in transfer id
+func (s *fileCheckpointStore) Save(id string, checkpoint *Checkpoint) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	payload, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(s.directory, 0755); err != nil {
+		return err
+	}
+	tempFile := s.filename(id) + ".tmp"
+	if err = os.WriteFile(tempFile, payload, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, s.filename(id))
+}
+
+//NewFileCheckpointStore returns checkpoint store keeping checkpoints as JSON files in passed in directory
+func NewFileCheckpointStore(directory string) CheckpointStore {
+	return &fileCheckpointStore{directory: directory, mux: &sync.Mutex{}}
+}
+
+//Copier copies rows between managers
+type Copier struct {
+	checkpoints CheckpointStore
+}
+
+//transferState represents running transfer state
+type transferState struct {
+	*Transfer
+	ctx        context.Context
+	result     *TransferResult
+	checkpoint *Checkpoint
+	columns    []string
+	batch      []interface{}
+	batchSize  int
+	provider   DmlProvider
+	descriptor *TableDescriptor
+}
+
+func (t *Transfer) checkpointID() string {
+	if t.ID != "" {
+		return t.ID
+	}
+	hash := md5.Sum([]byte(t.SourceSQL + "\n" + toolbox.AsString(t.SourceArgs) + "\n" + t.DestTable))
+	return t.DestTable + "_" + hex.EncodeToString(hash[:8])
+}
+
+func (t *Transfer) validate() error {
+	if t.Source == nil {
+		return fmt.Errorf("source manager was empty")
+	}
+	if t.Dest == nil {
+		return fmt.Errorf("dest manager was empty")
+	}
+	if t.SourceSQL == "" {
+		return fmt.Errorf("source SQL was empty")
+	}
+	if t.DestTable == "" {
+		return fmt.Errorf("dest table was empty")
+	}
+	return nil
+}
+
+//Copy streams source query rows into destination table, it creates destination table if needed, and resumes from the last checkpoint if checkpoint store was provided
+func (c *Copier) Copy(ctx context.Context, transfer *Transfer) (*TransferResult, error) {
+	if err := transfer.validate(); err != nil {
+		return nil, err
+	}
+	state := &transferState{Transfer: transfer, ctx: ctx, result: &TransferResult{}, checkpoint: &Checkpoint{}}
+	if state.batchSize = transfer.BatchSize; state.batchSize <= 0 {
+		state.batchSize = defaultTransferBatchSize
+	}
+	id := transfer.checkpointID()
+	if c.checkpoints != nil {
+		checkpoint, err := c.checkpoints.Load(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load checkpoint %v: %v", id, err)
+		}
+		if checkpoint != nil {
+			if checkpoint.Done {
+				return state.result, nil
+			}
+			state.checkpoint = checkpoint
+		}
+	}
+	created, err := c.createTableIfNeeded(transfer)
+	if err != nil {
+		return nil, err
+	}
+	state.result.Created = created
+	//rows of pending batch might have been committed before interruption, they are written idempotently
+	replayUntil := state.checkpoint.Rows + state.checkpoint.Pending
+	var flush = func() error {
+		if len(state.batch) == 0 {
+			return nil
+		}
+		state.checkpoint.Pending = state.result.Read - state.checkpoint.Rows
+		if err := c.saveCheckpoint(id, state.checkpoint); err != nil {
+			return err
+		}
+		written, err := state.persist(state.result.Read <= replayUntil)
+		if err != nil {
+			return fmt.Errorf("failed to persist %v rows into %v: %w", len(state.batch), transfer.DestTable, err)
+		}
+		state.result.Written += written
+		state.checkpoint.Rows = state.result.Read
+		state.checkpoint.Pending = 0
+		state.batch = state.batch[:0]
+		return c.saveCheckpoint(id, state.checkpoint)
+	}
+	var index = 0
+	err = readAllWithHandler(ctx, transfer.Source, transfer.SourceSQL, transfer.SourceArgs, func(scanner Scanner) (bool, error) {
+		index++
+		if index <= state.checkpoint.Rows {
+			state.result.Read++
+			state.result.Skipped++
+			return true, nil
+		}
+		record, err := state.readRecord(scanner)
+		if err != nil {
+			return false, err
+		}
+		state.result.Read++
+		if record == nil {
+			state.result.Skipped++
+		} else {
+			state.batch = append(state.batch, record)
+		}
+		if len(state.batch) >= state.batchSize || index == replayUntil {
+			return true, flush()
+		}
+		return true, nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return state.result, err
+	}
+	state.checkpoint.Done = true
+	return state.result, c.saveCheckpoint(id, state.checkpoint)
+}
+
+func (c *Copier) saveCheckpoint(id string, checkpoint *Checkpoint) error {
+	if c.checkpoints == nil {
+		return nil
+	}
+	checkpoint.Updated = time.Now()
+	if err := c.checkpoints.Save(id, checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint %v: %v", id, err)
+	}
+	return nil
+}
+
+//createTableIfNeeded creates destination table from source columns if destination table does not exist
+func (c *Copier) createTableIfNeeded(transfer *Transfer) (bool, error) {
+	dialect := GetDatastoreDialect(transfer.Dest.Config().DriverName)
+	if dialect == nil {
+		return false, nil
+	}
+	datastore, err := dialect.GetCurrentDatastore(transfer.Dest)
+	if err != nil {
+		return false, err
+	}
+	tables, err := dialect.GetTables(transfer.Dest, datastore)
+	if err != nil {
+		return false, err
+	}
+	for _, table := range tables {
+		if strings.EqualFold(table, transfer.DestTable) || strings.HasPrefix(strings.ToLower(table), strings.ToLower(transfer.DestTable)+".") {
+			return false, nil
+		}
+	}
+	sourceTable := transfer.SourceTable
+	if sourceTable == "" {
+		sourceTable = discoverTable(transfer.SourceSQL)
+	}
+	sourceDialect := GetDatastoreDialect(transfer.Source.Config().DriverName)
+	if sourceTable == "" || sourceDialect == nil {
+		return false, fmt.Errorf("failed to create %v: unable to discover source table", transfer.DestTable)
+	}
+	sourceDatastore, err := sourceDialect.GetCurrentDatastore(transfer.Source)
+	if err != nil {
+		return false, err
+	}
+	columns, err := sourceDialect.GetColumns(transfer.Source, sourceDatastore, sourceTable)
+	if err != nil {
+		return false, fmt.Errorf("failed to get %v columns: %v", sourceTable, err)
+	}
+	descriptor := &TableDescriptor{
+		Table:       transfer.DestTable,
+		Columns:     make([]string, 0, len(columns)),
+		ColumnTypes: make(map[string]string),
+		ColumnSizes: make(map[string]int),
+		SQLTypes:    make(map[string]string),
+		Nullables:   make(map[string]bool),
+	}
+	for _, column := range columns {
+		name, ok := transfer.mapColumn(column.Name())
+		if !ok {
+			continue
+		}
+		descriptor.Columns = append(descriptor.Columns, name)
+		describeColumn(descriptor, name, column)
+	}
+	pkColumns := transfer.PkColumns
+	if len(pkColumns) == 0 {
+		if keyName := sourceDialect.GetKeyName(transfer.Source, sourceDatastore, sourceTable); keyName != "" {
+			for _, column := range strings.Split(keyName, ",") {
+				if name, ok := transfer.mapColumn(strings.TrimSpace(column)); ok {
+					pkColumns = append(pkColumns, name)
+				}
+			}
+		}
+	}
+	descriptor.PkColumns = pkColumns
+	if err = dialect.CreateTable(transfer.Dest, datastore, transfer.DestTable, descriptor); err != nil {
+		return false, fmt.Errorf("failed to create %v: %v", transfer.DestTable, err)
+	}
+	return true, nil
+}
+
+//describeColumn sets destination column go type, size and nullability from source column, so that destination dialect maps them to its own column type,
+//decimal keeps precision and scale, character column without known length uses dialect text type
+func describeColumn(descriptor *TableDescriptor, name string, column Column) {
+	nullable, ok := column.Nullable()
+	descriptor.Nullables[name] = nullable || !ok
+	switch sqlTypeCategory(column.DatabaseTypeName()) {
+	case "int":
+		descriptor.ColumnTypes[name] = "int64"
+	case "float":
+		descriptor.ColumnTypes[name] = "float64"
+	case "decimal":
+		descriptor.ColumnTypes[name] = "float64"
+		if precision, scale, ok := column.DecimalSize(); ok && precision > 0 {
+			descriptor.SQLTypes[name] = fmt.Sprintf("DECIMAL(%v, %v)", precision, scale)
+		}
+	case "bool":
+		descriptor.ColumnTypes[name] = "bool"
+	case "time":
+		descriptor.ColumnTypes[name] = "time.Time"
+	case "bytes":
+		descriptor.ColumnTypes[name] = "[]uint8"
+	default:
+		descriptor.ColumnTypes[name] = "string"
+		descriptor.ColumnSizes[name] = maxTextSize
+		if length, ok := column.Length(); ok && length > 0 && length <= maxVarcharSize && strings.Contains(strings.ToUpper(column.DatabaseTypeName()), "CHAR") {
+			descriptor.ColumnSizes[name] = int(length)
+		}
+	}
+}
+
+//mapColumn returns destination column name for passed in source column or false if column was dropped
+func (t *Transfer) mapColumn(column string) (string, bool) {
+	if mapped, ok := t.Mapping[column]; ok {
+		return mapped, mapped != ""
+	}
+	return column, true
+}
+
+//readRecord reads scanner row as mapped and transformed record
+func (s *transferState) readRecord(scanner Scanner) (map[string]interface{}, error) {
+	columns, err := scanner.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var values = make([]interface{}, len(columns))
+	var pointers = make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err = scanner.Scan(pointers...); err != nil {
+		return nil, err
+	}
+	var record = make(map[string]interface{})
+	for i, column := range columns {
+		name, ok := s.mapColumn(column)
+		if !ok {
+			continue
+		}
+		if bytes, isBytes := values[i].([]byte); isBytes {
+			values[i] = string(bytes)
+		}
+		record[name] = values[i]
+	}
+	for _, transform := range s.Transforms {
+		if record, err = transform(record); err != nil || record == nil {
+			return nil, err
+		}
+	}
+	if s.columns == nil {
+		for _, column := range columns {
+			if name, ok := s.mapColumn(column); ok {
+				if _, has := record[name]; has {
+					s.columns = append(s.columns, name)
+				}
+			}
+		}
+		var extra = make([]string, 0)
+		for name := range record {
+			if !toolbox.HasSliceAnyElements(s.columns, name) {
+				extra = append(extra, name)
+			}
+		}
+		sort.Strings(extra)
+		s.columns = append(s.columns, extra...)
+	}
+	return record, nil
+}
+
+//persist writes current batch to destination table, replayed batch is merged by primary key since it might have been already written
+func (s *transferState) persist(replay bool) (int, error) {
+	if s.provider == nil {
+		s.descriptor = &TableDescriptor{Table: s.DestTable, Columns: s.columns, PkColumns: append([]string{}, s.PkColumns...)}
+		if len(s.descriptor.PkColumns) == 0 {
+			if dialect := GetDatastoreDialect(s.Dest.Config().DriverName); dialect != nil {
+				datastore, _ := dialect.GetCurrentDatastore(s.Dest)
+				if keyName := dialect.GetKeyName(s.Dest, datastore, s.DestTable); keyName != "" {
+					for _, column := range strings.Split(keyName, ",") {
+						s.descriptor.PkColumns = append(s.descriptor.PkColumns, strings.TrimSpace(column))
+					}
+				}
+			}
+		}
+		s.provider = NewMapDmlProvider(s.descriptor)
+	}
+	if s.Merge || (replay && len(s.descriptor.PkColumns) > 0) {
+		if !s.Dest.TableDescriptorRegistry().Has(s.DestTable) {
+			if err := s.Dest.TableDescriptorRegistry().Register(s.descriptor); err != nil {
+				return 0, err
+			}
+		}
+		if contextManager, ok := s.Dest.(ContextManager); ok {
+			inserted, updated, err := contextManager.PersistAllContext(s.ctx, &s.batch, s.DestTable, s.provider)
+			return inserted + updated, err
+		}
+		inserted, updated, err := s.Dest.PersistAll(&s.batch, s.DestTable, s.provider)
+		return inserted + updated, err
+	}
+	var written = 0
+	err := s.Dest.WithTransaction(func(tx Connection) (err error) {
+		sqlProvider := func(item interface{}) *ParametrizedSQL {
+			return s.provider.Get(SQLTypeInsert, item)
+		}
+		if contextManager, ok := s.Dest.(ContextManager); ok {
+			written, err = contextManager.PersistDataContext(s.ctx, tx, s.batch, s.DestTable, nil, sqlProvider)
+		} else {
+			written, err = s.Dest.PersistData(tx, s.batch, s.DestTable, nil, sqlProvider)
+		}
+		return err
+	})
+	return written, err
+}
+
+//NewCopier creates a new copier, checkpoints store is optional, if provided interrupted copy resumes from the last persisted batch
+func NewCopier(checkpoints CheckpointStore) *Copier {
+	return &Copier{checkpoints: checkpoints}
+}
@@ -0,0 +1,205 @@
+package dsc_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestCopier_Copy(t *testing.T) {
+	source, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/copy_src.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	dest, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/copy_dest.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS users",
+		"CREATE TABLE users (id INTEGER NOT NULL PRIMARY KEY, username varchar(255), password varchar(255), active INTEGER)",
+	} {
+		_, err = source.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	for i := 1; i <= 10; i++ {
+		_, err = source.Execute("INSERT INTO users(id, username, password, active) VALUES(?, ?, ?, ?)", i, fmt.Sprintf("user%v", i), "secret", i%5)
+		assert.Nil(t, err)
+	}
+	_, err = dest.Execute("DROP TABLE IF EXISTS accounts")
+	assert.Nil(t, err)
+
+	var failAt = 7
+	transfer := &dsc.Transfer{
+		Source:    source,
+		SourceSQL: "SELECT id, username, password, active FROM users ORDER BY id",
+		Dest:      dest,
+		DestTable: "accounts",
+		Mapping:   map[string]string{"username": "name", "password": ""},
+		BatchSize: 3,
+		Transforms: []dsc.RecordTransformer{
+			func(record map[string]interface{}) (map[string]interface{}, error) {
+				if record["id"] == int64(failAt) {
+					return nil, fmt.Errorf("interrupted")
+				}
+				if record["active"] == int64(0) {
+					return nil, nil
+				}
+				return record, nil
+			},
+		},
+	}
+	copier := dsc.NewCopier(dsc.NewFileCheckpointStore(t.TempDir()))
+	result, err := copier.Copy(context.Background(), transfer)
+	assert.NotNil(t, err)
+	assert.True(t, result.Created)
+	assert.Equal(t, 3, result.Written)
+
+	failAt = 0
+	result, err = copier.Copy(context.Background(), transfer)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.False(t, result.Created)
+	assert.Equal(t, 10, result.Read)
+	assert.Equal(t, 5, result.Skipped)
+	assert.Equal(t, 5, result.Written)
+
+	var accounts = make([]map[string]interface{}, 0)
+	err = dest.ReadAll(&accounts, "SELECT * FROM accounts ORDER BY id", nil, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 8, len(accounts))
+	assert.EqualValues(t, "user1", accounts[0]["name"])
+	_, hasPassword := accounts[0]["password"]
+	assert.False(t, hasPassword)
+
+	result, err = copier.Copy(context.Background(), transfer)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Read)
+}
+
+type interruptingCheckpointStore struct {
+	dsc.CheckpointStore
+	interrupted bool
+}
+
+func (s *interruptingCheckpointStore) Save(id string, checkpoint *dsc.Checkpoint) error {
+	if !s.interrupted && checkpoint.Rows > 0 && checkpoint.Pending == 0 {
+		s.interrupted = true
+		return fmt.Errorf("interrupted")
+	}
+	return s.CheckpointStore.Save(id, checkpoint)
+}
+
+func TestCopier_Copy_Resume(t *testing.T) {
+	source, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/copy_src.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	dest, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/copy_dest.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS items",
+		"CREATE TABLE items (id INTEGER NOT NULL PRIMARY KEY, name varchar(20), price DECIMAL(7, 2))",
+	} {
+		_, err = source.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	for i := 1; i <= 5; i++ {
+		_, err = source.Execute("INSERT INTO items(id, name, price) VALUES(?, ?, ?)", i, fmt.Sprintf("item%v", i), float64(i)+0.5)
+		assert.Nil(t, err)
+	}
+	_, err = dest.Execute("DROP TABLE IF EXISTS items_copy")
+	assert.Nil(t, err)
+
+	transfer := &dsc.Transfer{
+		Source:    source,
+		SourceSQL: "SELECT id, name, price FROM items ORDER BY id",
+		Dest:      dest,
+		DestTable: "items_copy",
+		PkColumns: []string{"id"},
+		BatchSize: 2,
+	}
+	copier := dsc.NewCopier(&interruptingCheckpointStore{CheckpointStore: dsc.NewFileCheckpointStore(t.TempDir())})
+	result, err := copier.Copy(context.Background(), transfer)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, result.Written)
+
+	result, err = copier.Copy(context.Background(), transfer)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 5, result.Read)
+
+	var items = make([]map[string]interface{}, 0)
+	err = dest.ReadAll(&items, "SELECT * FROM items_copy ORDER BY id", nil, nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 5, len(items))
+}
+
+type copyContextKey struct{}
+
+func TestCopier_Copy_MergeContext(t *testing.T) {
+	source, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/copy_src.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	var withCopyContext, writes = 0, 0
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/copy_dest.db")
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			if operation.Table == "merged_items" && operation.Kind != dsc.OperationRead {
+				writes++
+				if operation.Context.Value(copyContextKey{}) != nil {
+					withCopyContext++
+				}
+			}
+			return next(operation)
+		}),
+	}
+	dest, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS merge_src",
+		"CREATE TABLE merge_src (id INTEGER NOT NULL PRIMARY KEY, name varchar(20))",
+		"INSERT INTO merge_src(id, name) VALUES(1, 'a'), (2, 'b')",
+	} {
+		_, err = source.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS merged_items",
+		"CREATE TABLE merged_items (id INTEGER NOT NULL PRIMARY KEY, name varchar(20))",
+		"INSERT INTO merged_items(id, name) VALUES(1, 'old')",
+	} {
+		_, err = dest.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	writes, withCopyContext = 0, 0
+	ctx := context.WithValue(context.Background(), copyContextKey{}, true)
+	result, err := dsc.NewCopier(nil).Copy(ctx, &dsc.Transfer{
+		Source:    source,
+		SourceSQL: "SELECT id, name FROM merge_src ORDER BY id",
+		Dest:      dest,
+		DestTable: "merged_items",
+		PkColumns: []string{"id"},
+		Merge:     true,
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 2, result.Written)
+	assert.True(t, writes > 0)
+	assert.Equal(t, writes, withCopyContext, "merge writes should use copy context")
+}
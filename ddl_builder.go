@@ -0,0 +1,210 @@
+package dsc
+
+import (
+	"fmt"
+	"strings"
+)
+
+var createTableSQLTemplate = "CREATE TABLE %v(%v)"
+
+const (
+	defaultVarcharSize = 255
+	maxVarcharSize     = 65535 //string column with larger size uses dialect text type
+	maxTextSize        = 1<<31 - 1
+)
+
+//ddlSpec represents dialect column types and autoincrement syntax used to build CREATE TABLE statement
+type ddlSpec struct {
+	types          map[string]string //column type by go type kind, %v is replaced with column size, text is used for string larger than maxVarcharSize
+	autoincrement  string            //autoincrement column type, %v is replaced with column type
+	inlineKey      bool              //autoincrement type already declares primary key, i.e. SQLite INTEGER PRIMARY KEY AUTOINCREMENT
+	addColumn      string            //add column template with table and column definition
+	modifyColumn   []string          //modify column templates with table, column, type, NULL or NOT NULL, DROP NOT NULL or SET NOT NULL indexed arguments, empty if not supported
+	modifyNullable string            //modify column nullability template with the same arguments, empty if nullability is set by modifyColumn
+	addPrimaryKey  string            //add primary key template with table and key columns, empty if not supported
+	dropPrimaryKey string            //drop primary key template with table, empty if primary key constraint name is required
+}
+
+const (
+	ansiAddColumn     = "ALTER TABLE %v ADD COLUMN %v"
+	ansiDropColumn    = "ALTER TABLE %v DROP COLUMN %v"
+	ansiAddPrimaryKey = "ALTER TABLE %v ADD PRIMARY KEY(%v)"
+)
+
+var ansiDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "INTEGER", "int64": "BIGINT", "float32": "REAL", "float64": "DOUBLE PRECISION", "bool": "BOOLEAN",
+		"string": "VARCHAR(%v)", "text": "CLOB", "time": "TIMESTAMP", "bytes": "BLOB"},
+	autoincrement:  "%v",
+	addColumn:      ansiAddColumn,
+	modifyColumn:   []string{"ALTER TABLE %[1]v ALTER COLUMN %[2]v SET DATA TYPE %[3]v"},
+	modifyNullable: "ALTER TABLE %[1]v ALTER COLUMN %[2]v %[5]v",
+	addPrimaryKey:  ansiAddPrimaryKey,
+}
+
+var mySQLDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "INT", "int64": "BIGINT", "float32": "FLOAT", "float64": "DOUBLE", "bool": "BOOLEAN",
+		"string": "VARCHAR(%v)", "text": "LONGTEXT", "time": "DATETIME", "bytes": "BLOB"},
+	autoincrement:  "%v AUTO_INCREMENT",
+	addColumn:      ansiAddColumn,
+	modifyColumn:   []string{"ALTER TABLE %[1]v MODIFY COLUMN %[2]v %[3]v %[4]v"},
+	addPrimaryKey:  ansiAddPrimaryKey,
+	dropPrimaryKey: "ALTER TABLE %v DROP PRIMARY KEY",
+}
+
+var pgDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "INTEGER", "int64": "BIGINT", "float32": "REAL", "float64": "DOUBLE PRECISION", "bool": "BOOLEAN",
+		"string": "VARCHAR(%v)", "text": "TEXT", "time": "TIMESTAMP", "bytes": "BYTEA"},
+	autoincrement:  "BIGSERIAL",
+	addColumn:      ansiAddColumn,
+	modifyColumn:   []string{"ALTER TABLE %[1]v ALTER COLUMN %[2]v TYPE %[3]v"},
+	modifyNullable: "ALTER TABLE %[1]v ALTER COLUMN %[2]v %[5]v",
+	addPrimaryKey:  ansiAddPrimaryKey,
+}
+
+var sqlLiteDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "INTEGER", "int64": "INTEGER", "float32": "REAL", "float64": "REAL", "bool": "BOOLEAN",
+		"string": "VARCHAR(%v)", "text": "TEXT", "time": "TIMESTAMP", "bytes": "BLOB"},
+	autoincrement: "INTEGER PRIMARY KEY AUTOINCREMENT",
+	inlineKey:     true,
+	addColumn:     ansiAddColumn,
+}
+
+var msSQLDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "INT", "int64": "BIGINT", "float32": "REAL", "float64": "FLOAT", "bool": "BIT",
+		"string": "NVARCHAR(%v)", "text": "NVARCHAR(MAX)", "time": "DATETIME2", "bytes": "VARBINARY(MAX)"},
+	autoincrement: "%v IDENTITY(1,1)",
+	addColumn:     "ALTER TABLE %v ADD %v",
+	modifyColumn:  []string{"ALTER TABLE %[1]v ALTER COLUMN %[2]v %[3]v %[4]v"},
+	addPrimaryKey: ansiAddPrimaryKey,
+}
+
+var oraDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "NUMBER(10)", "int64": "NUMBER(19)", "float32": "BINARY_FLOAT", "float64": "BINARY_DOUBLE", "bool": "NUMBER(1)",
+		"string": "VARCHAR2(%v)", "text": "CLOB", "time": "TIMESTAMP", "bytes": "BLOB"},
+	autoincrement:  "%v GENERATED BY DEFAULT AS IDENTITY",
+	addColumn:      "ALTER TABLE %v ADD (%v)",
+	modifyColumn:   []string{"ALTER TABLE %[1]v MODIFY (%[2]v %[3]v)"},
+	modifyNullable: "ALTER TABLE %[1]v MODIFY (%[2]v %[4]v)",
+	addPrimaryKey:  ansiAddPrimaryKey,
+}
+
+var verticaDDLSpec = &ddlSpec{
+	types: map[string]string{"int": "INT", "int64": "INT", "float32": "FLOAT", "float64": "FLOAT", "bool": "BOOLEAN",
+		"string": "VARCHAR(%v)", "text": "LONG VARCHAR", "time": "TIMESTAMP", "bytes": "VARBINARY"},
+	autoincrement:  "AUTO_INCREMENT",
+	addColumn:      ansiAddColumn,
+	modifyColumn:   []string{"ALTER TABLE %[1]v ALTER COLUMN %[2]v SET DATA TYPE %[3]v"},
+	modifyNullable: "ALTER TABLE %[1]v ALTER COLUMN %[2]v %[5]v",
+	addPrimaryKey:  ansiAddPrimaryKey,
+}
+
+//goTypeKind returns DDL kind for passed in go type name, unknown types are stored as string
+func goTypeKind(typeName string) string {
+	switch typeName {
+	case "int8", "int16", "int32", "uint8", "uint16", "uint32", "sql.NullInt16", "sql.NullInt32", "sql.NullByte":
+		return "int"
+	case "int", "int64", "uint", "uint64", "sql.NullInt64":
+		return "int64"
+	case "float32":
+		return "float32"
+	case "float64", "sql.NullFloat64":
+		return "float64"
+	case "bool", "sql.NullBool":
+		return "bool"
+	case "time.Time", "sql.NullTime":
+		return "time"
+	case "[]uint8", "json.RawMessage":
+		return "bytes"
+	}
+	return "string"
+}
+
+//columnSQLType returns column SQL type, explicit sqlType tag takes precedence over go type mapping
+func (s *ddlSpec) columnSQLType(descriptor *TableDescriptor, column string) string {
+	if sqlType, ok := descriptor.SQLTypes[column]; ok {
+		return sqlType
+	}
+	kind := goTypeKind(descriptor.ColumnTypes[column])
+	size := defaultVarcharSize
+	if columnSize, ok := descriptor.ColumnSizes[column]; ok {
+		size = columnSize
+	}
+	if kind == "string" && size > maxVarcharSize && s.types["text"] != "" {
+		kind = "text"
+	}
+	columnType := s.types[kind]
+	if strings.Contains(columnType, "%v") {
+		columnType = fmt.Sprintf(columnType, size)
+	}
+	return columnType
+}
+
+//columnDefinition returns column definition with type, default, nullability and unique constraint
+func (s *ddlSpec) columnDefinition(descriptor *TableDescriptor, column string) string {
+	isPk := hasColumn(descriptor.PkColumns, column)
+	columnType := s.columnSQLType(descriptor, column)
+	if isPk && descriptor.Autoincrement {
+		if strings.Contains(s.autoincrement, "%v") {
+			columnType = fmt.Sprintf(s.autoincrement, columnType)
+		} else {
+			columnType = s.autoincrement
+		}
+	}
+	definition := column + " " + columnType
+	if value, ok := descriptor.ColumnDefaults[column]; ok {
+		definition += " DEFAULT " + value
+	}
+	if isPk || !descriptor.Nullables[column] {
+		definition += " NOT NULL"
+	}
+	if !isPk && hasColumn(descriptor.UniqueColumns, column) {
+		definition += " UNIQUE"
+	}
+	return definition
+}
+
+//createTableSQL returns CREATE TABLE statement for passed in descriptor
+func (s *ddlSpec) createTableSQL(descriptor *TableDescriptor) (string, error) {
+	if len(descriptor.Columns) == 0 {
+		return "", fmt.Errorf("columns were empty for table: %v", descriptor.Table)
+	}
+	inlineKey := s.inlineKey && descriptor.Autoincrement && len(descriptor.PkColumns) == 1
+	var definitions = make([]string, 0, len(descriptor.Columns)+1)
+	for _, column := range descriptor.Columns {
+		definitions = append(definitions, s.columnDefinition(descriptor, column))
+	}
+	if len(descriptor.PkColumns) > 0 && !inlineKey {
+		definitions = append(definitions, "PRIMARY KEY("+strings.Join(descriptor.PkColumns, ", ")+")")
+	}
+	return fmt.Sprintf(createTableSQLTemplate, descriptor.Table, strings.Join(definitions, ", ")), nil
+}
+
+func hasColumn(columns []string, column string) bool {
+	for _, candidate := range columns {
+		if strings.EqualFold(candidate, column) {
+			return true
+		}
+	}
+	return false
+}
+
+//tableDescriptorFor returns table descriptor for passed in specification: *TableDescriptor, TableDescriptor or struct instance/pointer
+func tableDescriptorFor(table string, specification interface{}) (*TableDescriptor, error) {
+	var descriptor *TableDescriptor
+	switch value := specification.(type) {
+	case *TableDescriptor:
+		copied := *value
+		descriptor = &copied
+	case TableDescriptor:
+		descriptor = &value
+	default:
+		var err error
+		if descriptor, err = NewTableDescriptor(table, specification); err != nil {
+			return nil, err
+		}
+	}
+	if table != "" {
+		descriptor.Table = table
+	}
+	return descriptor, nil
+}
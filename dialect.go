@@ -64,6 +64,18 @@ func (d DefaultDialect) GetKeyName(manager Manager, datastore, table string) str
 	return ""
 }
 
+func (d DefaultDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return []*Index{}, nil
+}
+
+func (d DefaultDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return []*ForeignKey{}, nil
+}
+
+func (d DefaultDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return []*Constraint{}, nil
+}
+
 func (d DefaultDialect) IsAutoincrement(manager Manager, datastore, table string) bool {
 	return false
 }
@@ -98,6 +110,46 @@ func (d DefaultDialect) CanHandleTransaction() bool {
 	return false
 }
 
+func (d DefaultDialect) CanUseSavepoint() bool {
+	return false
+}
+
+func (d DefaultDialect) SavepointSQL(action int, name string) string {
+	return ""
+}
+
+func (d DefaultDialect) DeferConstraintsSQL() string {
+	return ""
+}
+
+func (d DefaultDialect) CanUseRowValueIn() bool {
+	return true
+}
+
+func (d DefaultDialect) ReturningSQL(columns []string) string {
+	return ""
+}
+
+func (d DefaultDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	return ""
+}
+
+func (d DefaultDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return "", errUnsupportedOperation
+}
+
+func (d DefaultDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return nil, errUnsupportedOperation
+}
+
+func (d DefaultDialect) TranslateError(err error) error {
+	return translateCommonError(err)
+}
+
+func (d DefaultDialect) IsRetryable(err error) bool {
+	return isTransientError(err)
+}
+
 //EachTable iterates each datastore table
 func (d DefaultDialect) EachTable(manager Manager, handler func(table string) error) error {
 	dbname, err := d.GetCurrentDatastore(manager)
@@ -18,7 +18,10 @@ type DmlBuilder struct {
 	InsertSQL       string
 	UpdateSQL       string
 	DeleteSQL       string
+	UpsertSQL       string
+	updateColumns   []string
 	reserved        *Reserved
+	dialect         DatastoreDialect
 }
 
 func (b *DmlBuilder) readValues(columns []string, valueProvider func(column string) interface{}) []interface{} {
@@ -50,21 +53,84 @@ func (b *DmlBuilder) GetParametrizedSQL(sqlType int, valueProvider func(column s
 		}
 
 	case SQLTypeUpdate:
+		columns := b.updateColumns
+		if columns == nil {
+			columns = *b.Columns
+		}
 		return &ParametrizedSQL{
 			SQL:    b.UpdateSQL,
-			Values: b.readValues(*b.Columns, valueProvider),
+			Values: b.readValues(columns, valueProvider),
 			Type:   SQLTypeUpdate,
 		}
 	case SQLTypeDelete:
+		columns := b.TableDescriptor.PkColumns
+		if version := b.TableDescriptor.VersionColumn; version != "" {
+			columns = append(append([]string{}, columns...), version)
+		}
 		return &ParametrizedSQL{
 			SQL:    b.DeleteSQL,
-			Values: b.readValues(b.TableDescriptor.PkColumns, valueProvider),
+			Values: b.readValues(columns, valueProvider),
 			Type:   SQLTypeDelete,
 		}
+	case SQLTypeUpsert:
+		if b.UpsertSQL == "" {
+			break
+		}
+		return &ParametrizedSQL{
+			SQL:    b.UpsertSQL,
+			Values: b.readValues(*b.Columns, valueProvider),
+			Type:   SQLTypeUpsert,
+		}
 	}
 	panic(fmt.Sprintf("Unsupprted sqltype:%v", sqlType))
 }
 
+// GetBatchUpdateParametrizedSQL returns ParametrizedSQL updating all rows with one statement for passed in row value providers,
+// non pk columns are set with CASE expression, it returns nil if there are no non pk columns to update.
+func (b *DmlBuilder) GetBatchUpdateParametrizedSQL(valueProviders []func(column string) interface{}) *ParametrizedSQL {
+	if len(*b.NonPkColumns) == 0 || len(valueProviders) == 0 {
+		return nil
+	}
+	nonPk := append([]string{}, (*b.NonPkColumns)...)
+	pk := append([]string{}, b.TableDescriptor.PkColumns...)
+	if b.reserved != nil {
+		b.reserved.quoteIfReserved(nonPk)
+		b.reserved.quoteIfReserved(pk)
+	} else {
+		updateReserved(nonPk)
+		updateReserved(pk)
+	}
+	var pkValues = make([][]interface{}, len(valueProviders))
+	for i, valueProvider := range valueProviders {
+		pkValues[i] = b.readValues(b.TableDescriptor.PkColumns, valueProvider)
+	}
+	keyCriteria := strings.TrimPrefix(buildAssignValueSQL(pk, " AND"), " ")
+	var values = make([]interface{}, 0)
+	var assignments = make([]string, len(nonPk))
+	for i, column := range *b.NonPkColumns {
+		assignment := nonPk[i] + " = CASE"
+		for j, valueProvider := range valueProviders {
+			assignment += " WHEN " + keyCriteria + " THEN ?"
+			values = append(values, pkValues[j]...)
+			values = append(values, valueProvider(column))
+		}
+		//ELSE branch lets datastore (i.e. postgres) infer parameter type from the column
+		assignments[i] = assignment + " ELSE " + nonPk[i] + " END"
+	}
+	where, whereValues := buildInCriteria(pk, pkValues, b.dialect == nil || b.dialect.CanUseRowValueIn())
+	return &ParametrizedSQL{
+		SQL:    fmt.Sprintf(updateSQLTemplate, b.TableDescriptor.Table, strings.Join(assignments, ", "), where),
+		Values: append(values, whereValues...),
+		Type:   SQLTypeUpdate,
+	}
+}
+
+// batchUpdateRowParameters returns number of bind parameters used by one row in batch update statement.
+func (b *DmlBuilder) batchUpdateRowParameters() int {
+	pkCount := len(b.TableDescriptor.PkColumns)
+	return len(*b.NonPkColumns)*(pkCount+1) + pkCount
+}
+
 func buildAssignValueSQL(columns []string, separator string) string {
 	result := ""
 	for _, column := range columns {
@@ -97,6 +163,10 @@ func buildInsertSQL(descriptor *TableDescriptor, columns []string, nonPkColumns
 }
 
 func buildUpdateSQL(descriptor *TableDescriptor, nonPkColumns []string, reserved *Reserved) string {
+	version := descriptor.VersionColumn
+	if version != "" {
+		nonPkColumns = versionlessColumns(nonPkColumns, version)
+	}
 	if reserved != nil {
 		reserved.quoteIfReserved(nonPkColumns)
 	} else {
@@ -108,7 +178,16 @@ func buildUpdateSQL(descriptor *TableDescriptor, nonPkColumns []string, reserved
 	} else {
 		updateReserved(pk)
 	}
-	return fmt.Sprintf(updateSQLTemplate, descriptor.Table, buildAssignValueSQL(nonPkColumns, ","), buildAssignValueSQL(pk, " AND "))
+	assignments, where := buildAssignValueSQL(nonPkColumns, ","), buildAssignValueSQL(pk, " AND ")
+	if version != "" { //optimistic lock
+		version = quoteColumn(version, reserved)
+		if assignments != "" {
+			assignments += ","
+		}
+		assignments += " " + version + " = " + version + " + 1"
+		where += " AND " + version + " = ?"
+	}
+	return fmt.Sprintf(updateSQLTemplate, descriptor.Table, assignments, where)
 }
 
 func buildDeleteSQL(descriptor *TableDescriptor, reserved *Reserved) string {
@@ -118,7 +197,58 @@ func buildDeleteSQL(descriptor *TableDescriptor, reserved *Reserved) string {
 	} else {
 		updateReserved(pk)
 	}
-	return fmt.Sprintf(deleteSQLTemplate, descriptor.Table, buildAssignValueSQL(pk, " AND "))
+	where := buildAssignValueSQL(pk, " AND ")
+	if version := descriptor.VersionColumn; version != "" {
+		where += " AND " + quoteColumn(version, reserved) + " = ?"
+	}
+	return fmt.Sprintf(deleteSQLTemplate, descriptor.Table, where)
+}
+
+func quoteColumn(column string, reserved *Reserved) string {
+	columns := []string{column}
+	if reserved != nil {
+		reserved.quoteIfReserved(columns)
+	} else {
+		updateReserved(columns)
+	}
+	return columns[0]
+}
+
+// versionlessColumns returns columns without version column
+func versionlessColumns(columns []string, version string) []string {
+	var result = make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column != version {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// buildUpdateColumns returns update statement binding columns: non pk, pk and version columns
+func buildUpdateColumns(descriptor *TableDescriptor, nonPkColumns []string) []string {
+	if descriptor.VersionColumn == "" {
+		return append(append([]string{}, nonPkColumns...), descriptor.PkColumns...)
+	}
+	result := versionlessColumns(nonPkColumns, descriptor.VersionColumn)
+	result = append(result, descriptor.PkColumns...)
+	return append(result, descriptor.VersionColumn)
+}
+
+func buildUpsertSQL(descriptor *TableDescriptor, columns []string, reserved *Reserved, dialect DatastoreDialect) string {
+	if dialect == nil || len(descriptor.PkColumns) == 0 {
+		return ""
+	}
+	upsertColumns := append([]string{}, columns...)
+	pk := append([]string{}, descriptor.PkColumns...)
+	if reserved != nil {
+		reserved.quoteIfReserved(upsertColumns)
+		reserved.quoteIfReserved(pk)
+	} else {
+		updateReserved(upsertColumns)
+		updateReserved(pk)
+	}
+	return dialect.UpsertSQL(descriptor.Table, upsertColumns, pk)
 }
 
 // NewDmlBuilder returns a new DmlBuilder for passed in table descriptor.
@@ -147,6 +277,7 @@ func NewDmlBuilder(descriptor *TableDescriptor) *DmlBuilder {
 		TableDescriptor: descriptor,
 		NonPkColumns:    &nonPkColumns,
 		Columns:         &columns,
+		updateColumns:   buildUpdateColumns(descriptor, nonPkColumns),
 		InsertSQL:       buildInsertSQL(descriptor, columns, nonPkColumns, nil),
 		UpdateSQL:       buildUpdateSQL(descriptor, nonPkColumns, nil),
 		DeleteSQL:       buildDeleteSQL(descriptor, nil),
@@ -164,6 +295,25 @@ func (b *DmlBuilder) RebuildWithReserved(res *Reserved) {
 	b.InsertSQL = buildInsertSQL(b.TableDescriptor, cols, nonPk, res)
 	b.UpdateSQL = buildUpdateSQL(b.TableDescriptor, nonPk, res)
 	b.DeleteSQL = buildDeleteSQL(b.TableDescriptor, res)
+	b.UpsertSQL = buildUpsertSQL(b.TableDescriptor, cols, res, b.dialect)
+}
+
+// clone returns a copy of builder, so that copy can be rebuilt without affecting builder shared by other goroutines
+func (b *DmlBuilder) clone() *DmlBuilder {
+	result := *b
+	return &result
+}
+
+// RebuildWithDialect builds upsert statement with supplied dialect, it returns false if dialect does not support upsert.
+func (b *DmlBuilder) RebuildWithDialect(dialect DatastoreDialect) bool {
+	if b == nil {
+		return false
+	}
+	if b.dialect != dialect {
+		b.dialect = dialect
+		b.UpsertSQL = buildUpsertSQL(b.TableDescriptor, *b.Columns, b.reserved, dialect)
+	}
+	return b.UpsertSQL != ""
 }
 
 // RebuildWithKeywords rebuilds SQL statements using supplied keywords (enables quoting).
@@ -101,11 +101,40 @@ func (p *metaDmlProvider) withReserved(res *Reserved) *metaDmlProvider {
 	if p == nil {
 		return p
 	}
-	if res == nil {
+	if res == nil || p.dmlBuilder.reserved == res {
 		return p
 	}
-	p.dmlBuilder.RebuildWithReserved(res)
-	return p
+	builder := p.dmlBuilder.clone()
+	builder.RebuildWithReserved(res)
+	return &metaDmlProvider{dmlBuilder: builder, columnToFieldNameMap: p.columnToFieldNameMap}
+}
+
+// batchUpdate returns update statement for passed in instances
+func (p *metaDmlProvider) batchUpdate(instances []interface{}) *ParametrizedSQL {
+	var valueProviders = make([]func(column string) interface{}, len(instances))
+	for i, instance := range instances {
+		var reflectable = reflect.ValueOf(instance)
+		if reflectable.Kind() == reflect.Ptr {
+			reflectable = reflectable.Elem()
+		}
+		valueProviders[i] = func(column string) interface{} {
+			return p.readValue(reflectable, column)
+		}
+	}
+	return p.dmlBuilder.GetBatchUpdateParametrizedSQL(valueProviders)
+}
+
+func (p *metaDmlProvider) builder() *DmlBuilder {
+	return p.dmlBuilder
+}
+
+// withDialect returns a copy of provider with upsert statement built for dialect, it returns false if dialect does not support upsert
+func (p *metaDmlProvider) withDialect(dialect DatastoreDialect) (DmlProvider, bool) {
+	builder := p.dmlBuilder.clone()
+	if !builder.RebuildWithDialect(dialect) {
+		return nil, false
+	}
+	return &metaDmlProvider{dmlBuilder: builder, columnToFieldNameMap: p.columnToFieldNameMap}, true
 }
 
 // NewDmlProviderIfNeeded returns a new NewDmlProvider for a table and target type if passed provider was nil.
@@ -150,6 +179,29 @@ func (p *mapDmlProvider) Get(sqlType int, instance interface{}) *ParametrizedSQL
 	})
 }
 
+func (p *mapDmlProvider) batchUpdate(instances []interface{}) *ParametrizedSQL {
+	var valueProviders = make([]func(column string) interface{}, len(instances))
+	for i, instance := range instances {
+		var record = toolbox.AsMap(instance)
+		valueProviders[i] = func(column string) interface{} {
+			return record[column]
+		}
+	}
+	return p.dmlBuilder.GetBatchUpdateParametrizedSQL(valueProviders)
+}
+
+func (p *mapDmlProvider) builder() *DmlBuilder {
+	return p.dmlBuilder
+}
+
+func (p *mapDmlProvider) withDialect(dialect DatastoreDialect) (DmlProvider, bool) {
+	builder := p.dmlBuilder.clone()
+	if !builder.RebuildWithDialect(dialect) {
+		return nil, false
+	}
+	return &mapDmlProvider{tableDescriptor: p.tableDescriptor, dmlBuilder: builder}, true
+}
+
 func NewMapDmlProvider(descriptor *TableDescriptor) DmlProvider {
 	var result = &mapDmlProvider{
 		tableDescriptor: descriptor,
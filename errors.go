@@ -0,0 +1,188 @@
+package dsc
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+)
+
+var (
+	//ErrNotFound represents missing row error
+	ErrNotFound = errors.New("not found")
+	//ErrDuplicateKey represents unique or primary key constraint violation
+	ErrDuplicateKey = errors.New("duplicate key")
+	//ErrForeignKeyViolation represents foreign key constraint violation
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	//ErrDeadlock represents deadlock or serialization failure, operation can be retried
+	ErrDeadlock = errors.New("deadlock")
+	//ErrConnection represents broken or refused connection
+	ErrConnection = errors.New("connection failure")
+	//ErrTimeout represents statement, lock wait or context deadline timeout
+	ErrTimeout = errors.New("timeout")
+	//ErrSyntax represents invalid SQL error
+	ErrSyntax = errors.New("syntax error")
+)
+
+//Error represents datastore error, it matches its Kind sentinel and the original driver error with errors.Is and errors.As
+type Error struct {
+	Kind error //one of Err* sentinels, nil if dialect could not translate driver error
+	Err  error
+}
+
+//Error returns driver error message
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+//Unwrap returns error kind and driver error
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+//errorPattern maps lower case driver error message fragment to error kind
+type errorPattern struct {
+	fragment string
+	kind     error
+}
+
+var mySQLErrorPatterns = []errorPattern{
+	{"error 1062", ErrDuplicateKey},
+	{"error 1451", ErrForeignKeyViolation},
+	{"error 1452", ErrForeignKeyViolation},
+	{"error 1213", ErrDeadlock},
+	{"error 1205", ErrTimeout},
+	{"error 3024", ErrTimeout},
+	{"error 1064", ErrSyntax},
+	{"error 1149", ErrSyntax},
+	{"invalid connection", ErrConnection},
+	{"bad connection", ErrConnection},
+}
+
+var pgErrorPatterns = []errorPattern{
+	{"duplicate key value", ErrDuplicateKey},
+	{"violates foreign key constraint", ErrForeignKeyViolation},
+	{"deadlock detected", ErrDeadlock},
+	{"could not serialize access", ErrDeadlock},
+	{"canceling statement due to", ErrTimeout},
+	{"syntax error", ErrSyntax},
+	{"connection refused", ErrConnection},
+	{"bad connection", ErrConnection},
+}
+
+var sqlLiteErrorPatterns = []errorPattern{
+	{"unique constraint failed", ErrDuplicateKey},
+	{"foreign key constraint failed", ErrForeignKeyViolation},
+	{"database is locked", ErrTimeout},
+	{"database table is locked", ErrTimeout},
+	{"syntax error", ErrSyntax},
+	{"unable to open database", ErrConnection},
+}
+
+var oraErrorPatterns = []errorPattern{
+	{"ora-00001", ErrDuplicateKey},
+	{"ora-02291", ErrForeignKeyViolation},
+	{"ora-02292", ErrForeignKeyViolation},
+	{"ora-00060", ErrDeadlock},
+	{"ora-08177", ErrDeadlock},
+	{"ora-01013", ErrTimeout},
+	{"ora-00054", ErrTimeout},
+	{"ora-00900", ErrSyntax},
+	{"ora-00933", ErrSyntax},
+	{"ora-00936", ErrSyntax},
+	{"ora-03113", ErrConnection},
+	{"ora-03114", ErrConnection},
+	{"ora-12541", ErrConnection},
+}
+
+var msSQLErrorPatterns = []errorPattern{
+	{"violation of primary key constraint", ErrDuplicateKey},
+	{"violation of unique key constraint", ErrDuplicateKey},
+	{"cannot insert duplicate key", ErrDuplicateKey},
+	{"conflicted with the foreign key constraint", ErrForeignKeyViolation},
+	{"conflicted with the reference constraint", ErrForeignKeyViolation},
+	{"was deadlocked", ErrDeadlock},
+	{"lock request time out", ErrTimeout},
+	{"incorrect syntax", ErrSyntax},
+	{"bad connection", ErrConnection},
+}
+
+//sqlStateClassifier represents driver error exposing SQLSTATE code, i.e. lib/pq or pgx error
+type sqlStateClassifier interface {
+	SQLState() string
+}
+
+//translateCommonError returns error kind for database/sql, context, network and SQLSTATE errors, or nil
+func translateCommonError(err error) error {
+	var netErr net.Error
+	var stateErr sqlStateClassifier
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimeout
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return ErrConnection
+	case errors.As(err, &stateErr):
+		return sqlStateErrorKind(stateErr.SQLState())
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return ErrTimeout
+		}
+		return ErrConnection
+	}
+	return nil
+}
+
+//sqlStateErrorKind returns error kind for passed in SQLSTATE code, or nil
+func sqlStateErrorKind(state string) error {
+	switch {
+	case state == "23505":
+		return ErrDuplicateKey
+	case state == "23503":
+		return ErrForeignKeyViolation
+	case state == "40001", state == "40P01":
+		return ErrDeadlock
+	case state == "57014", state == "55P03":
+		return ErrTimeout
+	case state == "42601":
+		return ErrSyntax
+	case strings.HasPrefix(state, "08"):
+		return ErrConnection
+	}
+	return nil
+}
+
+//translateErrorWithPatterns returns common error kind, or error kind of the first pattern matching driver error message, or nil
+func translateErrorWithPatterns(err error, patterns []errorPattern) error {
+	if kind := translateCommonError(err); kind != nil {
+		return kind
+	}
+	message := strings.ToLower(err.Error())
+	for _, pattern := range patterns {
+		if strings.Contains(message, pattern.fragment) {
+			return pattern.kind
+		}
+	}
+	return nil
+}
+
+//isTransientError returns true for deadlock (including serialization failure) and connection errors
+func isTransientError(err error) bool {
+	return errors.Is(err, ErrDeadlock) || errors.Is(err, ErrConnection)
+}
+
+//translateError wraps driver error with dialect translated error kind, already translated errors are returned as is
+func (m *AbstractManager) translateError(err error) error {
+	var translated *Error
+	if err == nil || errors.As(err, &translated) {
+		return err
+	}
+	dialect := GetDatastoreDialect(m.config.DriverName)
+	return &Error{Kind: dialect.TranslateError(err), Err: err}
+}
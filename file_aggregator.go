@@ -0,0 +1,326 @@
+package dsc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+)
+
+var aggregateFunctions = map[string]bool{
+	"COUNT": true,
+	"SUM":   true,
+	"MIN":   true,
+	"MAX":   true,
+	"AVG":   true,
+}
+
+//isAggregateFunction returns true if passed in function is supported aggregate function
+func isAggregateFunction(function string) bool {
+	return aggregateFunctions[strings.ToUpper(function)]
+}
+
+//aggregateExpression returns normalized aggregate expression i.e. COUNT(DISTINCT id)
+func aggregateExpression(function, arguments string) string {
+	return strings.ToUpper(function) + "(" + strings.Join(strings.Fields(arguments), " ") + ")"
+}
+
+//parseAggregateExpression returns function and arguments for expression like SUM(x) or empty function
+func parseAggregateExpression(expression string) (string, string) {
+	begin := strings.Index(expression, "(")
+	if begin <= 0 || !strings.HasSuffix(expression, ")") {
+		return "", ""
+	}
+	function := strings.TrimSpace(expression[:begin])
+	if !isAggregateFunction(function) {
+		return "", ""
+	}
+	return function, expression[begin+1 : len(expression)-1]
+}
+
+//aggregateSpec represents aggregate function specification
+type aggregateSpec struct {
+	expression string
+	function   string
+	column     string
+	distinct   bool
+}
+
+func newAggregateSpec(function, arguments string) (*aggregateSpec, error) {
+	result := &aggregateSpec{expression: aggregateExpression(function, arguments), function: strings.ToUpper(function)}
+	column := strings.TrimSpace(arguments)
+	if len(column) > 9 && strings.EqualFold(column[:9], "DISTINCT ") {
+		result.distinct = true
+		column = strings.TrimSpace(column[9:])
+	}
+	if column == "" || (column == "*" && (result.function != "COUNT" || result.distinct)) {
+		return nil, fmt.Errorf("unsupported aggregate: %v", result.expression)
+	}
+	result.column = column
+	return result, nil
+}
+
+//aggregateState represents aggregate function state for a group
+type aggregateState struct {
+	count     int64
+	intSum    int64
+	floatSum  float64
+	isFloat   bool
+	value     interface{}
+	distincts map[string]bool
+}
+
+func (s *aggregateState) add(spec *aggregateSpec, record data.Map) {
+	var value interface{} = 1
+	if spec.column != "*" {
+		value, _ = record.GetValue(spec.column)
+		if value == nil {
+			return
+		}
+	}
+	if spec.distinct {
+		if s.distincts == nil {
+			s.distincts = make(map[string]bool)
+		}
+		key := toolbox.AsString(value)
+		if s.distincts[key] {
+			return
+		}
+		s.distincts[key] = true
+	}
+	s.count++
+	switch spec.function {
+	case "SUM", "AVG":
+		number, ok := asNumber(value)
+		if !ok {
+			number = toolbox.AsFloat(value)
+		}
+		if intValue, isInt := asInteger(value); isInt && !s.isFloat {
+			s.intSum += intValue
+		} else if !s.isFloat {
+			s.isFloat = true
+			s.floatSum = float64(s.intSum) + number
+		} else {
+			s.floatSum += number
+		}
+	case "MIN":
+		if s.count == 1 || compareValues(value, s.value) < 0 {
+			s.value = value
+		}
+	case "MAX":
+		if s.count == 1 || compareValues(value, s.value) > 0 {
+			s.value = value
+		}
+	}
+}
+
+func (s *aggregateState) result(spec *aggregateSpec) interface{} {
+	switch spec.function {
+	case "COUNT":
+		return int(s.count)
+	case "SUM":
+		if s.count == 0 {
+			return nil
+		}
+		if s.isFloat {
+			return s.floatSum
+		}
+		return int(s.intSum)
+	case "AVG":
+		if s.count == 0 {
+			return nil
+		}
+		if s.isFloat {
+			return s.floatSum / float64(s.count)
+		}
+		return float64(s.intSum) / float64(s.count)
+	}
+	return s.value
+}
+
+func asInteger(value interface{}) (int64, bool) {
+	switch actual := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return int64(toolbox.AsInt(actual)), true
+	case float64:
+		if actual == float64(int64(actual)) {
+			return int64(actual), true
+		}
+	case float32:
+		if actual == float32(int64(actual)) {
+			return int64(actual), true
+		}
+	}
+	if number, ok := value.(interface{ Int64() (int64, error) }); ok {
+		if result, err := number.Int64(); err == nil {
+			return result, true
+		}
+	}
+	return 0, false
+}
+
+//aggregateGroup represents group key values with aggregate states
+type aggregateGroup struct {
+	values []interface{}
+	states []*aggregateState
+}
+
+//fileAggregator groups matched records and computes aggregate functions
+type fileAggregator struct {
+	statement    *QueryStatement
+	aliases      []string
+	groupColumns []string
+	specs        []*aggregateSpec
+	specIndex    map[string]int
+	groups       map[string]*aggregateGroup
+	keys         []string
+}
+
+func (a *fileAggregator) addSpec(function, arguments string) error {
+	expression := aggregateExpression(function, arguments)
+	if _, ok := a.specIndex[expression]; ok {
+		return nil
+	}
+	spec, err := newAggregateSpec(function, arguments)
+	if err != nil {
+		return err
+	}
+	a.specIndex[expression] = len(a.specs)
+	a.specs = append(a.specs, spec)
+	return nil
+}
+
+//add adds matched record to its group
+func (a *fileAggregator) add(record map[string]interface{}) {
+	var recordMap = data.Map(record)
+	var values = make([]interface{}, len(a.groupColumns))
+	var key = ""
+	for i, column := range a.groupColumns {
+		values[i], _ = recordMap.GetValue(column)
+		key += toolbox.AsString(values[i]) + "\x1f"
+	}
+	group, ok := a.groups[key]
+	if !ok {
+		group = a.newGroup(key, values)
+	}
+	for i, spec := range a.specs {
+		group.states[i].add(spec, recordMap)
+	}
+}
+
+func (a *fileAggregator) newGroup(key string, values []interface{}) *aggregateGroup {
+	group := &aggregateGroup{values: values, states: make([]*aggregateState, len(a.specs))}
+	for i := range group.states {
+		group.states[i] = &aggregateState{}
+	}
+	a.groups[key] = group
+	a.keys = append(a.keys, key)
+	return group
+}
+
+//iterate passes each group projected row and source row (with group columns and aggregate expressions) to handler
+func (a *fileAggregator) iterate(handler func(projected, source map[string]interface{}) (bool, error)) error {
+	if len(a.keys) == 0 && len(a.groupColumns) == 0 { //aggregate without group by returns single row
+		a.newGroup("", nil)
+	}
+	for _, key := range a.keys {
+		group := a.groups[key]
+		var source = make(map[string]interface{})
+		for i, column := range a.groupColumns {
+			source[column] = group.values[i]
+		}
+		for i, spec := range a.specs {
+			source[spec.expression] = group.states[i].result(spec)
+		}
+		var projected = make(map[string]interface{})
+		for i, column := range a.statement.Columns {
+			if column.Function != "" {
+				projected[a.aliases[i]] = source[aggregateExpression(column.Function, column.FunctionArguments)]
+				continue
+			}
+			projected[a.aliases[i]] = source[column.Name]
+		}
+		if toContinue, err := handler(projected, source); err != nil || !toContinue {
+			return err
+		}
+	}
+	return nil
+}
+
+//havingRow returns row with HAVING criteria left operands values
+func (a *fileAggregator) havingRow(having *SQLCriteria, projected, source map[string]interface{}) map[string]interface{} {
+	var result = make(map[string]interface{})
+	for _, criterion := range having.Criteria {
+		operand := toolbox.AsString(criterion.LeftOperand)
+		if function, arguments := parseAggregateExpression(operand); function != "" {
+			result[operand] = source[aggregateExpression(function, arguments)]
+		} else if value, ok := projected[operand]; ok {
+			result[operand] = value
+		} else {
+			result[operand] = source[operand]
+		}
+	}
+	return result
+}
+
+//isAggregateQuery returns true if statement uses GROUP BY or aggregate functions
+func isAggregateQuery(statement *QueryStatement) bool {
+	if len(statement.GroupBy) > 0 {
+		return true
+	}
+	for _, column := range statement.Columns {
+		if column.Function != "" && isAggregateFunction(column.Function) {
+			return true
+		}
+	}
+	return false
+}
+
+func newFileAggregator(statement *QueryStatement, aliases []string) (*fileAggregator, error) {
+	result := &fileAggregator{
+		statement: statement,
+		aliases:   aliases,
+		specIndex: make(map[string]int),
+		groups:    make(map[string]*aggregateGroup),
+	}
+	var grouped = make(map[string]bool)
+	for _, column := range statement.GroupBy {
+		if column.Name == "" {
+			return nil, fmt.Errorf("unsupported GROUP BY expression: %v", column.Expression)
+		}
+		result.groupColumns = append(result.groupColumns, column.Name)
+		grouped[column.Name] = true
+	}
+	for _, column := range statement.Columns {
+		if column.Function != "" {
+			if !isAggregateFunction(column.Function) {
+				return nil, fmt.Errorf("unsupported function: %v", column.Expression)
+			}
+			if err := result.addSpec(column.Function, column.FunctionArguments); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if column.Name == "" || !grouped[column.Name] {
+			return nil, fmt.Errorf("column %v must appear in GROUP BY clause or be used in an aggregate function", column.Name+column.Expression)
+		}
+	}
+	if statement.Having != nil {
+		for _, criterion := range statement.Having.Criteria {
+			if function, arguments := parseAggregateExpression(toolbox.AsString(criterion.LeftOperand)); function != "" {
+				if err := result.addSpec(function, arguments); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, column := range statement.OrderBy {
+		if column.Function != "" && isAggregateFunction(column.Function) {
+			if err := result.addSpec(column.Function, column.FunctionArguments); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
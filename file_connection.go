@@ -15,10 +15,20 @@ type fileConnection struct {
 }
 
 func (fc *fileConnection) Close() error {
+	fc.closeFiles()
+	return fc.AbstractConnection.Close()
+}
+
+func (fc *fileConnection) CloseNow() error {
+	fc.closeFiles()
+	return nil
+}
+
+func (fc *fileConnection) closeFiles() {
 	for _, file := range fc.files {
 		file.Close()
 	}
-	return nil
+	fc.files = nil
 }
 
 func getFile(filename string, connection Connection) (*os.File, error) {
@@ -37,6 +37,20 @@ func (d fileDialect) DropTable(manager Manager, datastore string, table string)
 	return fileManager.service.Delete(object)
 }
 
+//CreateTable creates an empty table file in datastore managed by passed in manager, existing table file is left intact.
+func (d fileDialect) CreateTable(manager Manager, datastore string, table string, specification interface{}) error {
+	fileManager, ok := manager.(*FileManager)
+	if !ok {
+		return fmt.Errorf("invalid store manager: %T, expected %T", &FileManager{}, manager)
+	}
+	tableURL := fileManager.getTableURL(manager, table)
+	exists, err := fileManager.service.Exists(tableURL)
+	if err != nil || exists {
+		return err
+	}
+	return fileManager.PersistTableData(tableURL, []byte{})
+}
+
 //GetTables return tables names for passed in datastore managed by passed in manager.
 func (d fileDialect) GetTables(manager Manager, datastore string) ([]string, error) {
 	fileManager, ok := manager.(*FileManager)
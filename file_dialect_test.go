@@ -31,3 +31,22 @@ func TestFileDialect(t *testing.T) {
 	assert.NotNil(t, err, "Unsupported")
 
 }
+
+func TestFileDialect_CreateTable(t *testing.T) {
+	config := dsc.NewConfig("ndjson", "[url]", "dateFormat:yyyy-MM-dd hh:mm:ss,ext:json,url:test/")
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	dialect := dsc.GetDatastoreDialect("ndjson")
+	_ = dialect.DropTable(manager, "", "created")
+	assert.Nil(t, dialect.CreateTable(manager, "", "created", ""))
+	tables, err := dialect.GetTables(manager, "")
+	assert.Nil(t, err)
+	assert.Contains(t, tables, "created.json")
+	var records = make([]map[string]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT * FROM created", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(records))
+	assert.Nil(t, dialect.DropTable(manager, "", "created"))
+}
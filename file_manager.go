@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/viant/toolbox"
@@ -189,7 +190,7 @@ func (m *FileManager) PersistTableData(tableURL string, data []byte) error {
 	return m.service.Upload(tableURL, bytes.NewReader(data))
 }
 
-func (m *FileManager) modifyRecords(tableURL string, statement *DmlStatement, parameters toolbox.Iterator, onMatchedHandler func(record map[string]interface{}) (bool, error)) (int, error) {
+func (m *FileManager) modifyRecords(ctx context.Context, tableURL string, statement *DmlStatement, parameters toolbox.Iterator, onMatchedHandler func(record map[string]interface{}) (bool, error)) (int, error) {
 	var count = 0
 	buf := new(bytes.Buffer)
 	var err error
@@ -200,7 +201,7 @@ func (m *FileManager) modifyRecords(tableURL string, statement *DmlStatement, pa
 			return 0, fmt.Errorf("failed to read data from %v due to %v", statement.SQL, err)
 		}
 	}
-	err = m.fetchRecords(statement.Table, predicate, func(record map[string]interface{}, matched bool) (bool, error) {
+	err = m.fetchRecords(ctx, statement.Table, predicate, func(record map[string]interface{}, matched bool) (bool, error) {
 
 		if matched {
 			count++
@@ -223,16 +224,19 @@ func (m *FileManager) modifyRecords(tableURL string, statement *DmlStatement, pa
 		}
 		return true, nil
 	})
+	if err != nil {
+		return 0, err
+	}
 	err = m.PersistTableData(tableURL, buf.Bytes())
 	return count, err
 }
 
-func (m *FileManager) updateRecords(tableURL string, statement *DmlStatement, parameters toolbox.Iterator) (int, error) {
+func (m *FileManager) updateRecords(ctx context.Context, tableURL string, statement *DmlStatement, parameters toolbox.Iterator) (int, error) {
 	updatedRecord, err := m.getRecord(statement, parameters)
 	if err != nil {
 		return 0, fmt.Errorf("failed to update table %v, due to %v", statement.Table, err)
 	}
-	return m.modifyRecords(tableURL, statement, parameters, func(record map[string]interface{}) (bool, error) {
+	return m.modifyRecords(ctx, tableURL, statement, parameters, func(record map[string]interface{}) (bool, error) {
 		for k, v := range updatedRecord {
 			record[k] = v
 		}
@@ -240,8 +244,8 @@ func (m *FileManager) updateRecords(tableURL string, statement *DmlStatement, pa
 	})
 }
 
-func (m *FileManager) deleteRecords(tableURL string, statement *DmlStatement, parameters toolbox.Iterator) (int, error) {
-	return m.modifyRecords(tableURL, statement, parameters, func(record map[string]interface{}) (bool, error) {
+func (m *FileManager) deleteRecords(ctx context.Context, tableURL string, statement *DmlStatement, parameters toolbox.Iterator) (int, error) {
+	return m.modifyRecords(ctx, tableURL, statement, parameters, func(record map[string]interface{}) (bool, error) {
 		return false, nil
 	})
 }
@@ -249,7 +253,18 @@ func (m *FileManager) deleteRecords(tableURL string, statement *DmlStatement, pa
 //ExecuteOnConnection executs passed in sql on connection. It takes connection, sql and sql parameters. It returns number of rows affected, or error.
 //This method support basic insert, updated and delete operations.
 func (m *FileManager) ExecuteOnConnection(connection Connection, sql string, sqlParameters []interface{}) (sql.Result, error) {
+	return m.ExecuteOnConnectionContext(context.Background(), connection, sql, sqlParameters)
+}
+
+//ExecuteOnConnectionContext executs passed in sql on connection, it honours context cancellation and deadline. It returns number of rows affected, or error.
+func (m *FileManager) ExecuteOnConnectionContext(ctx context.Context, connection Connection, sql string, sqlParameters []interface{}) (sql.Result, error) {
+	return m.interceptExecute(ctx, connection, sql, sqlParameters, m.executeOnConnection)
+}
 
+func (m *FileManager) executeOnConnection(ctx context.Context, connection Connection, sql string, sqlParameters []interface{}) (sql.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	parser := NewDmlParser()
 	statement, err := parser.Parse(sql)
 	if err != nil {
@@ -267,9 +282,9 @@ func (m *FileManager) ExecuteOnConnection(connection Connection, sql string, sql
 			count = 1
 		}
 	case "UPDATE":
-		count, err = m.updateRecords(tableURL, statement, parameters)
+		count, err = m.updateRecords(ctx, tableURL, statement, parameters)
 	case "DELETE":
-		count, err = m.deleteRecords(tableURL, statement, parameters)
+		count, err = m.deleteRecords(ctx, tableURL, statement, parameters)
 	}
 	if err != nil {
 		return nil, err
@@ -348,7 +363,7 @@ func (m *FileManager) readHeaderIfNeeded(scanner *bufio.Scanner) []string {
 	return headers
 }
 
-func (m *FileManager) fetchRecords(table string, predicate toolbox.Predicate, recordHandler func(record map[string]interface{}, matched bool) (bool, error)) error {
+func (m *FileManager) fetchRecords(ctx context.Context, table string, predicate toolbox.Predicate, recordHandler func(record map[string]interface{}, matched bool) (bool, error)) error {
 	tableURL := m.getTableURL(m, table)
 	reader, err := m.getReaderForURL(tableURL)
 	if reader == nil {
@@ -360,6 +375,9 @@ func (m *FileManager) fetchRecords(table string, predicate toolbox.Predicate, re
 	headers := m.readHeaderIfNeeded(scanner)
 	var recordProvider = m.getRecordProvider(headers...)
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		line := scanner.Text()
 		if line == "" {
 			continue
@@ -391,7 +409,7 @@ func (m *FileManager) fetchRecords(table string, predicate toolbox.Predicate, re
 	return nil
 }
 
-func (m *FileManager) readWithPredicate(connection Connection, statement *QueryStatement, sqlParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error), predicate toolbox.Predicate) error {
+func (m *FileManager) readWithPredicate(ctx context.Context, connection Connection, statement *QueryStatement, sqlParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error), predicate, having toolbox.Predicate) error {
 	var columns = make([]string, 0)
 	var aliases = make([]string, 0)
 	if statement.Columns != nil && len(statement.Columns) > 0 {
@@ -405,16 +423,9 @@ func (m *FileManager) readWithPredicate(connection Connection, statement *QueryS
 		}
 	}
 	fileScanner := NewFileScanner(m.config, columns, nil)
-	err := m.fetchRecords(statement.Table, predicate, func(record map[string]interface{}, matched bool) (bool, error) {
-
-		if !matched {
-			return true, nil
-		}
-
-		fileScanner.columns = aliases
-		if len(columns) == 0 {
-			columns = toolbox.MapKeysToStringSlice(record)
-			fileScanner.columns = columns
+	project := func(record map[string]interface{}) map[string]interface{} {
+		if len(statement.Columns) == 0 {
+			return record
 		}
 		var recordMap = data.Map(record)
 		var mappedRecord = map[string]interface{}{}
@@ -422,38 +433,174 @@ func (m *FileManager) readWithPredicate(connection Connection, statement *QueryS
 			value, _ := recordMap.GetValue(column.Name)
 			mappedRecord[aliases[i]] = value
 		}
-		fileScanner.Values = mappedRecord
+		return mappedRecord
+	}
+	emit := func(record map[string]interface{}) (bool, error) {
+		fileScanner.columns = aliases
+		if len(columns) == 0 {
+			fileScanner.columns = toolbox.MapKeysToStringSlice(record)
+		}
+		fileScanner.Values = record
 		toContinue, err := readingHandler(fileScanner)
 		if err != nil {
 			return false, fmt.Errorf("failed to read data on statement %v, due to\n\t%v", statement.SQL, err)
 		}
-		if !toContinue {
+		return toContinue, nil
+	}
+	var skipped, emitted = 0, 0
+	page := func(record map[string]interface{}) (bool, error) {
+		if statement.HasLimit && statement.Limit <= 0 {
 			return false, nil
 		}
+		if skipped < statement.Offset {
+			skipped++
+			return true, nil
+		}
+		toContinue, err := emit(record)
+		emitted++
+		if statement.HasLimit && emitted >= statement.Limit {
+			return false, err
+		}
+		return toContinue, err
+	}
+
+	if isAggregateQuery(statement) {
+		return m.readAggregated(ctx, statement, aliases, predicate, having, page)
+	}
+	if len(statement.OrderBy) == 0 {
+		return m.fetchRecords(ctx, statement.Table, predicate, func(record map[string]interface{}, matched bool) (bool, error) {
+			if !matched {
+				return true, nil
+			}
+			return page(project(record))
+		})
+	}
+	sorter := m.newRecordSorter(statement)
+	defer sorter.close()
+	orderKeys := m.orderKeysProvider(statement, aliases)
+	err := m.fetchRecords(ctx, statement.Table, predicate, func(record map[string]interface{}, matched bool) (bool, error) {
+		if !matched {
+			return true, nil
+		}
+		projected := project(record)
+		return true, sorter.add(orderKeys(record, projected), projected)
+	})
+	if err != nil {
+		return err
+	}
+	return sorter.iterate(page)
+}
+
+func (m *FileManager) newRecordSorter(statement *QueryStatement) *recordSorter {
+	var window = 0
+	if statement.HasLimit {
+		window = statement.Offset + statement.Limit
+	}
+	return newRecordSorter(statement.OrderBy, window, m.config.GetInt(SortBufferSizeKey, defaultSortBufferSize))
+}
+
+//readAggregated groups matched records, computes aggregates and passes rows matching having predicate to page handler
+func (m *FileManager) readAggregated(ctx context.Context, statement *QueryStatement, aliases []string, predicate, having toolbox.Predicate, page func(record map[string]interface{}) (bool, error)) error {
+	aggregator, err := newFileAggregator(statement, aliases)
+	if err != nil {
+		return fmt.Errorf("failed to read data on statement %v, due to\n\t%v", statement.SQL, err)
+	}
+	err = m.fetchRecords(ctx, statement.Table, predicate, func(record map[string]interface{}, matched bool) (bool, error) {
+		if matched {
+			aggregator.add(record)
+		}
 		return true, nil
 	})
 	if err != nil {
 		return err
 	}
-	return nil
+	var sorter *recordSorter
+	var orderKeys func(record, projected map[string]interface{}) []interface{}
+	if len(statement.OrderBy) > 0 {
+		sorter = m.newRecordSorter(statement)
+		defer sorter.close()
+		orderKeys = m.orderKeysProvider(statement, aliases)
+	}
+	err = aggregator.iterate(func(projected, source map[string]interface{}) (bool, error) {
+		if having != nil && !having.Apply(aggregator.havingRow(statement.Having, projected, source)) {
+			return true, nil
+		}
+		if sorter != nil {
+			return true, sorter.add(orderKeys(source, projected), projected)
+		}
+		return page(projected)
+	})
+	if err != nil || sorter == nil {
+		return err
+	}
+	return sorter.iterate(page)
+}
+
+//orderKeysProvider returns function extracting ORDER BY values, selected columns are matched by alias, name or expression, other columns are read from source record
+func (m *FileManager) orderKeysProvider(statement *QueryStatement, aliases []string) func(record, projected map[string]interface{}) []interface{} {
+	var selected = make([]string, len(statement.OrderBy))
+	for i, orderColumn := range statement.OrderBy {
+		for j, column := range statement.Columns {
+			if column == orderColumn.SQLColumn ||
+				(orderColumn.Name != "" && (orderColumn.Name == aliases[j] || orderColumn.Name == column.Name)) ||
+				(orderColumn.Expression != "" && strings.EqualFold(orderColumn.Expression, column.Expression)) {
+				selected[i] = aliases[j]
+				break
+			}
+		}
+	}
+	return func(record, projected map[string]interface{}) []interface{} {
+		var keys = make([]interface{}, len(statement.OrderBy))
+		var recordMap = data.Map(record)
+		for i, orderColumn := range statement.OrderBy {
+			if selected[i] != "" {
+				keys[i] = projected[selected[i]]
+				continue
+			}
+			if orderColumn.Function != "" {
+				keys[i] = record[aggregateExpression(orderColumn.Function, orderColumn.FunctionArguments)]
+				continue
+			}
+			keys[i], _ = recordMap.GetValue(orderColumn.Name)
+		}
+		return keys
+	}
 }
 
 //ReadAllOnWithHandlerOnConnection reads all records on passed in connection.
 func (m *FileManager) ReadAllOnWithHandlerOnConnection(connection Connection, query string, sqlParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	return m.ReadAllOnWithHandlerOnConnectionContext(context.Background(), connection, query, sqlParameters, readingHandler)
+}
+
+//ReadAllOnWithHandlerOnConnectionContext reads all records on passed in connection, it honours context cancellation and deadline.
+func (m *FileManager) ReadAllOnWithHandlerOnConnectionContext(ctx context.Context, connection Connection, query string, sqlParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	return m.interceptRead(ctx, connection, query, sqlParameters, readingHandler, m.readAllOnWithHandlerOnConnection)
+}
+
+func (m *FileManager) readAllOnWithHandlerOnConnection(ctx context.Context, connection Connection, query string, sqlParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	parser := NewQueryParser()
 	statement, err := parser.Parse(query)
 	if err != nil {
 		return fmt.Errorf("failed to parse statement %v, %v", query, err)
 	}
-	var predicate toolbox.Predicate
+	var predicate, having toolbox.Predicate
+	parameters := toolbox.NewSliceIterator(sqlParameters)
 	if len(statement.Criteria) > 0 {
-		parameters := toolbox.NewSliceIterator(sqlParameters)
 		predicate, err = NewSQLCriteriaPredicate(parameters, statement.SQLCriteria)
 		if err != nil {
 			return fmt.Errorf("failed to read data from %v due to %v", query, err)
 		}
 	}
-	return m.readWithPredicate(connection, statement, sqlParameters, readingHandler, predicate)
+	if statement.Having != nil && len(statement.Having.Criteria) > 0 {
+		having, err = NewSQLCriteriaPredicate(parameters, statement.Having)
+		if err != nil {
+			return fmt.Errorf("failed to read data from %v due to %v", query, err)
+		}
+	}
+	return m.readWithPredicate(ctx, connection, statement, sqlParameters, readingHandler, predicate, having)
 }
 
 //NewFileManager creates a new file manager.
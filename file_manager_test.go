@@ -1,10 +1,13 @@
 package dsc_test
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
 	"github.com/viant/toolbox/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -181,3 +184,167 @@ func TestReadCsv(t *testing.T) {
 		assert.EqualValues(t, "Bob", travelers[0][1])
 	}
 }
+
+func TestFileManager_ReadAllContext(t *testing.T) {
+	config := dsc.NewConfig("ndjson", "[url]", "dateFormat:yyyy-MM-dd hh:mm:ss,ext:json,url:test/")
+	manager, err := dsc.NewManagerFactory().Create(config)
+	assert.Nil(t, err)
+	contextManager, ok := manager.(dsc.ContextManager)
+	if !assert.True(t, ok) {
+		return
+	}
+	{
+		travelers := make([][]interface{}, 0)
+		err := contextManager.ReadAllContext(context.Background(), &travelers, "SELECT id, name FROM travelers1 WHERE id IN(?)", []interface{}{1}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(travelers))
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		travelers := make([][]interface{}, 0)
+		err := contextManager.ReadAllContext(ctx, &travelers, "SELECT id, name FROM travelers1", nil, nil)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, len(travelers))
+	}
+}
+
+func TestFileManager_ReadAllOrderByLimit(t *testing.T) {
+	var useCases = []struct {
+		description string
+		parameters  string
+		SQL         string
+		expect      []interface{}
+	}{
+		{
+			description: "order by",
+			SQL:         "SELECT id, name FROM travelers1 ORDER BY name",
+			expect:      []interface{}{"Bogi", "Dodi", "Rob", "Vodi"},
+		},
+		{
+			description: "order by desc with limit and offset",
+			SQL:         "SELECT id, name FROM travelers1 ORDER BY id DESC LIMIT 2 OFFSET 1",
+			expect:      []interface{}{"Dodi", "Vodi"},
+		},
+		{
+			description: "limit without order",
+			SQL:         "SELECT id, name FROM travelers1 WHERE id > ? LIMIT 2",
+			expect:      []interface{}{"Vodi", "Dodi"},
+		},
+		{
+			description: "zero limit",
+			SQL:         "SELECT id, name FROM travelers1 ORDER BY name LIMIT 0",
+			expect:      []interface{}{},
+		},
+		{
+			description: "order by not selected column",
+			SQL:         "SELECT name FROM travelers1 ORDER BY id DESC",
+			expect:      []interface{}{"Bogi", "Dodi", "Vodi", "Rob"},
+		},
+		{
+			description: "external sort",
+			parameters:  ",sortBufferSize:1",
+			SQL:         "SELECT id, name FROM travelers1 ORDER BY name DESC",
+			expect:      []interface{}{"Vodi", "Rob", "Dodi", "Bogi"},
+		},
+	}
+	for _, useCase := range useCases {
+		config := dsc.NewConfig("ndjson", "[url]", "dateFormat:yyyy-MM-dd hh:mm:ss,ext:json,url:test/"+useCase.parameters)
+		manager, err := dsc.NewManagerFactory().Create(config)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		var records = make([]map[string]interface{}, 0)
+		var parameters []interface{}
+		if strings.Contains(useCase.SQL, "?") {
+			parameters = []interface{}{1}
+		}
+		err = manager.ReadAll(&records, useCase.SQL, parameters, nil)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		var names = make([]interface{}, 0)
+		for _, record := range records {
+			names = append(names, record["name"])
+		}
+		assert.EqualValues(t, useCase.expect, names, useCase.description)
+	}
+}
+
+func TestFileManager_ReadAllAggregate(t *testing.T) {
+	config := dsc.NewConfig("ndjson", "[url]", "dateFormat:yyyy-MM-dd hh:mm:ss,ext:json,url:test/")
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		SQL         string
+		parameters  []interface{}
+		expect      []map[string]interface{}
+		hasError    bool
+	}{
+		{
+			description: "count by group",
+			SQL:         "SELECT status, COUNT(*) AS cnt FROM events GROUP BY status",
+			expect: []map[string]interface{}{
+				{"status": "active", "cnt": 3},
+				{"status": "closed", "cnt": 2},
+				{"status": "pending", "cnt": 1},
+			},
+		},
+		{
+			description: "aggregates without group",
+			SQL:         "SELECT COUNT(DISTINCT user) AS users, COUNT(quantity) AS cnt, SUM(quantity) AS total, MIN(quantity) AS low, MAX(quantity) AS high FROM events",
+			expect: []map[string]interface{}{
+				{"users": 4, "cnt": 5, "total": 15.5, "low": 1, "high": 5.5},
+			},
+		},
+		{
+			description: "having and order by aggregate",
+			SQL:         "SELECT user, SUM(quantity) AS total, AVG(quantity) AS average FROM events WHERE id > ? GROUP BY user HAVING COUNT(*) >= ? ORDER BY SUM(quantity) DESC",
+			parameters:  []interface{}{0, 1},
+			expect: []map[string]interface{}{
+				{"user": "Bob", "total": 6, "average": 2.0},
+				{"user": "Sam", "total": 5.5, "average": 5.5},
+				{"user": "Dan", "total": 4, "average": 4.0},
+				{"user": "Ela", "total": nil, "average": nil},
+			},
+		},
+		{
+			description: "having by alias with limit",
+			SQL:         "SELECT status, COUNT(*) AS cnt FROM events GROUP BY 1 HAVING cnt > 1 ORDER BY 2 DESC LIMIT 1",
+			expect: []map[string]interface{}{
+				{"status": "active", "cnt": 3},
+			},
+		},
+		{
+			description: "not grouped column",
+			SQL:         "SELECT user, COUNT(*) FROM events GROUP BY status",
+			hasError:    true,
+		},
+	}
+	for _, useCase := range useCases {
+		var records = make([]map[string]interface{}, 0)
+		err = manager.ReadAll(&records, useCase.SQL, useCase.parameters, nil)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		if !assert.Equal(t, len(useCase.expect), len(records), useCase.description) {
+			continue
+		}
+		for i, expect := range useCase.expect {
+			for key, value := range expect {
+				if value == nil {
+					assert.Nil(t, records[i][key], useCase.description+" "+key)
+					continue
+				}
+				assert.EqualValues(t, toolbox.AsString(value), toolbox.AsString(records[i][key]), useCase.description+" "+key)
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package dsc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var typedMappers = &sync.Map{}
+
+type typedProviderKey struct {
+	targetType reflect.Type
+	table      string
+}
+
+type reservedSettingsProvider interface {
+	reservedSettings() *Reserved
+}
+
+//typedProviderCacheProvider represents a manager caching dml providers of generic API, cached providers are never modified
+type typedProviderCacheProvider interface {
+	typedProviderCache() *sync.Map
+}
+
+//Query executes query with parameters and returns all fetched rows mapped to T, T can be a struct, a pointer to a struct, a map or a slice.
+func Query[T any](ctx context.Context, manager Manager, query string, parameters ...interface{}) ([]T, error) {
+	mapper, err := typedRecordMapper[T]()
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]T, 0)
+	err = readAllWithHandler(ctx, manager, query, parameters, func(scanner Scanner) (bool, error) {
+		item, err := mapTyped[T](mapper, scanner)
+		if err != nil {
+			return false, fmt.Errorf("failed to map row sql: %v  due to %v", query, err)
+		}
+		result = append(result, item)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+//Get executes query with parameters and returns the first fetched row mapped to T, found flag is false if query returned no rows.
+func Get[T any](ctx context.Context, manager Manager, query string, parameters ...interface{}) (result T, found bool, err error) {
+	mapper, err := typedRecordMapper[T]()
+	if err != nil {
+		return result, false, err
+	}
+	err = readAllWithHandler(ctx, manager, query, parameters, func(scanner Scanner) (bool, error) {
+		item, err := mapTyped[T](mapper, scanner)
+		if err != nil {
+			return false, fmt.Errorf("failed to map record: %v with %T due to %v", query, mapper, err)
+		}
+		result, found = item, true
+		return false, nil
+	})
+	return result, found, err
+}
+
+//Persist persists passed in items into table, T has to be a struct or a pointer to a struct. It returns number of inserted, updated or error.
+//Autoincrement keys are set on the passed in items.
+func Persist[T any](ctx context.Context, manager Manager, table string, items []T) (inserted int, updated int, err error) {
+	provider, err := typedDmlProvider[T](manager, table)
+	if err != nil {
+		return 0, 0, err
+	}
+	if contextManager, ok := manager.(ContextManager); ok {
+		return contextManager.PersistAllContext(ctx, &items, table, provider)
+	}
+	if err = ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	return manager.PersistAll(&items, table, provider)
+}
+
+//Delete deletes passed in items from table, T has to be a struct or a pointer to a struct. It returns number of deleted rows or error.
+func Delete[T any](ctx context.Context, manager Manager, table string, items []T) (int, error) {
+	provider, err := typedDmlProvider[T](manager, table)
+	if err != nil {
+		return 0, err
+	}
+	if contextManager, ok := manager.(ContextManager); ok {
+		return contextManager.DeleteAllContext(ctx, &items, table, provider)
+	}
+	if err = ctx.Err(); err != nil {
+		return 0, err
+	}
+	return manager.DeleteAll(&items, table, provider)
+}
+
+func readAllWithHandler(ctx context.Context, manager Manager, query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	if contextManager, ok := manager.(ContextManager); ok {
+		return contextManager.ReadAllWithHandlerContext(ctx, query, parameters, readingHandler)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return manager.ReadAllWithHandler(query, parameters, readingHandler)
+}
+
+func mapTyped[T any](mapper RecordMapper, scanner Scanner) (result T, err error) {
+	mapped, err := mapper.Map(scanner)
+	if err != nil || mapped == nil {
+		return result, err
+	}
+	if typed, ok := mapped.(T); ok {
+		return typed, nil
+	}
+	mappedValue := reflect.ValueOf(mapped)
+	if mappedValue.Kind() == reflect.Ptr {
+		if typed, ok := mappedValue.Elem().Interface().(T); ok {
+			return typed, nil
+		}
+	}
+	return result, fmt.Errorf("unable to map %T to %T", mapped, result)
+}
+
+//typedRecordMapper returns cached record mapper for T
+func typedRecordMapper[T any]() (RecordMapper, error) {
+	targetType := reflect.TypeOf((*T)(nil)).Elem()
+	if mapper, ok := typedMappers.Load(targetType); ok {
+		return mapper.(RecordMapper), nil
+	}
+	switch targetType.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice:
+	case reflect.Ptr:
+		if kind := targetType.Elem().Kind(); kind != reflect.Struct && kind != reflect.Slice {
+			return nil, fmt.Errorf("unsupported type: %v", targetType)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type: %v", targetType)
+	}
+	mapper, _ := typedMappers.LoadOrStore(targetType, NewRecordMapper(targetType))
+	return mapper.(RecordMapper), nil
+}
+
+//typedDmlProvider returns dml provider for T and table cached by manager, or a new provider if manager does not cache providers
+func typedDmlProvider[T any](manager Manager, table string) (DmlProvider, error) {
+	targetType := reflect.TypeOf((*T)(nil)).Elem()
+	structType := targetType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported type: %v, expected struct or struct pointer", targetType)
+	}
+	var cache *sync.Map
+	if cacheProvider, ok := manager.(typedProviderCacheProvider); ok {
+		cache = cacheProvider.typedProviderCache()
+	}
+	key := typedProviderKey{targetType: targetType, table: table}
+	if cache != nil {
+		if provider, ok := cache.Load(key); ok {
+			return provider.(DmlProvider), nil
+		}
+	}
+	provider, err := newMetaDmlProvider(table, targetType)
+	if err != nil {
+		return nil, err
+	}
+	if settingsProvider, ok := manager.(reservedSettingsProvider); ok {
+		provider = provider.(*metaDmlProvider).withReserved(settingsProvider.reservedSettings())
+	}
+	if cache == nil {
+		return provider, nil
+	}
+	result, _ := cache.LoadOrStore(key, provider)
+	return result.(DmlProvider), nil
+}
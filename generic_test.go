@@ -0,0 +1,108 @@
+package dsc_test
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"testing"
+)
+
+func TestGenericHelpers(t *testing.T) {
+	manager := GetManager(t)
+	ctx := context.Background()
+	var query = "SELECT id, username, active, salary, comments, last_access_time FROM users"
+
+	users := []User{
+		{
+			Id:       1,
+			Username: "Sir Edi",
+			Salary:   32432.3,
+		},
+		{
+			Username: "Bogi",
+			Active:   true,
+			Salary:   32432.3,
+		},
+	}
+	inserted, updated, err := dsc.Persist(ctx, manager, "users", users)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, inserted)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, 2, users[1].Id, "autoicrement value should be set")
+
+	{
+		result, err := dsc.Query[User](ctx, manager, query+" ORDER BY id")
+		assert.Nil(t, err)
+		if assert.Equal(t, 2, len(result)) {
+			assert.Equal(t, "Sir Edi", result[0].Username)
+			assert.Equal(t, "Bogi", result[1].Username)
+		}
+	}
+	{
+		result, err := dsc.Query[*User](ctx, manager, query+" WHERE id = ?", 2)
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(result)) {
+			assert.Equal(t, "Bogi", result[0].Username)
+		}
+	}
+	{
+		result, found, err := dsc.Get[User](ctx, manager, query+" WHERE id = ?", 2)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "Bogi", result.Username)
+
+		_, found, err = dsc.Get[*User](ctx, manager, query+" WHERE id = ?", 20)
+		assert.Nil(t, err)
+		assert.False(t, found)
+	}
+	{
+		result, found, err := dsc.Get[map[string]interface{}](ctx, manager, "SELECT username FROM users WHERE id = ?", 1)
+		assert.Nil(t, err)
+		assert.True(t, found)
+		assert.EqualValues(t, "Sir Edi", result["username"])
+	}
+
+	deleted, err := dsc.Delete(ctx, manager, "users", users[:1])
+	assert.Nil(t, err)
+	assert.Equal(t, 1, deleted)
+
+	{
+		_, err := dsc.Query[int](ctx, manager, query)
+		assert.NotNil(t, err)
+
+		_, _, err = dsc.Persist(ctx, manager, "users", []string{"abc"})
+		assert.NotNil(t, err)
+	}
+}
+
+func TestPersist_Concurrent(t *testing.T) {
+	type Account struct {
+		Id   int `primaryKey:"true"`
+		Name string
+	}
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/generic.db,upsert:true"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS generic_accounts",
+		"CREATE TABLE generic_accounts (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	var errors = make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func(id int) {
+			_, _, err := dsc.Persist(context.Background(), manager, "generic_accounts", []*Account{{Id: id, Name: "a"}})
+			errors <- err
+		}(i + 1)
+	}
+	for i := 0; i < 4; i++ {
+		assert.Nil(t, <-errors)
+	}
+	accounts, err := dsc.Query[Account](context.Background(), manager, "SELECT id, name FROM generic_accounts")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(accounts))
+}
@@ -0,0 +1,183 @@
+package dsc
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+const (
+	//OperationExecute represents sql execution operation kind
+	OperationExecute = "execute"
+	//OperationRead represents query operation kind
+	OperationRead = "read"
+	//OperationPersist represents insert or update operation kind executed by PersistAll
+	OperationPersist = "persist"
+	//OperationBatch represents batched insert flush operation kind
+	OperationBatch = "batch"
+	//OperationDelete represents delete operation kind executed by DeleteAll
+	OperationDelete = "delete"
+)
+
+//Operation represents an intercepted manager operation
+type Operation struct {
+	Context    context.Context
+	Kind       string
+	Table      string
+	SQL        string
+	Args       []interface{}
+	Manager    Manager
+	Connection Connection
+	Result     sql.Result //execution result, set for non read operation
+	Rows       int64      //number of affected or fetched rows
+}
+
+//OperationHandler represents operation handler
+type OperationHandler func(operation *Operation) error
+
+//Interceptor represents an operation interceptor, it can inspect or modify operation SQL and arguments,
+//call next to continue the chain, or short-circuit it by not calling next (setting operation result if needed).
+type Interceptor interface {
+	Intercept(operation *Operation, next OperationHandler) error
+}
+
+//InterceptorFunc represents an interceptor function adapter
+type InterceptorFunc func(operation *Operation, next OperationHandler) error
+
+//Intercept calls interceptor function
+func (f InterceptorFunc) Intercept(operation *Operation, next OperationHandler) error {
+	return f(operation, next)
+}
+
+type operationHintKey struct{}
+
+//operationHint carries operation kind and table down to execution on the same context
+type operationHint struct {
+	kind  string
+	table string
+}
+
+func withOperationHint(ctx context.Context, kind, table string) context.Context {
+	return context.WithValue(ctx, operationHintKey{}, &operationHint{kind: kind, table: table})
+}
+
+func getOperationHint(ctx context.Context) *operationHint {
+	if ctx == nil {
+		return nil
+	}
+	hint, _ := ctx.Value(operationHintKey{}).(*operationHint)
+	return hint
+}
+
+//discoverTable returns table name for passed in SQL, or empty string
+func discoverTable(SQL string) string {
+	fields := strings.Fields(SQL)
+	for i := 0; i+1 < len(fields); i++ {
+		switch strings.ToUpper(fields[i]) {
+		case "FROM", "INTO", "UPDATE", "TABLE":
+			table := fields[i+1]
+			if index := strings.IndexAny(table, "(,;"); index != -1 {
+				table = table[:index]
+			}
+			return strings.Trim(table, "`\"[]")
+		}
+	}
+	return ""
+}
+
+//Interceptors returns config and manager interceptors
+func (m *AbstractManager) Interceptors() []Interceptor {
+	if len(m.interceptors) == 0 {
+		return m.config.Interceptors
+	}
+	if len(m.config.Interceptors) == 0 {
+		return m.interceptors
+	}
+	var result = make([]Interceptor, 0, len(m.config.Interceptors)+len(m.interceptors))
+	result = append(result, m.config.Interceptors...)
+	return append(result, m.interceptors...)
+}
+
+//AddInterceptor adds manager interceptors, interceptors run after the ones defined on Config, it should be called before manager is used.
+func (m *AbstractManager) AddInterceptor(interceptors ...Interceptor) {
+	m.interceptors = append(m.interceptors, interceptors...)
+}
+
+//operationInterceptors returns interceptors followed by metrics and logging interceptor, so that they record SQL that is actually executed
+func (m *AbstractManager) operationInterceptors(ctx context.Context) []Interceptor {
+	interceptors := m.Interceptors()
+	var result = make([]Interceptor, 0, len(interceptors)+2)
+	result = append(result, interceptors...)
+	if m.metrics != nil {
+		result = append(result, m.metrics)
+	}
+	if logging := m.loggingInterceptor(ctx); logging != nil {
+		result = append(result, logging)
+	}
+	return result
+}
+
+func (m *AbstractManager) newOperation(ctx context.Context, kind string, connection Connection, SQL string, args []interface{}) *Operation {
+	operation := &Operation{Context: ctx, Kind: kind, SQL: SQL, Args: args, Manager: m.Manager, Connection: connection}
+	if hint := getOperationHint(ctx); hint != nil {
+		operation.Table = hint.table
+		if hint.kind != "" {
+			operation.Kind = hint.kind
+		}
+	}
+	if operation.Table == "" {
+		operation.Table = discoverTable(SQL)
+	}
+	return operation
+}
+
+//interceptExecute runs execution through interceptor chain
+func (m *AbstractManager) interceptExecute(ctx context.Context, connection Connection, SQL string, args []interface{}, execute func(ctx context.Context, connection Connection, SQL string, args []interface{}) (sql.Result, error)) (sql.Result, error) {
+	defer m.invalidateQueryCache(ctx, connection, SQL)
+	interceptors := m.operationInterceptors(ctx)
+	if len(interceptors) == 0 {
+		return execute(ctx, connection, SQL, args)
+	}
+	operation := m.newOperation(ctx, OperationExecute, connection, SQL, args)
+	err := chainInterceptors(interceptors, func(operation *Operation) (err error) {
+		operation.Result, err = execute(operation.Context, operation.Connection, operation.SQL, operation.Args)
+		if err == nil && operation.Result != nil {
+			operation.Rows, _ = operation.Result.RowsAffected()
+		}
+		return err
+	})(operation)
+	if err != nil {
+		return nil, err
+	}
+	if operation.Result == nil { //short-circuited without result
+		return NewSQLResult(operation.Rows, 0), nil
+	}
+	return operation.Result, nil
+}
+
+//interceptRead runs query through interceptor chain
+func (m *AbstractManager) interceptRead(ctx context.Context, connection Connection, query string, args []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error), read func(ctx context.Context, connection Connection, query string, args []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error) error {
+	interceptors := m.operationInterceptors(ctx)
+	if len(interceptors) == 0 {
+		return read(ctx, connection, query, args, readingHandler)
+	}
+	operation := m.newOperation(ctx, OperationRead, connection, query, args)
+	return chainInterceptors(interceptors, func(operation *Operation) error {
+		return read(operation.Context, operation.Connection, operation.SQL, operation.Args, func(scanner Scanner) (bool, error) {
+			operation.Rows++
+			return readingHandler(scanner)
+		})
+	})(operation)
+}
+
+//chainInterceptors returns handler running passed in interceptors in order, ending with handler
+func chainInterceptors(interceptors []Interceptor, handler OperationHandler) OperationHandler {
+	var next = handler
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, nextHandler := interceptors[i], next
+		next = func(operation *Operation) error {
+			return interceptor.Intercept(operation, nextHandler)
+		}
+	}
+	return next
+}
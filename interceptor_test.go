@@ -0,0 +1,79 @@
+package dsc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestInterceptor(t *testing.T) {
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db")
+	var operations = make([]dsc.Operation, 0)
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			err := next(operation)
+			operations = append(operations, *operation)
+			return err
+		}),
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			if operation.SQL == "SELECT 'skip'" {
+				return nil
+			}
+			if operation.SQL == "SELECT id, username FROM users_view" {
+				operation.SQL = "SELECT id, username FROM users"
+			}
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS users",
+		"CREATE TABLE users (id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, username varchar(255) DEFAULT NULL, active tinyint(1) DEFAULT '1', salary decimal(7,2) DEFAULT NULL, comments text, last_access_time timestamp DEFAULT CURRENT_TIMESTAMP)",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, "execute", operations[1].Kind)
+	assert.Equal(t, "users", operations[1].Table)
+
+	operations = operations[:0]
+	users := []User{{Username: "Bob"}, {Username: "Sam"}}
+	inserted, _, err := manager.PersistAll(&users, "users", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, inserted)
+	if assert.Equal(t, 3, len(operations)) {
+		assert.Equal(t, dsc.OperationRead, operations[0].Kind)
+		assert.Equal(t, dsc.OperationPersist, operations[1].Kind)
+		assert.Equal(t, "users", operations[1].Table)
+		assert.EqualValues(t, 1, operations[1].Rows)
+	}
+
+	operations = operations[:0]
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id, username FROM users_view", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(records))
+	if assert.Equal(t, 1, len(operations)) {
+		assert.Equal(t, dsc.OperationRead, operations[0].Kind)
+		assert.Equal(t, "SELECT id, username FROM users", operations[0].SQL)
+		assert.EqualValues(t, 2, operations[0].Rows)
+	}
+
+	result, err := manager.Execute("SELECT 'skip'")
+	assert.Nil(t, err)
+	affected, _ := result.RowsAffected()
+	assert.EqualValues(t, 0, affected)
+
+	operations = operations[:0]
+	deleted, err := manager.DeleteAll(&users, "users", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	if assert.Equal(t, 2, len(operations)) {
+		assert.Equal(t, dsc.OperationDelete, operations[0].Kind)
+		assert.Equal(t, "users", operations[0].Table)
+	}
+}
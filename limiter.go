@@ -1,6 +1,7 @@
 package dsc
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,20 +19,49 @@ type Limiter struct {
 	duration time.Duration
 	win      *timeWindow
 	mux      *sync.Mutex
+	waits    int64
+	waitTime int64
+}
+
+//Stats returns number of throttled acquires and total time spent waiting
+func (l *Limiter) Stats() (waits int64, waitTime time.Duration) {
+	return atomic.LoadInt64(&l.waits), time.Duration(atomic.LoadInt64(&l.waitTime))
 }
 
 //Acquire checks if limit for current time window was not exhausted or sleep
 func (l *Limiter) Acquire() {
+	_ = l.AcquireContext(context.Background())
+}
 
+//AcquireContext checks if limit for current time window was not exhausted or sleep, it returns context error if context is done while waiting
+func (l *Limiter) AcquireContext(ctx context.Context) error {
+	var waited = false
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		window := l.window()
 		if int(atomic.AddInt64(&l.count, 1)) <= l.max {
-			return
+			return nil
 		}
 		duration := window.End.Sub(time.Now())
-		if duration > 0 {
-			time.Sleep(duration)
+		if duration <= 0 {
+			continue
+		}
+		if !waited {
+			waited = true
+			atomic.AddInt64(&l.waits, 1)
+		}
+		startTime := time.Now()
+		timer := time.NewTimer(duration)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			atomic.AddInt64(&l.waitTime, int64(time.Now().Sub(startTime)))
+			return ctx.Err()
+		case <-timer.C:
 		}
+		atomic.AddInt64(&l.waitTime, int64(time.Now().Sub(startTime)))
 	}
 }
 
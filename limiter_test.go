@@ -1,6 +1,7 @@
 package dsc
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"sync"
 	"testing"
@@ -25,3 +26,18 @@ func TestLimiter_Acquire(t *testing.T) {
 	elapsed := time.Now().Sub(startTime)
 	assert.True(t, elapsed >= time.Second)
 }
+
+func TestLimiter_AcquireContext(t *testing.T) {
+	limiter := NewLimiter(time.Second, 1)
+	assert.Nil(t, limiter.AcquireContext(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	startTime := time.Now()
+	err := limiter.AcquireContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Now().Sub(startTime) < time.Second)
+	waits, waitTime := limiter.Stats()
+	assert.EqualValues(t, 1, waits)
+	assert.True(t, waitTime > 0)
+}
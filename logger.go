@@ -1,11 +1,32 @@
 package dsc
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	//LogSlowQueryMsKey represents a config parameter for slow operation threshold, when set only operations exceeding it are logged with timings
+	LogSlowQueryMsKey = "logSlowQueryMs"
+	//LogArgsKey represents a config parameter enabling statement arguments logging, arguments are redacted by default
+	LogArgsKey = "logArgs"
+	//LogRedactColumnsKey represents a config parameter with comma separated columns which arguments are redacted when LogArgsKey is enabled
+	LogRedactColumnsKey = "logRedactColumns"
+
+	redactedValue = "***"
+)
 
 //Log represent log function
 type Log func(format string, args ...interface{})
 
-//Logf - function to log debug info
+//Logf - function to log debug info, it is used by default Logger when neither Config.Logger nor manager logger is set
+//
+//Deprecated: use Config.Logger or AbstractManager.SetLogger instead
 var Logf Log = VoidLogger
 
 //VoidLogger represent logger that do not log
@@ -17,3 +38,205 @@ func VoidLogger(format string, args ...interface{}) {
 func StdoutLogger(format string, args ...interface{}) {
 	fmt.Print(fmt.Sprintf(format, args...) + "\n")
 }
+
+//Logger represents structured levelled logger, *slog.Logger implements this interface
+type Logger interface {
+	//Enabled returns true if logger handles records at passed in level
+	Enabled(ctx context.Context, level slog.Level) bool
+
+	//Log logs message with alternating key, value attributes
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+//Redactor redacts statement arguments before they are logged
+type Redactor func(SQL string, args []interface{}) []interface{}
+
+//RedactAll redacts all statement arguments
+func RedactAll(SQL string, args []interface{}) []interface{} {
+	var result = make([]interface{}, len(args))
+	for i := range result {
+		result[i] = redactedValue
+	}
+	return result
+}
+
+//NoRedaction returns statement arguments as is
+func NoRedaction(SQL string, args []interface{}) []interface{} {
+	return args
+}
+
+//RedactColumns returns redactor that redacts arguments bound to passed in columns, arguments which column can not be matched are kept
+func RedactColumns(columns ...string) Redactor {
+	var redacted = make(map[string]bool)
+	for _, column := range columns {
+		if column = strings.TrimSpace(column); column != "" {
+			redacted[strings.ToLower(column)] = true
+		}
+	}
+	return func(SQL string, args []interface{}) []interface{} {
+		placeholderColumns := discoverPlaceholderColumns(SQL)
+		var result = make([]interface{}, len(args))
+		for i, arg := range args {
+			result[i] = arg
+			if i < len(placeholderColumns) && redacted[placeholderColumns[i]] {
+				result[i] = redactedValue
+			}
+		}
+		return result
+	}
+}
+
+var insertColumnsExpr = regexp.MustCompile(`(?is)INSERT\s+INTO\s+[^\s(]+\s*\(([^)]*)\)\s*VALUES`)
+var predicateColumnExpr = regexp.MustCompile(`(?is)([\w."` + "`" + `\[\]]+)\s*(?:=|<>|!=|<=|>=|<|>|\s+LIKE|\s+IN\s*\((?:\s*\?\s*,)*)\s*$`)
+
+//discoverPlaceholderColumns returns lower case column name for each SQL placeholder, or empty string if column can not be matched
+func discoverPlaceholderColumns(SQL string) []string {
+	var result = make([]string, 0)
+	var insertColumns []string
+	var valuesIndex = -1
+	if match := insertColumnsExpr.FindStringSubmatchIndex(SQL); match != nil {
+		for _, column := range strings.Split(SQL[match[2]:match[3]], ",") {
+			insertColumns = append(insertColumns, normalizeLogColumn(column))
+		}
+		valuesIndex = match[1]
+	}
+	var inQuote = false
+	var insertIndex = 0
+	for i := 0; i < len(SQL); i++ {
+		switch SQL[i] {
+		case '\'':
+			inQuote = !inQuote
+		case '?':
+			if inQuote {
+				continue
+			}
+			if valuesIndex != -1 && i > valuesIndex && len(insertColumns) > 0 {
+				result = append(result, insertColumns[insertIndex%len(insertColumns)])
+				insertIndex++
+				continue
+			}
+			column := ""
+			if match := predicateColumnExpr.FindStringSubmatch(SQL[:i]); match != nil {
+				column = normalizeLogColumn(match[1])
+			}
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+func normalizeLogColumn(column string) string {
+	column = strings.Trim(strings.TrimSpace(column), "`\"[]")
+	if index := strings.LastIndex(column, "."); index != -1 {
+		column = strings.Trim(column[index+1:], "`\"[]")
+	}
+	return strings.ToLower(column)
+}
+
+//funcLogger adapts legacy Logf function to Logger
+type funcLogger struct{}
+
+//Enabled returns false if Logf is VoidLogger
+func (l funcLogger) Enabled(ctx context.Context, level slog.Level) bool {
+	return Logf != nil && reflect.ValueOf(Logf).Pointer() != reflect.ValueOf(VoidLogger).Pointer()
+}
+
+//Log logs message with attributes with Logf
+func (l funcLogger) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	if Logf == nil {
+		return
+	}
+	var builder = strings.Builder{}
+	builder.WriteString(level.String() + " " + msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		builder.WriteString(fmt.Sprintf(" %v=%v", args[i], args[i+1]))
+	}
+	Logf("%v", builder.String())
+}
+
+//logger returns config logger or legacy Logf adapter
+func (c *Config) logger() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return funcLogger{}
+}
+
+//redactor returns config arguments redactor
+func (c *Config) redactor() Redactor {
+	if c.Redactor != nil {
+		return c.Redactor
+	}
+	c.initLock()
+	if !c.GetBoolean(LogArgsKey, false) {
+		return RedactAll
+	}
+	if columns := c.GetString(LogRedactColumnsKey, ""); columns != "" {
+		return RedactColumns(strings.Split(columns, ",")...)
+	}
+	return NoRedaction
+}
+
+//Logger returns manager logger
+func (m *AbstractManager) Logger() Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	return m.config.logger()
+}
+
+//SetLogger sets manager logger, it takes precedence over Config.Logger
+func (m *AbstractManager) SetLogger(logger Logger) {
+	m.logger = logger
+}
+
+//loggingInterceptor logs operation SQL at debug level and operation timing at info level, or at warn level when slow query threshold is set and exceeded
+type loggingInterceptor struct {
+	logger        Logger
+	driver        string
+	redactor      Redactor
+	slowThreshold time.Duration
+}
+
+//Intercept logs operation
+func (i *loggingInterceptor) Intercept(operation *Operation, next OperationHandler) error {
+	ctx := operation.Context
+	if i.logger.Enabled(ctx, slog.LevelDebug) {
+		i.logger.Log(ctx, slog.LevelDebug, "dsc: sql", "driver", i.driver, "kind", operation.Kind, "table", operation.Table, "sql", operation.SQL, "args", i.redactor(operation.SQL, operation.Args))
+	}
+	startTime := time.Now()
+	err := next(operation)
+	elapsed := time.Now().Sub(startTime)
+	if err != nil {
+		if i.logger.Enabled(ctx, slog.LevelError) {
+			i.logger.Log(ctx, slog.LevelError, "dsc: operation failed", "driver", i.driver, "kind", operation.Kind, "table", operation.Table, "duration", elapsed, "error", err)
+		}
+		return err
+	}
+	level, msg := slog.LevelInfo, "dsc: operation"
+	if i.slowThreshold > 0 {
+		if elapsed < i.slowThreshold {
+			return nil
+		}
+		level, msg = slog.LevelWarn, "dsc: slow operation"
+	}
+	if i.logger.Enabled(ctx, level) {
+		i.logger.Log(ctx, level, msg, "driver", i.driver, "kind", operation.Kind, "table", operation.Table, "duration", elapsed, "rows", operation.Rows)
+	}
+	return nil
+}
+
+//loggingInterceptor returns logging interceptor or nil if logging is disabled
+func (m *AbstractManager) loggingInterceptor(ctx context.Context) Interceptor {
+	logger := m.Logger()
+	if logger == nil || !logger.Enabled(ctx, slog.LevelError) {
+		return nil
+	}
+	m.config.initLock()
+	return &loggingInterceptor{
+		logger:        logger,
+		driver:        m.config.DriverName,
+		redactor:      m.config.redactor(),
+		slowThreshold: m.config.GetDuration(LogSlowQueryMsKey, time.Millisecond, 0),
+	}
+}
@@ -0,0 +1,95 @@
+package dsc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestRedactColumns(t *testing.T) {
+	var useCases = []struct {
+		description string
+		SQL         string
+		args        []interface{}
+		expect      []interface{}
+	}{
+		{
+			description: "insert",
+			SQL:         "INSERT INTO users(id, username, password) VALUES(?, ?, ?)",
+			args:        []interface{}{1, "Bob", "secret"},
+			expect:      []interface{}{1, "Bob", "***"},
+		},
+		{
+			description: "update",
+			SQL:         "UPDATE users SET password = ?, username=? WHERE id = ?",
+			args:        []interface{}{"secret", "Bob", 1},
+			expect:      []interface{}{"***", "Bob", 1},
+		},
+		{
+			description: "in predicate",
+			SQL:         "SELECT id FROM users u WHERE u.Password IN (?, ?) AND id > ?",
+			args:        []interface{}{"s1", "s2", 1},
+			expect:      []interface{}{"***", "***", 1},
+		},
+	}
+	redactor := dsc.RedactColumns("password")
+	for _, useCase := range useCases {
+		assert.EqualValues(t, useCase.expect, redactor(useCase.SQL, useCase.args), useCase.description)
+	}
+	assert.EqualValues(t, []interface{}{"***", "***"}, dsc.RedactAll("", []interface{}{1, 2}))
+}
+
+func TestLogger(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db")
+	config.Logger = slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var records = func() []map[string]interface{} {
+		var result = make([]map[string]interface{}, 0)
+		for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			record := map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal([]byte(line), &record))
+			result = append(result, record)
+		}
+		buffer.Reset()
+		return result
+	}
+	_, err = manager.Execute("DROP TABLE IF EXISTS users")
+	assert.Nil(t, err)
+	_, err = manager.Execute("CREATE TABLE users (id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, username varchar(255) DEFAULT NULL)")
+	assert.Nil(t, err)
+	records()
+
+	_, err = manager.Execute("INSERT INTO users(username) VALUES(?)", "Bob")
+	assert.Nil(t, err)
+	logged := records()
+	if assert.Equal(t, 2, len(logged)) {
+		assert.Equal(t, "DEBUG", logged[0]["level"])
+		assert.Equal(t, "INSERT INTO users(username) VALUES(?)", logged[0]["sql"])
+		assert.EqualValues(t, []interface{}{"***"}, logged[0]["args"])
+		assert.Equal(t, "INFO", logged[1]["level"])
+		assert.Equal(t, "sqlite3", logged[1]["driver"])
+		assert.Equal(t, "users", logged[1]["table"])
+		assert.EqualValues(t, 1, logged[1]["rows"])
+	}
+
+	config.Parameters[dsc.LogSlowQueryMsKey] = 60000
+	var users = make([][]interface{}, 0)
+	err = manager.ReadAll(&users, "SELECT id, username FROM users", nil, nil)
+	assert.Nil(t, err)
+	logged = records()
+	if assert.Equal(t, 1, len(logged)) {
+		assert.Equal(t, "DEBUG", logged[0]["level"])
+	}
+}
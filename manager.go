@@ -1,11 +1,13 @@
 package dsc
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/viant/toolbox"
@@ -16,6 +18,7 @@ var defaultBatchSize = 512
 var BulkInsertAllType = "insertAll"
 var UnionSelectInsert = "unionSelectInsert"
 var CopyLocalInsert = "copyLocalInsert"
+var CopyFromStdinInsert = "copyFromStdin"
 
 // AbstractManager represent general abstraction for datastore implementation.
 // Note that ExecuteOnConnection,  ReadAllOnWithHandlerOnConnection may need to be implemented for particular datastore.
@@ -26,6 +29,12 @@ type AbstractManager struct {
 	tableDescriptorRegistry TableDescriptorRegistry
 	limiter                 *Limiter
 	reserved                *Reserved
+	interceptors            []Interceptor
+	logger                  Logger
+	metrics                 *Metrics
+	retry                   *RetryPolicy
+	queryCache              *queryCache
+	typedProviders          *sync.Map
 }
 
 // Config returns a config.
@@ -49,6 +58,28 @@ func (m *AbstractManager) Execute(sql string, sqlParameters ...interface{}) (res
 	return m.Manager.ExecuteOnConnection(connection, sql, sqlParameters)
 }
 
+// ExecuteContext executes passed in sql with parameters, it honours context cancellation and deadline.  It returns sql result, or an error.
+func (m *AbstractManager) ExecuteContext(ctx context.Context, sql string, sqlParameters ...interface{}) (result sql.Result, err error) {
+	var connection Connection
+	connection, err = m.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+	return m.contextManager().ExecuteOnConnectionContext(ctx, connection, sql, sqlParameters)
+}
+
+// ExecuteOnConnectionContext executes passed in sql with parameters on connection, this implementation checks context before delegating to ExecuteOnConnection,
+// datastore specific manager should override it to propagate context to the driver.
+func (m *AbstractManager) ExecuteOnConnectionContext(ctx context.Context, connection Connection, SQL string, sqlParameters []interface{}) (sql.Result, error) {
+	return m.interceptExecute(ctx, connection, SQL, sqlParameters, func(ctx context.Context, connection Connection, SQL string, sqlParameters []interface{}) (sql.Result, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return m.Manager.ExecuteOnConnection(connection, SQL, sqlParameters)
+	})
+}
+
 // ExecuteAll passed in SQL. It returns sql result, or an error.
 func (m *AbstractManager) ExecuteAll(sqls []string) ([]sql.Result, error) {
 	connection, err := m.Manager.ConnectionProvider().Get()
@@ -59,17 +90,36 @@ func (m *AbstractManager) ExecuteAll(sqls []string) ([]sql.Result, error) {
 	return m.Manager.ExecuteAllOnConnection(connection, sqls)
 }
 
+// ExecuteAllContext passed in SQL, it honours context cancellation and deadline. It returns sql result, or an error.
+func (m *AbstractManager) ExecuteAllContext(ctx context.Context, sqls []string) ([]sql.Result, error) {
+	connection, err := m.getConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+	return m.contextManager().ExecuteAllOnConnectionContext(ctx, connection, sqls)
+}
+
 // Acquire if max request per second is specified this function will throttle any request exceeding specified max
 func (m *AbstractManager) Acquire() {
+	_ = m.AcquireContext(context.Background())
+}
+
+// AcquireContext if max request per second is specified this function will throttle any request exceeding specified max, it returns error if context is done while waiting.
+func (m *AbstractManager) AcquireContext(ctx context.Context) error {
 	if m.config.MaxRequestPerSecond == 0 {
-		return
+		return ctx.Err()
 	}
-
-	m.limiter.Acquire()
+	return m.limiter.AcquireContext(ctx)
 }
 
 // ExecuteAllOnConnection executes passed in SQL on connection. It returns sql result, or an error.
 func (m *AbstractManager) ExecuteAllOnConnection(connection Connection, sqls []string) ([]sql.Result, error) {
+	return m.ExecuteAllOnConnectionContext(context.Background(), connection, sqls)
+}
+
+// ExecuteAllOnConnectionContext executes passed in SQL on connection, it honours context cancellation and deadline. It returns sql result, or an error.
+func (m *AbstractManager) ExecuteAllOnConnectionContext(ctx context.Context, connection Connection, sqls []string) ([]sql.Result, error) {
 	var result = make([]sql.Result, len(sqls))
 
 	err := connection.Begin()
@@ -86,7 +136,7 @@ func (m *AbstractManager) ExecuteAllOnConnection(connection Connection, sqls []s
 	}()
 	for i, sql := range sqls {
 		var err error
-		result[i], err = m.Manager.ExecuteOnConnection(connection, sql, nil)
+		result[i], err = m.contextManager().ExecuteOnConnectionContext(ctx, connection, sql, nil)
 		if err != nil {
 			return result, err
 		}
@@ -104,25 +154,69 @@ func (m *AbstractManager) ReadAllWithHandler(query string, queryParameters []int
 	return m.Manager.ReadAllOnWithHandlerOnConnection(connection, query, queryParameters, readingHandler)
 }
 
-// ReadAll executes query with parameters and fetches all table rows. The row is mapped to result slice pointer with record mapper.
-func (m AbstractManager) ReadAll(resultSlicePointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) error {
-	connection, err := m.Manager.ConnectionProvider().Get()
+// ReadAllWithHandlerContext executes query with parameters and for each fetch row call reading handler with a scanner, it honours context cancellation and deadline.
+func (m *AbstractManager) ReadAllWithHandlerContext(ctx context.Context, query string, queryParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	connection, err := m.getConnection(ctx)
 	if err != nil {
 		return err
 	}
 	defer connection.Close()
+	return m.contextManager().ReadAllOnWithHandlerOnConnectionContext(ctx, connection, query, queryParameters, readingHandler)
+}
+
+// ReadAllOnWithHandlerOnConnectionContext executes query with parameters on connection, this implementation checks context before each fetched row and delegates to ReadAllOnWithHandlerOnConnection,
+// datastore specific manager should override it to propagate context to the driver.
+func (m *AbstractManager) ReadAllOnWithHandlerOnConnectionContext(ctx context.Context, connection Connection, query string, queryParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	return m.interceptRead(ctx, connection, query, queryParameters, readingHandler, func(ctx context.Context, connection Connection, query string, queryParameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return m.Manager.ReadAllOnWithHandlerOnConnection(connection, query, queryParameters, func(scanner Scanner) (bool, error) {
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+			return readingHandler(scanner)
+		})
+	})
+}
+
+// ReadAll executes query with parameters and fetches all table rows. The row is mapped to result slice pointer with record mapper.
+func (m AbstractManager) ReadAll(resultSlicePointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) error {
+	return m.readAllCached(context.Background(), resultSlicePointer, query, queryParameters, mapper, func(resultSlicePointer interface{}) error {
+		connection, err := m.Manager.ConnectionProvider().Get()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+		return m.Manager.ReadAllOnConnection(connection, resultSlicePointer, query, queryParameters, mapper)
+	})
+}
 
-	return m.Manager.ReadAllOnConnection(connection, resultSlicePointer, query, queryParameters, mapper)
+// ReadAllContext executes query with parameters and fetches all table rows, it honours context cancellation and deadline. The row is mapped to result slice pointer with record mapper.
+func (m *AbstractManager) ReadAllContext(ctx context.Context, resultSlicePointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) error {
+	return m.readAllCached(ctx, resultSlicePointer, query, queryParameters, mapper, func(resultSlicePointer interface{}) error {
+		connection, err := m.getConnection(ctx)
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+		return m.contextManager().ReadAllOnConnectionContext(ctx, connection, resultSlicePointer, query, queryParameters, mapper)
+	})
 }
 
 // ReadAllOnConnection executes query with parameters on passed in connection and fetches all table rows. The row is mapped to result slice pointer with record mapper.
 func (m *AbstractManager) ReadAllOnConnection(connection Connection, resultSlicePointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) error {
+	return m.ReadAllOnConnectionContext(context.Background(), connection, resultSlicePointer, query, queryParameters, mapper)
+}
+
+// ReadAllOnConnectionContext executes query with parameters on passed in connection and fetches all table rows, it honours context cancellation and deadline. The row is mapped to result slice pointer with record mapper.
+func (m *AbstractManager) ReadAllOnConnectionContext(ctx context.Context, connection Connection, resultSlicePointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) error {
 	toolbox.AssertPointerKind(resultSlicePointer, reflect.Slice, "resultSlicePointer")
 	slice := reflect.ValueOf(resultSlicePointer).Elem()
 	if mapper == nil {
 		mapper = NewRecordMapperIfNeeded(mapper, reflect.TypeOf(resultSlicePointer).Elem().Elem())
 	}
-	err := m.Manager.ReadAllOnWithHandlerOnConnection(connection, query, queryParameters, func(scannalbe Scanner) (toContinue bool, err error) {
+	err := m.contextManager().ReadAllOnWithHandlerOnConnectionContext(ctx, connection, query, queryParameters, func(scannalbe Scanner) (toContinue bool, err error) {
 		mapped, providerError := mapper.Map(scannalbe)
 		if providerError != nil {
 			return false, fmt.Errorf("failed to map row sql: %v  due to %v", query, providerError.Error())
@@ -144,23 +238,42 @@ func (m *AbstractManager) ReadAllOnConnection(connection Connection, resultSlice
 
 // ReadSingle executes query with parameters and reads on connection single table row. The row is mapped to result pointer with record mapper.
 func (m *AbstractManager) ReadSingle(resultPointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) (success bool, err error) {
-	connection, err := m.Manager.ConnectionProvider().Get()
-	if err != nil {
-		return false, err
-	}
-	defer connection.Close()
-	return m.Manager.ReadSingleOnConnection(connection, resultPointer, query, queryParameters, mapper)
+	return m.readSingleCached(context.Background(), resultPointer, query, queryParameters, mapper, func(resultPointer interface{}) (bool, error) {
+		connection, err := m.Manager.ConnectionProvider().Get()
+		if err != nil {
+			return false, err
+		}
+		defer connection.Close()
+		return m.Manager.ReadSingleOnConnection(connection, resultPointer, query, queryParameters, mapper)
+	})
+}
+
+// ReadSingleContext executes query with parameters and reads on connection single table row, it honours context cancellation and deadline. The row is mapped to result pointer with record mapper.
+func (m *AbstractManager) ReadSingleContext(ctx context.Context, resultPointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) (success bool, err error) {
+	return m.readSingleCached(ctx, resultPointer, query, queryParameters, mapper, func(resultPointer interface{}) (bool, error) {
+		connection, err := m.getConnection(ctx)
+		if err != nil {
+			return false, err
+		}
+		defer connection.Close()
+		return m.contextManager().ReadSingleOnConnectionContext(ctx, connection, resultPointer, query, queryParameters, mapper)
+	})
 }
 
 // ReadSingleOnConnection executes query with parameters on passed in connection and reads single table row. The row is mapped to result pointer with record mapper.
 func (m *AbstractManager) ReadSingleOnConnection(connection Connection, resultPointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) (success bool, err error) {
+	return m.ReadSingleOnConnectionContext(context.Background(), connection, resultPointer, query, queryParameters, mapper)
+}
+
+// ReadSingleOnConnectionContext executes query with parameters on passed in connection and reads single table row, it honours context cancellation and deadline. The row is mapped to result pointer with record mapper.
+func (m *AbstractManager) ReadSingleOnConnectionContext(ctx context.Context, connection Connection, resultPointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper) (success bool, err error) {
 	toolbox.AssertKind(resultPointer, reflect.Ptr, "resultStruct")
 	if mapper == nil {
 		mapper = NewRecordMapperIfNeeded(mapper, reflect.TypeOf(resultPointer).Elem())
 	}
 	var mapped interface{}
 	var elementType = reflect.TypeOf(resultPointer).Elem()
-	err = m.Manager.ReadAllOnWithHandlerOnConnection(connection, query, queryParameters, func(scanner Scanner) (toContinue bool, err error) {
+	err = m.contextManager().ReadAllOnWithHandlerOnConnectionContext(ctx, connection, query, queryParameters, func(scanner Scanner) (toContinue bool, err error) {
 		mapped, err = mapper.Map(scanner)
 		if err != nil {
 			return false, fmt.Errorf("failed to map record: %v with %T due to %v", query, mapper, err)
@@ -202,29 +315,123 @@ func (m *AbstractManager) ReadSingleOnConnection(connection Connection, resultPo
 // PersistAll persists all table rows, dmlProvider is used to generate insert or update statement. It returns number of inserted, updated or error.
 // If driver allows this operation is executed in one transaction.
 func (m *AbstractManager) PersistAll(dataPointer interface{}, table string, provider DmlProvider) (int, int, error) {
-	connection, err := m.Manager.ConnectionProvider().Get()
+	var inserted, updated int
+	err := m.runTransactionalUnit(context.Background(), OperationPersist, func(connection Connection) (err error) {
+		inserted, updated, err = m.Manager.PersistAllOnConnection(connection, dataPointer, table, provider)
+		return err
+	})
 	if err != nil {
 		return 0, 0, err
 	}
-	defer connection.Close()
+	return inserted, updated, nil
+}
 
-	err = connection.Begin()
+// PersistAllContext persists all table rows, it honours context cancellation and deadline, dmlProvider is used to generate insert or update statement. It returns number of inserted, updated or error.
+// If driver allows this operation is executed in one transaction.
+func (m *AbstractManager) PersistAllContext(ctx context.Context, dataPointer interface{}, table string, provider DmlProvider) (int, int, error) {
+	var inserted, updated int
+	err := m.runTransactionalUnit(ctx, OperationPersist, func(connection Connection) (err error) {
+		inserted, updated, err = m.contextManager().PersistAllOnConnectionContext(ctx, connection, dataPointer, table, provider)
+		return err
+	})
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to start transaction on %v due to %v", m.config.Descriptor, err)
+		return 0, 0, err
 	}
-	inserted, updated, err := m.Manager.PersistAllOnConnection(connection, dataPointer, table, provider)
+	return inserted, updated, nil
+}
+
+// runInTransaction starts transaction on passed in connection, then runs handler, transaction is committed if handler returns no error, otherwise it is rolled back.
+func (m *AbstractManager) runInTransaction(connection Connection, handler func() error) error {
+	if err := connection.Begin(); err != nil {
+		return fmt.Errorf("failed to start transaction on %v due to %w", m.config.Descriptor, m.translateError(err))
+	}
+	err := handler()
 	if err == nil {
-		commitErr := connection.Commit()
-		if commitErr != nil {
-			return 0, 0, fmt.Errorf("failed to commit on %v due to %v", m.config.Descriptor, commitErr)
+		if commitErr := connection.Commit(); commitErr != nil {
+			return fmt.Errorf("%w on %v due to %w", errCommitFailed, m.config.Descriptor, m.translateError(commitErr))
 		}
-	} else {
-		rollbackErr := connection.Rollback()
-		if rollbackErr != nil {
-			return 0, 0, fmt.Errorf("failed to rollback on %v due to %v, %v", m.config.Descriptor, err, rollbackErr)
+		return nil
+	}
+	if rollbackErr := connection.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("failed to rollback on %v due to %w, %v", m.config.Descriptor, err, rollbackErr)
+	}
+	return err
+}
+
+// WithTransaction runs handler in a transaction, transaction is committed if handler returns no error, otherwise (or on panic) it is rolled back.
+// If dialect can not handle transaction, handler runs without transaction. Handler is rerun on retryable failure only if RetryPolicy.RetryTransactions is set.
+func (m *AbstractManager) WithTransaction(handler func(tx Connection) error) error {
+	run := func() error {
+		connection, err := m.Manager.ConnectionProvider().Get()
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+		return m.Manager.WithTransactionOnConnection(connection, handler)
+	}
+	if !GetDatastoreDialect(m.config.DriverName).CanHandleTransaction() || m.retry == nil || !m.retry.RetryTransactions {
+		return run()
+	}
+	return m.withRetry(context.Background(), "transaction", run)
+}
+
+// WithTransactionOnConnection runs handler in a transaction on passed in connection, transaction is committed if handler returns no error, otherwise (or on panic) it is rolled back.
+// If connection has already an active transaction, nested scope uses a savepoint, or joins the active transaction if dialect does not support savepoints.
+func (m *AbstractManager) WithTransactionOnConnection(connection Connection, handler func(tx Connection) error) (err error) {
+	if transactional, ok := connection.(transactionalConnection); ok && transactional.inTransaction() {
+		return m.withSavepoint(connection, transactional, handler)
+	}
+	if err = connection.Begin(); err != nil {
+		return fmt.Errorf("failed to start transaction on %v due to %w", m.config.Descriptor, m.translateError(err))
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = connection.Rollback()
+			panic(r)
+		}
+	}()
+	if err = handler(connection); err != nil {
+		if rollbackErr := connection.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("failed to rollback on %v due to %w, %v", m.config.Descriptor, err, rollbackErr)
+		}
+		return err
+	}
+	if commitErr := connection.Commit(); commitErr != nil {
+		return fmt.Errorf("%w on %v due to %w", errCommitFailed, m.config.Descriptor, m.translateError(commitErr))
+	}
+	return nil
+}
+
+func (m *AbstractManager) withSavepoint(connection Connection, transactional transactionalConnection, handler func(tx Connection) error) (err error) {
+	dialect := GetDatastoreDialect(m.config.DriverName)
+	if dialect == nil || !dialect.CanUseSavepoint() {
+		return handler(connection)
+	}
+	name := transactional.nextSavepoint()
+	if _, err = m.Manager.ExecuteOnConnection(connection, dialect.SavepointSQL(SavepointCreate, name), nil); err != nil {
+		return fmt.Errorf("failed to create savepoint %v on %v due to %w", name, m.config.Descriptor, err)
+	}
+	mark := transactional.callbackMark()
+	defer func() {
+		if r := recover(); r != nil {
+			_, _ = m.Manager.ExecuteOnConnection(connection, dialect.SavepointSQL(SavepointRollback, name), nil)
+			transactional.rollbackCallbacks(mark)
+			panic(r)
+		}
+	}()
+	if err = handler(connection); err != nil {
+		transactional.rollbackCallbacks(mark)
+		if _, rollbackErr := m.Manager.ExecuteOnConnection(connection, dialect.SavepointSQL(SavepointRollback, name), nil); rollbackErr != nil {
+			return fmt.Errorf("failed to rollback to savepoint %v on %v due to %w, %v", name, m.config.Descriptor, err, rollbackErr)
+		}
+		return err
+	}
+	if releaseSQL := dialect.SavepointSQL(SavepointRelease, name); releaseSQL != "" {
+		if _, err = m.Manager.ExecuteOnConnection(connection, releaseSQL, nil); err != nil {
+			return fmt.Errorf("failed to release savepoint %v on %v due to %w", name, m.config.Descriptor, err)
 		}
 	}
-	return inserted, updated, err
+	return nil
 }
 
 // RegisterDescriptorIfNeeded register a table descriptor if there it is not present, returns a pointer to a table descriptor.
@@ -234,6 +441,9 @@ func (m *AbstractManager) RegisterDescriptorIfNeeded(table string, instance inte
 		if err != nil {
 			return nil, err
 		}
+		if descriptor.SoftDeleteColumn == "" {
+			descriptor.SoftDeleteColumn = configSoftDeleteColumn(m.config, descriptor.Columns)
+		}
 		_ = m.tableDescriptorRegistry.Register(descriptor)
 	}
 	var result = m.tableDescriptorRegistry.Get(table)
@@ -245,7 +455,12 @@ func (m *AbstractManager) RegisterDescriptorIfNeeded(table string, instance inte
 
 // PersistAllOnConnection persists on connection all table rows, dmlProvider is used to generate insert or update statement. It returns number of inserted, updated or error.
 func (m *AbstractManager) PersistAllOnConnection(connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	return m.PersistAllOnConnectionContext(context.Background(), connection, dataPointer, table, provider)
+}
 
+// PersistAllOnConnectionContext persists on connection all table rows, it honours context cancellation and deadline, dmlProvider is used to generate insert or update statement. It returns number of inserted, updated or error.
+func (m *AbstractManager) PersistAllOnConnectionContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	defer m.invalidateQueryCacheOnConnection(connection, table)
 	if ranger, isRanger := dataPointer.(toolbox.Ranger); isRanger {
 		collection := toolbox.AsSlice(ranger)
 		dataPointer = &collection
@@ -260,45 +475,38 @@ func (m *AbstractManager) PersistAllOnConnection(connection Connection, dataPoin
 	if err != nil {
 		return 0, 0, err
 	}
-	// If we created the default provider and have per-manager reserved settings, rebuild DML with quoting settings.
+	// If we created the default provider and have per-manager reserved settings, rebuild DML with quoting settings on a copy.
 	if p, ok := provider.(*metaDmlProvider); ok && m.reserved != nil {
-		p.withReserved(m.reserved)
-		provider = p
+		provider = p.withReserved(m.reserved)
 	}
 	descriptor, err := m.RegisterDescriptorIfNeeded(table, dataPointer)
 	if err != nil {
 		return 0, 0, err
 	}
-	insertables, updatables, err := m.Manager.ClassifyDataAsInsertableOrUpdatable(connection, dataPointer, table, provider)
+	if upsertProvider, ok := m.upsertProvider(descriptor, provider); ok {
+		upserted, err := m.contextManager().PersistDataContext(ctx, connection, dataPointer, table, nil, func(item interface{}) *ParametrizedSQL {
+			return upsertProvider.Get(SQLTypeUpsert, item)
+		})
+		return upserted, 0, err
+	}
+	insertables, updatables, err := m.contextManager().ClassifyDataAsInsertableOrUpdatableContext(ctx, connection, dataPointer, table, provider)
 	if err != nil {
 		return 0, 0, err
 	}
 
 	var isStructPointer = structType.Kind() == reflect.Ptr
-	var insertableMapping map[int]int
+	var insertableMapping, updatableMapping map[int]int
 	if descriptor.Autoincrement {
 		//we need to store original position of item, vs insertables, to set back autoincrement changed item to original slice
-		insertableMapping = make(map[int]int)
-
-		toolbox.ProcessSliceWithIndex(dataPointer, func(index int, value interface{}) bool {
-			for j, insertable := range insertables {
-				if isStructPointer {
-					if insertable == value {
-						insertableMapping[j] = index
-						break
-					}
-				} else {
-					if reflect.DeepEqual(insertable, value) {
-						insertableMapping[j] = index
-						break
-					}
-				}
-			}
-			return true
-		})
+		insertableMapping = sliceIndexes(dataPointer, insertables, isStructPointer)
+	}
+	versioned, isVersioned := asVersionedProvider(provider)
+	if isVersioned && !isStructPointer {
+		//updated items get incremented version after commit, which has to be set back to original slice
+		updatableMapping = sliceIndexes(dataPointer, updatables, isStructPointer)
 	}
 
-	inserted, insertErr := m.Manager.PersistData(connection, insertables, table, provider, func(item interface{}) *ParametrizedSQL {
+	inserted, insertErr := m.contextManager().PersistDataContext(ctx, connection, insertables, table, provider, func(item interface{}) *ParametrizedSQL {
 		return provider.Get(SQLTypeInsert, item)
 	})
 
@@ -312,17 +520,103 @@ func (m *AbstractManager) PersistAllOnConnection(connection Connection, dataPoin
 		}
 	}
 
-	updated, updateErr := m.Manager.PersistData(connection, updatables, table, provider, func(item interface{}) *ParametrizedSQL {
-		return provider.Get(SQLTypeUpdate, item)
-	})
-
+	updated, updateErr := m.persistUpdatables(ctx, connection, updatables, table, provider)
 	if updateErr != nil {
 		return 0, 0, updateErr
 	}
-
+	if isVersioned {
+		incrementVersions(connection, updatables, versioned, func(index int, item interface{}) {
+			if position, ok := updatableMapping[index]; ok {
+				toolbox.SetSliceValue(dataPointer, position, item)
+			}
+		})
+	}
 	return inserted, updated, nil
 }
 
+// sliceIndexes returns mapping of items position to their position in data slice
+func sliceIndexes(dataPointer interface{}, items []interface{}, isStructPointer bool) map[int]int {
+	var result = make(map[int]int)
+	toolbox.ProcessSliceWithIndex(dataPointer, func(index int, value interface{}) bool {
+		for j, item := range items {
+			if isStructPointer {
+				if item == value {
+					result[j] = index
+					break
+				}
+			} else {
+				if reflect.DeepEqual(item, value) {
+					result[j] = index
+					break
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// batchUpdater represents dml provider that can update many rows with one statement
+type batchUpdater interface {
+	builder() *DmlBuilder
+	batchUpdate(instances []interface{}) *ParametrizedSQL
+}
+
+// persistUpdatables updates rows, it uses one CASE based statement per batch if dialect can batch, otherwise one statement per row
+func (m *AbstractManager) persistUpdatables(ctx context.Context, connection Connection, updatables []interface{}, table string, provider DmlProvider) (int, error) {
+	if versioned, ok := asVersionedProvider(provider); ok {
+		return m.persistVersioned(ctx, connection, updatables, table, SQLTypeUpdate, versioned)
+	}
+	updater, ok := provider.(batchUpdater)
+	batchSize := m.persistBatchSize(GetDatastoreDialect(m.config.DriverName))
+	if !ok || batchSize == 0 || len(updatables) < 2 {
+		return m.contextManager().PersistDataContext(ctx, connection, updatables, table, provider, func(item interface{}) *ParametrizedSQL {
+			return provider.Get(SQLTypeUpdate, item)
+		})
+	}
+	batchSize = rowsPerStatement(batchSize, updater.builder().batchUpdateRowParameters())
+	var updated = 0
+	for i := 0; i < len(updatables); i += batchSize {
+		end := i + batchSize
+		if end > len(updatables) {
+			end = len(updatables)
+		}
+		parametrizedSQL := updater.batchUpdate(updatables[i:end])
+		if parametrizedSQL == nil { //nothing to update, only pk columns
+			return updated, nil
+		}
+		result, err := m.contextManager().ExecuteOnConnectionContext(withOperationHint(ctx, OperationBatch, table), connection, parametrizedSQL.SQL, parametrizedSQL.Values)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		updated += int(affected)
+	}
+	return updated, nil
+}
+
+// upsertProvider returns a copy of provider with dialect upsert statement if rows can be persisted without reading existing keys,
+// autoincrement tables are excluded since generated keys have to be set back on inserted rows
+func (m *AbstractManager) upsertProvider(descriptor *TableDescriptor, provider DmlProvider) (DmlProvider, bool) {
+	if descriptor.Autoincrement || descriptor.VersionColumn != "" || len(descriptor.PkColumns) == 0 || !m.config.GetBoolean(UpsertKey, false) {
+		return nil, false
+	}
+	upsertable, ok := provider.(interface {
+		withDialect(dialect DatastoreDialect) (DmlProvider, bool)
+	})
+	if !ok {
+		return nil, false
+	}
+	dialect := GetDatastoreDialect(m.config.DriverName)
+	if dialect == nil {
+		return nil, false
+	}
+	return upsertable.withDialect(dialect)
+}
+
 // PersistSingle persists single table row, dmlProvider is used to generate insert or update statement. It returns number of inserted, updated or error.
 func (m *AbstractManager) PersistSingle(dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
 	slice := convertToTypesSlice(dataPointer)
@@ -330,6 +624,20 @@ func (m *AbstractManager) PersistSingle(dataPointer interface{}, table string, p
 	if err != nil {
 		return 0, 0, err
 	}
+	return m.setSingleAutoincrementIfNeeded(dataPointer, slice, table, inserted, updated)
+}
+
+// PersistSingleContext persists single table row, it honours context cancellation and deadline, dmlProvider is used to generate insert or update statement. It returns number of inserted, updated or error.
+func (m *AbstractManager) PersistSingleContext(ctx context.Context, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	slice := convertToTypesSlice(dataPointer)
+	inserted, updated, err = m.contextManager().PersistAllContext(ctx, slice, table, provider)
+	if err != nil {
+		return 0, 0, err
+	}
+	return m.setSingleAutoincrementIfNeeded(dataPointer, slice, table, inserted, updated)
+}
+
+func (m *AbstractManager) setSingleAutoincrementIfNeeded(dataPointer, slice interface{}, table string, inserted, updated int) (int, int, error) {
 	if inserted > 0 {
 		descriptor, err := m.RegisterDescriptorIfNeeded(table, dataPointer)
 		if err != nil {
@@ -340,7 +648,7 @@ func (m *AbstractManager) PersistSingle(dataPointer interface{}, table string, p
 			reflect.ValueOf(dataPointer).Elem().Set(reflect.ValueOf(value))
 		}
 	}
-	return inserted, updated, err
+	return inserted, updated, nil
 }
 
 // PersistSingleOnConnection persists on connection single table row, dmlProvider is used to generate insert or udpate statement. It returns number of inserted, updated or error.
@@ -349,8 +657,19 @@ func (m *AbstractManager) PersistSingleOnConnection(connection Connection, dataP
 	return m.Manager.PersistAllOnConnection(connection, &slice, table, provider)
 }
 
+// PersistSingleOnConnectionContext persists on connection single table row, it honours context cancellation and deadline, dmlProvider is used to generate insert or udpate statement. It returns number of inserted, updated or error.
+func (m *AbstractManager) PersistSingleOnConnectionContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	slice := []interface{}{dataPointer}
+	return m.contextManager().PersistAllOnConnectionContext(ctx, connection, &slice, table, provider)
+}
+
 // PersistData batch data on connection on table, keySetter is used to optionally set autoincrement column, sqlProvider handler will generate ParametrizedSQL with Insert or Update statement.
 func (m *AbstractManager) PersistData(connection Connection, data interface{}, table string, keySetter KeySetter, sqlProvider func(item interface{}) *ParametrizedSQL) (int, error) {
+	return m.PersistDataContext(context.Background(), connection, data, table, keySetter, sqlProvider)
+}
+
+// PersistDataContext batch data on connection on table, it honours context cancellation and deadline, keySetter is used to optionally set autoincrement column, sqlProvider handler will generate ParametrizedSQL with Insert or Update statement.
+func (m *AbstractManager) PersistDataContext(ctx context.Context, connection Connection, data interface{}, table string, keySetter KeySetter, sqlProvider func(item interface{}) *ParametrizedSQL) (int, error) {
 	var collection = make([]interface{}, 0)
 	updateId := func(index int, seq int64) {
 		if seq == 0 || index < 0 {
@@ -379,7 +698,7 @@ func (m *AbstractManager) PersistData(connection Connection, data interface{}, t
 			collection[index] = structPointerValue.Elem().Interface()
 		}
 	}
-	var batch = newBatch(table, connection, m, sqlProvider, updateId)
+	var batch = newBatch(ctx, table, connection, m, sqlProvider, updateId)
 	var err error
 	if ranger, ok := data.(toolbox.Ranger); ok {
 		err = ranger.Range(func(item interface{}) (b bool, e error) {
@@ -409,13 +728,13 @@ func (m *AbstractManager) PersistData(connection Connection, data interface{}, t
 	return batch.processed, err
 }
 
-func (m *AbstractManager) fetchDataInBatches(connection Connection, sqlsWihtArguments []*ParametrizedSQL, mapper RecordMapper) (*[][]interface{}, error) {
+func (m *AbstractManager) fetchDataInBatches(ctx context.Context, connection Connection, sqlsWihtArguments []*ParametrizedSQL, mapper RecordMapper) (*[][]interface{}, error) {
 	var rows = make([][]interface{}, 0)
 	for _, sqlWihtArguments := range sqlsWihtArguments {
 		if len(sqlWihtArguments.Values) == 0 {
 			break
 		}
-		err := m.Manager.ReadAllOnConnection(connection, &rows, sqlWihtArguments.SQL, sqlWihtArguments.Values, mapper)
+		err := m.contextManager().ReadAllOnConnectionContext(ctx, connection, &rows, sqlWihtArguments.SQL, sqlWihtArguments.Values, mapper)
 		if err != nil {
 			return nil, err
 		}
@@ -423,20 +742,23 @@ func (m *AbstractManager) fetchDataInBatches(connection Connection, sqlsWihtArgu
 	return &rows, nil
 }
 
-func (m *AbstractManager) fetchExistingData(connection Connection, table string, pkValues [][]interface{}, provider DmlProvider) ([][]interface{}, error) {
+func (m *AbstractManager) fetchExistingData(ctx context.Context, connection Connection, table string, pkValues [][]interface{}, provider DmlProvider) ([][]interface{}, error) {
 	var rows = make([][]interface{}, 0)
 	descriptor := m.tableDescriptorRegistry.Get(table)
 
 	if len(pkValues) > 0 {
-		descriptor := TableDescriptor{Table: table, PkColumns: descriptor.PkColumns}
+		descriptor := TableDescriptor{Table: table, PkColumns: descriptor.PkColumns, SoftDeleteColumn: descriptor.SoftDeleteColumn}
 		qb := NewQueryBuilder(&descriptor, "")
 		if m.reserved != nil {
 			qb = qb.WithReserved(m.reserved)
 		}
+		if isIncludeDeleted(ctx) {
+			qb = qb.WithDeleted()
+		}
 		sqlWithArguments := qb.BuildBatchedQueryOnPk(descriptor.PkColumns, pkValues, defaultBatchSize)
 
 		var mapper = NewColumnarRecordMapper(false, reflect.TypeOf(rows))
-		batched, err := m.fetchDataInBatches(connection, sqlWithArguments, mapper)
+		batched, err := m.fetchDataInBatches(ctx, connection, sqlWithArguments, mapper)
 		if err != nil {
 			return nil, err
 		}
@@ -447,6 +769,11 @@ func (m *AbstractManager) fetchExistingData(connection Connection, table string,
 
 // ClassifyDataAsInsertableOrUpdatable classifies passed in data as insertable or updatable.
 func (m *AbstractManager) ClassifyDataAsInsertableOrUpdatable(connection Connection, dataPointer interface{}, table string, provider DmlProvider) ([]interface{}, []interface{}, error) {
+	return m.ClassifyDataAsInsertableOrUpdatableContext(context.Background(), connection, dataPointer, table, provider)
+}
+
+// ClassifyDataAsInsertableOrUpdatableContext classifies passed in data as insertable or updatable, it honours context cancellation and deadline.
+func (m *AbstractManager) ClassifyDataAsInsertableOrUpdatableContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, provider DmlProvider) ([]interface{}, []interface{}, error) {
 	if provider == nil {
 		return nil, nil, errors.New("provider was nil")
 	}
@@ -473,9 +800,9 @@ func (m *AbstractManager) ClassifyDataAsInsertableOrUpdatable(connection Connect
 
 	if hasPK { //only if has PK, otherwise always insert
 		//fetch all existing pk values into rows to classify as updatable
-		rows, err := m.fetchExistingData(connection, table, pkValues, provider)
+		rows, err := m.fetchExistingData(ctx, connection, table, pkValues, provider)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch existing data: due to:\n\t%v", err.Error())
+			return nil, nil, fmt.Errorf("failed to fetch existing data: due to:\n\t%w", err)
 		}
 		//process existing rows and add mapped entires as updatables
 		for _, row := range rows {
@@ -500,34 +827,37 @@ func (m *AbstractManager) ClassifyDataAsInsertableOrUpdatable(connection Connect
 
 // DeleteAll deletes all rows for passed in table,  key provider is used to extract primary keys. It returns number of deleted rows or error.
 func (m *AbstractManager) DeleteAll(dataPointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error) {
-	connection, err := m.Manager.ConnectionProvider().Get()
+	err = m.runTransactionalUnit(context.Background(), OperationDelete, func(connection Connection) (err error) {
+		deleted, err = m.DeleteAllOnConnection(connection, dataPointer, table, keyProvider)
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
-	defer connection.Close()
-	err = connection.Begin()
+	return deleted, nil
+}
+
+// DeleteAllContext deletes all rows for passed in table, it honours context cancellation and deadline, key provider is used to extract primary keys. It returns number of deleted rows or error.
+func (m *AbstractManager) DeleteAllContext(ctx context.Context, dataPointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error) {
+	err = m.runTransactionalUnit(ctx, OperationDelete, func(connection Connection) (err error) {
+		deleted, err = m.contextManager().DeleteAllOnConnectionContext(ctx, connection, dataPointer, table, keyProvider)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to start transaction on %v due to %v", m.config.Descriptor, err)
-	}
-	deleted, err = m.DeleteAllOnConnection(connection, dataPointer, table, keyProvider)
-	if err == nil {
-		commitErr := connection.Commit()
-		if commitErr != nil {
-			return 0, fmt.Errorf("failed to commit on %v due to %v", m.config.Descriptor, commitErr)
-		}
-	} else {
-		rollbackErr := connection.Rollback()
-		if rollbackErr != nil {
-			return 0, fmt.Errorf("failed to rollback on %v due to %v, %v", m.config.Descriptor, err, rollbackErr)
-		}
+		return 0, err
 	}
-	return deleted, err
+	return deleted, nil
 }
 
 // DeleteAllOnConnection deletes all rows on connection from table, key provider is used to extract primary keys. It returns number of deleted rows or error.
 // If driver allows this operation is executed in one transaction.
 func (m *AbstractManager) DeleteAllOnConnection(connection Connection, dataPointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error) {
+	return m.DeleteAllOnConnectionContext(context.Background(), connection, dataPointer, table, keyProvider)
+}
 
+// DeleteAllOnConnectionContext deletes all rows on connection from table, it honours context cancellation and deadline, key provider is used to extract primary keys. It returns number of deleted rows or error.
+func (m *AbstractManager) DeleteAllOnConnectionContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error) {
+	defer m.invalidateQueryCacheOnConnection(connection, table)
 	deleted = 0
 	structType := toolbox.DiscoverTypeByKind(dataPointer, reflect.Struct)
 	keyProvider, err = NewKeyGetterIfNeeded(keyProvider, table, structType)
@@ -537,6 +867,19 @@ func (m *AbstractManager) DeleteAllOnConnection(connection Connection, dataPoint
 	m.RegisterDescriptorIfNeeded(table, dataPointer)
 
 	descriptor := m.tableDescriptorRegistry.Get(table)
+	if descriptor.SoftDeleteColumn != "" && !isHardDelete(ctx) {
+		batchSize := m.persistBatchSize(GetDatastoreDialect(m.config.DriverName))
+		if batchSize == 0 {
+			batchSize = 1
+		}
+		return m.softDeleteAll(ctx, connection, dataPointer, descriptor, keyProvider, rowsPerStatement(batchSize, len(descriptor.PkColumns)+1))
+	}
+	if versioned, ok := asVersionedProvider(keyProvider); ok {
+		return m.persistVersioned(ctx, connection, toolbox.AsSlice(dataPointer), table, SQLTypeDelete, versioned)
+	}
+	if batchSize := m.persistBatchSize(GetDatastoreDialect(m.config.DriverName)); batchSize > 0 {
+		return m.deleteAllInBatches(ctx, connection, dataPointer, descriptor, keyProvider, rowsPerStatement(batchSize, len(descriptor.PkColumns)))
+	}
 	toolbox.ProcessSlice(dataPointer, func(item interface{}) bool {
 		if err != nil {
 			return false
@@ -552,7 +895,7 @@ func (m *AbstractManager) DeleteAllOnConnection(connection Connection, dataPoint
 		}
 		dml := fmt.Sprintf(deleteSQLTemplate, table, where)
 		var result sql.Result
-		result, err = m.Manager.ExecuteOnConnection(connection, dml, keyProvider.Key(item))
+		result, err = m.contextManager().ExecuteOnConnectionContext(withOperationHint(ctx, OperationDelete, table), connection, dml, keyProvider.Key(item))
 		if err != nil {
 			return false
 		}
@@ -569,6 +912,46 @@ func (m *AbstractManager) DeleteAllOnConnection(connection Connection, dataPoint
 	return deleted, nil
 }
 
+// canUseRowValueIn returns true if driver dialect supports row value IN criteria for composite keys
+func canUseRowValueIn(driverName string) bool {
+	dialect := GetDatastoreDialect(driverName)
+	return dialect == nil || dialect.CanUseRowValueIn()
+}
+
+// deleteAllInBatches deletes rows with pk IN criteria, composite keys use tuple IN or OR-ed key predicates if dialect does not support it
+func (m *AbstractManager) deleteAllInBatches(ctx context.Context, connection Connection, dataPointer interface{}, descriptor *TableDescriptor, keyProvider KeyGetter, batchSize int) (int, error) {
+	var pk = append([]string{}, descriptor.PkColumns...)
+	if m.reserved != nil {
+		m.reserved.quoteIfReserved(pk)
+	} else {
+		updateReserved(pk)
+	}
+	var keys = make([][]interface{}, 0)
+	toolbox.ProcessSlice(dataPointer, func(item interface{}) bool {
+		keys = append(keys, keyProvider.Key(item))
+		return true
+	})
+	var deleted = 0
+	var rowValueIn = canUseRowValueIn(m.Config().DriverName)
+	for i := 0; i < len(keys); i += batchSize {
+		end := i + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		where, values := buildInCriteria(pk, keys[i:end], rowValueIn)
+		result, err := m.contextManager().ExecuteOnConnectionContext(withOperationHint(ctx, OperationDelete, descriptor.Table), connection, fmt.Sprintf(deleteSQLTemplate, descriptor.Table, where), values)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		deleted += int(affected)
+	}
+	return deleted, nil
+}
+
 func (m *AbstractManager) buildPKWhere(descriptor *TableDescriptor) string {
 	var pk = append([]string{}, descriptor.PkColumns...)
 	if m.reserved != nil {
@@ -582,28 +965,28 @@ func (m *AbstractManager) buildPKWhere(descriptor *TableDescriptor) string {
 
 // DeleteSingle deletes single row from table on for passed in data pointer, key provider is used to extract primary keys. It returns boolean if successful, or error.
 func (m *AbstractManager) DeleteSingle(dataPointer interface{}, table string, keyProvider KeyGetter) (bool, error) {
-	connection, err := m.Manager.ConnectionProvider().Get()
+	var success bool
+	err := m.runTransactionalUnit(context.Background(), OperationDelete, func(connection Connection) (err error) {
+		success, err = m.DeleteSingleOnConnection(connection, dataPointer, table, keyProvider)
+		return err
+	})
 	if err != nil {
 		return false, err
 	}
-	defer connection.Close()
-	err = connection.Begin()
+	return success, nil
+}
+
+// DeleteSingleContext deletes single row from table on for passed in data pointer, it honours context cancellation and deadline, key provider is used to extract primary keys. It returns boolean if successful, or error.
+func (m *AbstractManager) DeleteSingleContext(ctx context.Context, dataPointer interface{}, table string, keyProvider KeyGetter) (bool, error) {
+	var success bool
+	err := m.runTransactionalUnit(ctx, OperationDelete, func(connection Connection) (err error) {
+		success, err = m.contextManager().DeleteSingleOnConnectionContext(ctx, connection, dataPointer, table, keyProvider)
+		return err
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to start transaction on %v due to %v", m.config.Descriptor, err)
-	}
-	suceess, err := m.DeleteSingleOnConnection(connection, dataPointer, table, keyProvider)
-	if err == nil {
-		commitErr := connection.Commit()
-		if commitErr != nil {
-			return false, fmt.Errorf("failed to commit on %v due to %v", m.config.Descriptor, commitErr)
-		}
-	} else {
-		rollbackErr := connection.Rollback()
-		if rollbackErr != nil {
-			return false, fmt.Errorf("failed to rollback on %v due to %v, %v", m.config.Descriptor, err, rollbackErr)
-		}
+		return false, err
 	}
-	return suceess, err
+	return success, nil
 }
 
 func convertToTypesSlice(dataPointer interface{}) interface{} {
@@ -627,6 +1010,17 @@ func (m *AbstractManager) DeleteSingleOnConnection(connection Connection, dataPo
 	return deleted == 1, nil
 }
 
+// DeleteSingleOnConnectionContext deletes data on connection from table on for passed in data pointer, it honours context cancellation and deadline, key provider is used to extract primary keys. It returns true if successful.
+func (m *AbstractManager) DeleteSingleOnConnectionContext(ctx context.Context, connection Connection, dataPointer interface{}, table string, keyProvider KeyGetter) (bool, error) {
+	toolbox.AssertPointerKind(dataPointer, reflect.Struct, "dataPointer")
+	slice := convertToTypesSlice(dataPointer)
+	deleted, err := m.contextManager().DeleteAllOnConnectionContext(ctx, connection, slice, table, keyProvider)
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
+
 // ExpandSQL expands sql with passed in arguments
 func (m *AbstractManager) ExpandSQL(sql string, arguments []interface{}) string {
 	for _, arg := range arguments {
@@ -648,15 +1042,48 @@ func (m *AbstractManager) TableDescriptorRegistry() TableDescriptorRegistry {
 	return m.tableDescriptorRegistry
 }
 
+// reservedSettings returns per manager reserved keywords settings.
+func (m *AbstractManager) reservedSettings() *Reserved {
+	return m.reserved
+}
+
+// typedProviderCache returns manager scoped cache of dml providers used by generic API
+func (m *AbstractManager) typedProviderCache() *sync.Map {
+	return m.typedProviders
+}
+
+// contextManager returns datastore specific manager context API, or abstract implementation if datastore manager does not implement it.
+func (m *AbstractManager) contextManager() ContextManager {
+	if result, ok := m.Manager.(ContextManager); ok {
+		return result
+	}
+	return m
+}
+
+// getConnection returns a connection from connection provider, it honours context cancellation and deadline if provider supports it.
+func (m *AbstractManager) getConnection(ctx context.Context) (Connection, error) {
+	provider := m.Manager.ConnectionProvider()
+	if contextProvider, ok := provider.(ContextConnectionProvider); ok {
+		return contextProvider.GetContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return provider.Get()
+}
+
 // NewAbstractManager create a new abstract manager, it takes config, conneciton provider, and target (sub class) manager
 func NewAbstractManager(config *Config, connectionProvider ConnectionProvider, self Manager) *AbstractManager {
 	var descriptorRegistry = newTableDescriptorRegistry()
-	var result = &AbstractManager{config: config, connectionProvider: connectionProvider, Manager: self, tableDescriptorRegistry: descriptorRegistry}
+	var result = &AbstractManager{config: config, connectionProvider: connectionProvider, Manager: self, tableDescriptorRegistry: descriptorRegistry, typedProviders: &sync.Map{}}
 	descriptorRegistry.manager = result
 	// Initialize reserved keyword handling from config (per manager instance).
 	result.reserved = newReservedFromConfig(config)
 	if config.MaxRequestPerSecond > 0 {
 		result.limiter = NewLimiter(time.Second, config.MaxRequestPerSecond)
 	}
+	result.metrics = newMetrics(result)
+	result.retry = config.retryPolicy()
+	result.queryCache = newQueryCache(config)
 	return result
 }
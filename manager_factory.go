@@ -16,7 +16,27 @@ func (f managerFactoryProxy) Create(config *Config) (Manager, error) {
 		return nil, fmt.Errorf("failed to lookup manager factory for `%v`, make sure you have imported required implmentation", config.DriverName)
 	}
 	config.Init()
-	return factory.Create(config)
+	manager, err := factory.Create(config)
+	if err != nil {
+		return nil, err
+	}
+	return f.withReplicas(config, manager)
+}
+
+// withReplicas wraps manager with ReplicatedManager if config defines replicas.
+func (f managerFactoryProxy) withReplicas(config *Config, primary Manager) (Manager, error) {
+	if len(config.Replicas) == 0 {
+		return primary, nil
+	}
+	var replicas = make([]Manager, 0, len(config.Replicas))
+	for i, replicaConfig := range config.Replicas {
+		replica, err := f.Create(replicaConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create replica[%d] manager: %w", i, err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return NewReplicatedManager(primary, replicas...)
 }
 
 // CreateFromURL create a new manager from URL, url resource should be a JSON Config
@@ -29,7 +49,11 @@ func (f managerFactoryProxy) CreateFromURL(URL string) (Manager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to lookup manager factory for `%v`, make sure you have imported required implmentation", config.DriverName)
 	}
-	return factory.Create(config)
+	manager, err := factory.Create(config)
+	if err != nil {
+		return nil, err
+	}
+	return f.withReplicas(config, manager)
 }
 
 // NewManagerFactory create a new manager factory.
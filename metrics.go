@@ -0,0 +1,316 @@
+package dsc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//MetricRead represents read metric operation
+	MetricRead = "read"
+	//MetricInsert represents insert metric operation
+	MetricInsert = "insert"
+	//MetricUpdate represents update metric operation
+	MetricUpdate = "update"
+	//MetricDelete represents delete metric operation
+	MetricDelete = "delete"
+	//MetricBatch represents batch flush metric operation
+	MetricBatch = "batch"
+	//MetricExecute represents other sql execution metric operation
+	MetricExecute = "execute"
+)
+
+//DefaultLatencyBuckets represents default latency histogram upper bounds
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+//HistogramSnapshot represents latency histogram snapshot
+type HistogramSnapshot struct {
+	Buckets []time.Duration //bucket upper bounds
+	Counts  []uint64        //cumulative number of observations less or equal to corresponding bucket
+	Count   uint64          //total number of observations
+	Sum     time.Duration   //total observed latency
+}
+
+//OperationStats represents per table and operation statistics
+type OperationStats struct {
+	Table     string
+	Operation string
+	Count     uint64 //number of calls
+	Errors    uint64 //number of failed calls
+	Rows      int64  //number of affected or fetched rows
+	Latency   HistogramSnapshot
+}
+
+//WaitStats represents resource wait statistics
+type WaitStats struct {
+	Waits    int64
+	WaitTime time.Duration
+}
+
+//MetricsSnapshot represents manager metrics snapshot
+type MetricsSnapshot struct {
+	Driver     string
+	Operations []*OperationStats //operation stats sorted by table and operation
+	Pool       *PoolStats        //pool stats, nil if connection provider does not use pool
+	Limiter    *WaitStats        //limiter wait stats, nil if max request per second is not set
+}
+
+type metricKey struct {
+	table     string
+	operation string
+}
+
+type operationMetric struct {
+	count   uint64
+	errors  uint64
+	rows    int64
+	buckets []uint64
+	sum     time.Duration
+}
+
+//Metrics represents manager metrics registry, it implements http.Handler exporting metrics in Prometheus text format
+type Metrics struct {
+	mux        *sync.Mutex
+	driver     string
+	buckets    []time.Duration
+	operations map[metricKey]*operationMetric
+	pool       func() *PoolStats
+	limiter    func() *WaitStats
+}
+
+//Record records operation outcome
+func (m *Metrics) Record(table, operation string, elapsed time.Duration, rows int64, err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	key := metricKey{table: table, operation: operation}
+	metric, ok := m.operations[key]
+	if !ok {
+		metric = &operationMetric{buckets: make([]uint64, len(m.buckets))}
+		m.operations[key] = metric
+	}
+	metric.count++
+	if err != nil {
+		metric.errors++
+	}
+	metric.rows += rows
+	metric.sum += elapsed
+	for i, bound := range m.buckets {
+		if elapsed <= bound {
+			metric.buckets[i]++
+			break
+		}
+	}
+}
+
+//Reset removes all recorded operation metrics
+func (m *Metrics) Reset() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.operations = make(map[metricKey]*operationMetric)
+}
+
+//Snapshot returns metrics snapshot
+func (m *Metrics) Snapshot() *MetricsSnapshot {
+	m.mux.Lock()
+	var result = &MetricsSnapshot{Driver: m.driver, Operations: make([]*OperationStats, 0, len(m.operations))}
+	for key, metric := range m.operations {
+		stats := &OperationStats{
+			Table:     key.table,
+			Operation: key.operation,
+			Count:     metric.count,
+			Errors:    metric.errors,
+			Rows:      metric.rows,
+			Latency: HistogramSnapshot{
+				Buckets: m.buckets,
+				Counts:  make([]uint64, len(m.buckets)),
+				Count:   metric.count,
+				Sum:     metric.sum,
+			},
+		}
+		var cumulative uint64
+		for i, count := range metric.buckets {
+			cumulative += count
+			stats.Latency.Counts[i] = cumulative
+		}
+		result.Operations = append(result.Operations, stats)
+	}
+	m.mux.Unlock()
+	sort.Slice(result.Operations, func(i, j int) bool {
+		if result.Operations[i].Table == result.Operations[j].Table {
+			return result.Operations[i].Operation < result.Operations[j].Operation
+		}
+		return result.Operations[i].Table < result.Operations[j].Table
+	})
+	if m.pool != nil {
+		result.Pool = m.pool()
+	}
+	if m.limiter != nil {
+		result.Limiter = m.limiter()
+	}
+	return result
+}
+
+//WritePrometheus writes metrics snapshot in Prometheus text exposition format
+func (m *Metrics) WritePrometheus(writer io.Writer) error {
+	return m.Snapshot().WritePrometheus(writer)
+}
+
+//ServeHTTP exports metrics in Prometheus text exposition format
+func (m *Metrics) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.WritePrometheus(writer); err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+//WritePrometheus writes snapshot in Prometheus text exposition format
+func (s *MetricsSnapshot) WritePrometheus(writer io.Writer) error {
+	w := bufio.NewWriter(writer)
+	driver := `driver="` + escapeLabel(s.Driver) + `"`
+	labels := func(stats *OperationStats) string {
+		return driver + `,table="` + escapeLabel(stats.Table) + `",operation="` + escapeLabel(stats.Operation) + `"`
+	}
+	writeHeader := func(name, kind, help string) {
+		fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+	}
+	if len(s.Operations) > 0 {
+		writeHeader("dsc_operations_total", "counter", "Number of datastore operations.")
+		for _, stats := range s.Operations {
+			fmt.Fprintf(w, "dsc_operations_total{%v} %v\n", labels(stats), stats.Count)
+		}
+		writeHeader("dsc_operation_errors_total", "counter", "Number of failed datastore operations.")
+		for _, stats := range s.Operations {
+			fmt.Fprintf(w, "dsc_operation_errors_total{%v} %v\n", labels(stats), stats.Errors)
+		}
+		writeHeader("dsc_operation_rows_total", "counter", "Number of rows affected or fetched by datastore operations.")
+		for _, stats := range s.Operations {
+			fmt.Fprintf(w, "dsc_operation_rows_total{%v} %v\n", labels(stats), stats.Rows)
+		}
+		writeHeader("dsc_operation_duration_seconds", "histogram", "Datastore operation latency.")
+		for _, stats := range s.Operations {
+			for i, bound := range stats.Latency.Buckets {
+				fmt.Fprintf(w, "dsc_operation_duration_seconds_bucket{%v,le=\"%v\"} %v\n", labels(stats), bound.Seconds(), stats.Latency.Counts[i])
+			}
+			fmt.Fprintf(w, "dsc_operation_duration_seconds_bucket{%v,le=\"+Inf\"} %v\n", labels(stats), stats.Latency.Count)
+			fmt.Fprintf(w, "dsc_operation_duration_seconds_sum{%v} %v\n", labels(stats), stats.Latency.Sum.Seconds())
+			fmt.Fprintf(w, "dsc_operation_duration_seconds_count{%v} %v\n", labels(stats), stats.Latency.Count)
+		}
+	}
+	if pool := s.Pool; pool != nil {
+		for _, gauge := range []struct {
+			name  string
+			help  string
+			value int
+		}{
+			{"dsc_pool_max_connections", "Max number of open connections.", pool.MaxSize},
+			{"dsc_pool_open_connections", "Number of open connections.", pool.Open},
+			{"dsc_pool_in_use_connections", "Number of connections in use.", pool.InUse},
+			{"dsc_pool_idle_connections", "Number of idle connections.", pool.Idle},
+		} {
+			writeHeader(gauge.name, "gauge", gauge.help)
+			fmt.Fprintf(w, "%v{%v} %v\n", gauge.name, driver, gauge.value)
+		}
+		writeHeader("dsc_pool_waits_total", "counter", "Number of connection acquires that had to wait.")
+		fmt.Fprintf(w, "dsc_pool_waits_total{%v} %v\n", driver, pool.Waits)
+		writeHeader("dsc_pool_wait_seconds_total", "counter", "Total time spent waiting for a connection.")
+		fmt.Fprintf(w, "dsc_pool_wait_seconds_total{%v} %v\n", driver, pool.WaitTime.Seconds())
+		writeHeader("dsc_pool_timeouts_total", "counter", "Number of connection acquires that timed out.")
+		fmt.Fprintf(w, "dsc_pool_timeouts_total{%v} %v\n", driver, pool.Timeouts)
+	}
+	if limiter := s.Limiter; limiter != nil {
+		writeHeader("dsc_limiter_waits_total", "counter", "Number of requests throttled by rate limiter.")
+		fmt.Fprintf(w, "dsc_limiter_waits_total{%v} %v\n", driver, limiter.Waits)
+		writeHeader("dsc_limiter_wait_seconds_total", "counter", "Total time spent waiting for rate limiter.")
+		fmt.Fprintf(w, "dsc_limiter_wait_seconds_total{%v} %v\n", driver, limiter.WaitTime.Seconds())
+	}
+	return w.Flush()
+}
+
+func escapeLabel(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
+
+//metricOperation returns metric operation for passed in intercepted operation
+func metricOperation(operation *Operation) string {
+	switch operation.Kind {
+	case OperationRead:
+		return MetricRead
+	case OperationBatch:
+		return MetricBatch
+	case OperationDelete:
+		return MetricDelete
+	}
+	SQL := strings.TrimLeft(operation.SQL, " \t\r\n(")
+	if index := strings.IndexAny(SQL, " \t\r\n("); index != -1 {
+		SQL = SQL[:index]
+	}
+	switch strings.ToUpper(SQL) {
+	case "SELECT", "WITH":
+		return MetricRead
+	case "INSERT", "REPLACE", "MERGE", "UPSERT":
+		return MetricInsert
+	case "UPDATE":
+		return MetricUpdate
+	case "DELETE":
+		return MetricDelete
+	}
+	return MetricExecute
+}
+
+//Intercept records operation metrics
+func (m *Metrics) Intercept(operation *Operation, next OperationHandler) error {
+	startTime := time.Now()
+	err := next(operation)
+	m.Record(operation.Table, metricOperation(operation), time.Now().Sub(startTime), operation.Rows, err)
+	return err
+}
+
+//Metrics returns manager metrics registry
+func (m *AbstractManager) Metrics() *Metrics {
+	return m.metrics
+}
+
+func newMetrics(manager *AbstractManager) *Metrics {
+	result := &Metrics{
+		mux:        &sync.Mutex{},
+		driver:     manager.config.DriverName,
+		buckets:    DefaultLatencyBuckets,
+		operations: make(map[metricKey]*operationMetric),
+	}
+	result.pool = func() *PoolStats {
+		if provider, ok := manager.connectionProvider.(PooledConnectionProvider); ok {
+			stats := provider.PoolStats()
+			return &stats
+		}
+		return nil
+	}
+	result.limiter = func() *WaitStats {
+		if manager.limiter == nil {
+			return nil
+		}
+		waits, waitTime := manager.limiter.Stats()
+		return &WaitStats{Waits: waits, WaitTime: waitTime}
+	}
+	return result
+}
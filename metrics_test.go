@@ -0,0 +1,75 @@
+package dsc_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestMetrics(t *testing.T) {
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db")
+	config.MaxRequestPerSecond = 1000
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS users",
+		"CREATE TABLE users (id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, username varchar(255) DEFAULT NULL, active tinyint(1) DEFAULT '1', salary decimal(7,2) DEFAULT NULL, comments text, last_access_time timestamp DEFAULT CURRENT_TIMESTAMP)",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	metrics := manager.(interface{ Metrics() *dsc.Metrics }).Metrics()
+	metrics.Reset()
+
+	users := []User{{Username: "Bob"}, {Username: "Sam"}}
+	_, _, err = manager.PersistAll(&users, "users", nil)
+	assert.Nil(t, err)
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id, username FROM users", nil, nil)
+	assert.Nil(t, err)
+	_, err = manager.Execute("UPDATE users SET username = ? WHERE id = ?", "Ela", users[0].Id)
+	assert.Nil(t, err)
+	_, err = manager.Execute("DELETE FROM unknown_table")
+	assert.NotNil(t, err)
+
+	snapshot := metrics.Snapshot()
+	var stats = make(map[string]*dsc.OperationStats)
+	for _, item := range snapshot.Operations {
+		stats[item.Table+"/"+item.Operation] = item
+	}
+	if insert, ok := stats["users/insert"]; assert.True(t, ok) {
+		assert.EqualValues(t, 2, insert.Count)
+		assert.EqualValues(t, 2, insert.Rows)
+		assert.EqualValues(t, 2, insert.Latency.Counts[len(insert.Latency.Counts)-1])
+	}
+	if read, ok := stats["users/read"]; assert.True(t, ok) {
+		assert.EqualValues(t, 2, read.Count) //existing keys check and select
+		assert.EqualValues(t, 2, read.Rows)
+	}
+	if update, ok := stats["users/update"]; assert.True(t, ok) {
+		assert.EqualValues(t, 1, update.Rows)
+	}
+	if failed, ok := stats["unknown_table/delete"]; assert.True(t, ok) {
+		assert.EqualValues(t, 1, failed.Errors)
+	}
+	assert.NotNil(t, snapshot.Pool)
+	assert.NotNil(t, snapshot.Limiter)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	assert.Contains(t, body, "# TYPE dsc_operation_duration_seconds histogram")
+	assert.Contains(t, body, `dsc_operations_total{driver="sqlite3",table="users",operation="insert"} 2`)
+	assert.Contains(t, body, `dsc_operation_duration_seconds_bucket{driver="sqlite3",table="users",operation="update",le="+Inf"} 1`)
+	assert.Contains(t, body, `dsc_pool_max_connections{driver="sqlite3"} 2`)
+	assert.Contains(t, body, `dsc_limiter_waits_total{driver="sqlite3"} 0`)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(t, metrics.WritePrometheus(buffer))
+	assert.Contains(t, buffer.String(), `dsc_operation_errors_total{driver="sqlite3",table="unknown_table",operation="delete"} 1`)
+}
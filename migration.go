@@ -0,0 +1,454 @@
+package dsc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/storage"
+	"github.com/viant/toolbox/url"
+)
+
+const (
+	//DefaultMigrationTable represents default table tracking applied migrations
+	DefaultMigrationTable = "schema_migrations"
+	//DefaultMigrationLockTable represents default table used as migration lock
+	DefaultMigrationLockTable = "schema_migrations_lock"
+
+	defaultMigrationLockTimeout = time.Minute
+	migrationLockRetryDelay     = 200 * time.Millisecond
+	migrationLockID             = 1
+)
+
+//migrationFilePattern matches <version>_<name>.up.sql, <version>_<name>.down.sql or <version>_<name>.sql (up) file name
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+//ErrMigrationLocked represents migration lock held by another process
+var ErrMigrationLocked = errors.New("migration lock is held by another process")
+
+//Migration represents versioned schema migration
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       []string //up statements
+	Down     []string //down statements, rollback fails if empty
+	Checksum string   //up script SHA-256 checksum
+}
+
+//MigrationRecord represents applied migration tracking record
+type MigrationRecord struct {
+	Version   int64     `column:"version" primaryKey:"true"`
+	Name      string    `column:"name"`
+	Checksum  string    `column:"checksum" size:"64"`
+	AppliedAt time.Time `column:"applied_at"`
+}
+
+//migrationLock represents migration lock record
+type migrationLock struct {
+	Id       int       `column:"id" primaryKey:"true"`
+	LockedAt time.Time `column:"locked_at"`
+}
+
+//NewMigration creates a new migration for passed in up and down scripts, statements are separated with semicolon
+func NewMigration(version int64, name, up, down string) *Migration {
+	checksum := sha256.Sum256([]byte(up))
+	return &Migration{
+		Version:  version,
+		Name:     name,
+		Up:       splitSQLScript(up),
+		Down:     splitSQLScript(down),
+		Checksum: hex.EncodeToString(checksum[:]),
+	}
+}
+
+//splitSQLScript splits script into statements separated with semicolon outside quotes, block comments and postgres dollar quoted bodies,
+//line comments are removed
+func splitSQLScript(script string) []string {
+	var result = make([]string, 0)
+	var statement strings.Builder
+	var quote rune
+	var flush = func() {
+		if text := strings.TrimSpace(statement.String()); text != "" {
+			result = append(result, text)
+		}
+		statement.Reset()
+	}
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		aChar := runes[i]
+		switch {
+		case quote != 0:
+			if aChar == quote {
+				quote = 0
+			}
+		case aChar == '\'' || aChar == '"' || aChar == '`':
+			quote = aChar
+		case aChar == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			statement.WriteRune('\n')
+			continue
+		case aChar == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			end := indexRunes(runes, i+2, []rune("*/")) + 2
+			statement.WriteString(string(runes[i:end]))
+			i = end - 1
+			continue
+		case aChar == '$':
+			tag := dollarQuoteTag(runes, i)
+			if tag == nil {
+				break
+			}
+			end := indexRunes(runes, i+len(tag), tag) + len(tag)
+			statement.WriteString(string(runes[i:end]))
+			i = end - 1
+			continue
+		case aChar == ';':
+			flush()
+			continue
+		}
+		statement.WriteRune(aChar)
+	}
+	flush()
+	return result
+}
+
+//dollarQuoteTag returns postgres dollar quote tag ($$ or $tag$) starting at passed in index, or nil, positional parameters ($1) are not tags
+func dollarQuoteTag(runes []rune, start int) []rune {
+	for i := start + 1; i < len(runes); i++ {
+		aChar := runes[i]
+		switch {
+		case aChar == '$':
+			return runes[start : i+1]
+		case aChar == '_' || unicode.IsLetter(aChar) || (i > start+1 && unicode.IsDigit(aChar)):
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+//indexRunes returns index of passed in fragment at or after from index, or index past the last rune with fragment length subtracted if fragment is missing
+func indexRunes(runes []rune, from int, fragment []rune) int {
+	for i := from; i+len(fragment) <= len(runes); i++ {
+		if string(runes[i:i+len(fragment)]) == string(fragment) {
+			return i
+		}
+	}
+	return len(runes) - len(fragment)
+}
+
+//LoadMigrations loads migrations ordered by version from passed in directory or URL, files are named <version>_<name>.up.sql and <version>_<name>.down.sql
+func LoadMigrations(URL, credentials string) ([]*Migration, error) {
+	resource := url.NewResource(URL)
+	service, err := storage.NewServiceForURL(resource.URL, credentials)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := service.List(resource.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations %v due to %w", URL, err)
+	}
+	var scripts = make(map[int64]map[string]string)
+	var names = make(map[int64]string)
+	for _, object := range objects {
+		if object.IsFolder() {
+			continue
+		}
+		_, name := path.Split(object.URL())
+		matched := migrationFilePattern.FindStringSubmatch(name)
+		if len(matched) == 0 {
+			continue
+		}
+		version := toolbox.AsInt(matched[1])
+		if previous, ok := names[int64(version)]; ok && previous != matched[2] {
+			return nil, fmt.Errorf("duplicate migration version %v: %v, %v", version, previous, matched[2])
+		}
+		text, err := storage.DownloadText(service, object.URL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load migration %v due to %w", name, err)
+		}
+		direction := strings.TrimPrefix(matched[3], ".")
+		if direction == "" {
+			direction = "up"
+		}
+		if scripts[int64(version)] == nil {
+			scripts[int64(version)] = make(map[string]string)
+		}
+		scripts[int64(version)][direction] = text
+		names[int64(version)] = matched[2]
+	}
+	var result = make([]*Migration, 0, len(scripts))
+	for version, script := range scripts {
+		if _, ok := script["up"]; !ok {
+			return nil, fmt.Errorf("up script was missing for migration %v_%v", version, names[version])
+		}
+		result = append(result, NewMigration(version, names[version], script["up"], script["down"]))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+	return result, nil
+}
+
+//Migrator represents schema migration runner, applied versions with checksums are tracked in Table, LockTable record guards against concurrent runs.
+//With DryRun, migrations to be applied or rolled back are returned without being executed.
+type Migrator struct {
+	Table       string
+	LockTable   string
+	LockTimeout time.Duration
+	DryRun      bool
+	manager     Manager
+	migrations  []*Migration
+}
+
+func (m *Migrator) dialect() DatastoreDialect {
+	return GetDatastoreDialect(m.manager.Config().DriverName)
+}
+
+func (m *Migrator) log(message string, migration *Migration) {
+	logger := m.manager.Config().logger()
+	if logger.Enabled(context.Background(), slog.LevelInfo) {
+		logger.Log(context.Background(), slog.LevelInfo, message, "version", migration.Version, "name", migration.Name, "dryRun", m.DryRun)
+	}
+}
+
+//hasTable returns true if table exists in current datastore
+func (m *Migrator) hasTable(table string) (bool, error) {
+	dialect := m.dialect()
+	datastore, err := dialect.GetCurrentDatastore(m.manager)
+	if err != nil {
+		return false, err
+	}
+	tables, err := dialect.GetTables(m.manager, datastore)
+	if err != nil {
+		return false, err
+	}
+	for _, candidate := range tables {
+		if strings.EqualFold(candidate, table) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//createTableIfNeeded creates table for passed in instance, table created meanwhile by concurrent run is not an error
+func (m *Migrator) createTableIfNeeded(table string, instance interface{}) error {
+	if has, err := m.hasTable(table); err != nil || has {
+		return err
+	}
+	err := m.dialect().CreateTable(m.manager, "", table, instance)
+	if err != nil {
+		if has, _ := m.hasTable(table); has {
+			return nil
+		}
+	}
+	return err
+}
+
+//Applied returns applied migration records ordered by version
+func (m *Migrator) Applied() ([]*MigrationRecord, error) {
+	var result = make([]*MigrationRecord, 0)
+	if has, err := m.hasTable(m.Table); err != nil || !has {
+		return result, err
+	}
+	err := m.manager.ReadAll(&result, fmt.Sprintf("SELECT version, name, checksum, applied_at FROM %v ORDER BY version", m.Table), nil, nil)
+	return result, err
+}
+
+//Pending returns migrations not applied yet, it fails if applied migration script was modified
+func (m *Migrator) Pending() ([]*Migration, error) {
+	applied, err := m.appliedByVersion()
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*Migration, 0)
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if !ok {
+			result = append(result, migration)
+			continue
+		}
+		if record.Checksum != migration.Checksum {
+			return nil, fmt.Errorf("checksum mismatch for applied migration %v_%v, applied: %v, current: %v", migration.Version, migration.Name, record.Checksum, migration.Checksum)
+		}
+	}
+	return result, nil
+}
+
+func (m *Migrator) appliedByVersion() (map[int64]*MigrationRecord, error) {
+	records, err := m.Applied()
+	if err != nil {
+		return nil, err
+	}
+	var result = make(map[int64]*MigrationRecord)
+	for _, record := range records {
+		result[record.Version] = record
+	}
+	return result, nil
+}
+
+//Up applies all pending migrations in version order, it returns applied (or to be applied with DryRun) migrations
+func (m *Migrator) Up() ([]*Migration, error) {
+	return m.UpTo(0)
+}
+
+//UpTo applies pending migrations up to passed in version inclusive, 0 applies all pending migrations
+func (m *Migrator) UpTo(version int64) ([]*Migration, error) {
+	var result = make([]*Migration, 0)
+	err := m.withLock(func() error {
+		pending, err := m.Pending()
+		if err != nil {
+			return err
+		}
+		for _, migration := range pending {
+			if version > 0 && migration.Version > version {
+				break
+			}
+			m.log("dsc: applying migration", migration)
+			if !m.DryRun {
+				record := &MigrationRecord{Version: migration.Version, Name: migration.Name, Checksum: migration.Checksum, AppliedAt: time.Now()}
+				insertSQL := fmt.Sprintf("INSERT INTO %v(version, name, checksum, applied_at) VALUES(?, ?, ?, ?)", m.Table)
+				if err = m.run(migration.Up, insertSQL, record.Version, record.Name, record.Checksum, record.AppliedAt); err != nil {
+					return fmt.Errorf("failed to apply migration %v_%v due to %w", migration.Version, migration.Name, err)
+				}
+			}
+			result = append(result, migration)
+		}
+		return nil
+	})
+	return result, err
+}
+
+//Rollback runs down scripts of applied migrations with version greater than target version in reverse order, it returns rolled back (or to be rolled back with DryRun) migrations
+func (m *Migrator) Rollback(target int64) ([]*Migration, error) {
+	var result = make([]*Migration, 0)
+	err := m.withLock(func() error {
+		applied, err := m.appliedByVersion()
+		if err != nil {
+			return err
+		}
+		var byVersion = make(map[int64]*Migration)
+		for _, migration := range m.migrations {
+			byVersion[migration.Version] = migration
+		}
+		var versions = make([]int64, 0)
+		for version := range applied {
+			if version > target {
+				versions = append(versions, version)
+			}
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i] > versions[j]
+		})
+		for _, version := range versions {
+			migration, ok := byVersion[version]
+			if !ok || len(migration.Down) == 0 {
+				return fmt.Errorf("down script was missing for applied migration %v_%v", version, applied[version].Name)
+			}
+			m.log("dsc: rolling back migration", migration)
+			if !m.DryRun {
+				deleteSQL := fmt.Sprintf("DELETE FROM %v WHERE version = ?", m.Table)
+				if err = m.run(migration.Down, deleteSQL, version); err != nil {
+					return fmt.Errorf("failed to roll back migration %v_%v due to %w", migration.Version, migration.Name, err)
+				}
+			}
+			result = append(result, migration)
+		}
+		return nil
+	})
+	return result, err
+}
+
+//run executes migration statements followed by tracking statement, in one transaction if dialect can handle transaction,
+//migration is never retried since DDL might have been committed implicitly
+func (m *Migrator) run(statements []string, trackingSQL string, trackingParameters ...interface{}) error {
+	execute := func(connection Connection) error {
+		for _, statement := range statements {
+			if _, err := m.manager.ExecuteOnConnection(connection, statement, nil); err != nil {
+				return err
+			}
+		}
+		_, err := m.manager.ExecuteOnConnection(connection, trackingSQL, trackingParameters)
+		return err
+	}
+	connection, err := m.manager.ConnectionProvider().Get()
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	if m.dialect().CanHandleTransaction() {
+		return m.manager.WithTransactionOnConnection(connection, execute)
+	}
+	return execute(connection)
+}
+
+//withLock creates tracking tables if needed, then runs handler holding migration lock, dry run neither creates tables nor takes lock
+func (m *Migrator) withLock(handler func() error) error {
+	if m.DryRun {
+		return handler()
+	}
+	if err := m.createTableIfNeeded(m.Table, &MigrationRecord{}); err != nil {
+		return err
+	}
+	if err := m.createTableIfNeeded(m.LockTable, &migrationLock{}); err != nil {
+		return err
+	}
+	lockSQL := fmt.Sprintf("INSERT INTO %v(id, locked_at) VALUES(?, ?)", m.LockTable)
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		_, err := m.manager.Execute(lockSQL, migrationLockID, time.Now())
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, ErrDuplicateKey) {
+			return fmt.Errorf("failed to acquire migration lock due to %w", err)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w, use Unlock if it was left by interrupted run", ErrMigrationLocked)
+		}
+		time.Sleep(migrationLockRetryDelay)
+	}
+	defer m.Unlock()
+	return handler()
+}
+
+//Unlock releases migration lock, it can be used to remove lock left by interrupted run
+func (m *Migrator) Unlock() error {
+	_, err := m.manager.Execute(fmt.Sprintf("DELETE FROM %v WHERE id = ?", m.LockTable), migrationLockID)
+	return err
+}
+
+//NewMigrator creates a new migrator for passed in manager and migrations
+func NewMigrator(manager Manager, migrations ...*Migration) *Migrator {
+	var sorted = append([]*Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return &Migrator{
+		Table:       DefaultMigrationTable,
+		LockTable:   DefaultMigrationLockTable,
+		LockTimeout: defaultMigrationLockTimeout,
+		manager:     manager,
+		migrations:  sorted,
+	}
+}
+
+//NewMigratorFromURL creates a new migrator for passed in manager and migrations loaded from directory or URL, manager config credentials are used to access URL
+func NewMigratorFromURL(manager Manager, URL string) (*Migrator, error) {
+	migrations, err := LoadMigrations(URL, manager.Config().Credentials)
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrator(manager, migrations...), nil
+}
@@ -0,0 +1,103 @@
+package dsc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestMigrator(t *testing.T) {
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/migration.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	for _, table := range []string{"mig_users", "mig_orders", dsc.DefaultMigrationTable, dsc.DefaultMigrationLockTable} {
+		_, _ = manager.Execute("DROP TABLE IF EXISTS " + table)
+	}
+
+	migrator, err := dsc.NewMigratorFromURL(manager, "test/migrations")
+	if !assert.Nil(t, err) {
+		return
+	}
+	migrator.DryRun = true
+	planned, err := migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(planned))
+	if assert.Equal(t, 2, len(planned[0].Up)) {
+		assert.Equal(t, "INSERT INTO mig_users(id, name) VALUES(1, 'a;b')", planned[0].Up[1])
+	}
+	applied, err := migrator.Applied()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(applied))
+
+	migrator.DryRun = false
+	executed, err := migrator.UpTo(2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(executed))
+	executed, err = migrator.Up()
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(executed)) {
+		assert.EqualValues(t, 3, executed[0].Version)
+	}
+	applied, err = migrator.Applied()
+	assert.Nil(t, err)
+	if assert.Equal(t, 3, len(applied)) {
+		assert.Equal(t, "add_email", applied[1].Name)
+		assert.Equal(t, executed[0].Checksum, applied[2].Checksum)
+	}
+
+	_, err = migrator.Rollback(1) //migration 3 has no down script
+	assert.NotNil(t, err)
+	_, err = manager.Execute("DELETE FROM " + dsc.DefaultMigrationTable + " WHERE version = 3")
+	assert.Nil(t, err)
+	_, err = manager.Execute("DROP TABLE mig_orders")
+	assert.Nil(t, err)
+	rolledBack, err := migrator.Rollback(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(rolledBack))
+	var user = struct {
+		Id    int
+		Name  string
+		Email string
+	}{}
+	_, err = manager.ReadSingle(&user, "SELECT id, name, email FROM mig_users WHERE id = ?", []interface{}{1}, nil)
+	assert.NotNil(t, err, "email column was removed")
+
+	modified := dsc.NewMigration(1, "create_users", "CREATE TABLE mig_users (id INTEGER)", "")
+	_, err = dsc.NewMigrator(manager, modified).Up()
+	assert.NotNil(t, err, "checksum mismatch")
+
+	_, err = manager.Execute("INSERT INTO "+dsc.DefaultMigrationLockTable+"(id, locked_at) VALUES(?, ?)", 1, "2024-01-01")
+	assert.Nil(t, err)
+	migrator.LockTimeout = 0
+	_, err = migrator.Up()
+	assert.True(t, errors.Is(err, dsc.ErrMigrationLocked))
+	assert.Nil(t, migrator.Unlock())
+	executed, err = migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(executed))
+}
+
+func TestNewMigration_Split(t *testing.T) {
+	up := `CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+	NEW.updated := now(); -- set update time
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+/* trigger; keeps updated column */
+CREATE TRIGGER touch BEFORE UPDATE ON users FOR EACH ROW EXECUTE PROCEDURE touch();
+CREATE FUNCTION label() RETURNS text AS $body$ SELECT 'a;b' || $1 $body$ LANGUAGE sql;
+SELECT $1, $2 FROM users`
+	migration := dsc.NewMigration(1, "touch", up, "")
+	if assert.Equal(t, 4, len(migration.Up)) {
+		assert.Equal(t, "CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n\tNEW.updated := now(); -- set update time\n\tRETURN NEW;\nEND;\n$$ LANGUAGE plpgsql", migration.Up[0])
+		assert.Equal(t, "/* trigger; keeps updated column */\nCREATE TRIGGER touch BEFORE UPDATE ON users FOR EACH ROW EXECUTE PROCEDURE touch()", migration.Up[1])
+		assert.Equal(t, "CREATE FUNCTION label() RETURNS text AS $body$ SELECT 'a;b' || $1 $body$ LANGUAGE sql", migration.Up[2])
+		assert.Equal(t, "SELECT $1, $2 FROM users", migration.Up[3])
+	}
+	assert.Equal(t, 0, len(migration.Down))
+}
@@ -0,0 +1,404 @@
+package dsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	//PoolBoundedKey represents a config parameter enabling MaxPoolSize hard cap, by default a new connection is opened when no pooled connection is released within 100 ms
+	PoolBoundedKey = "poolBounded"
+	//PoolAcquireTimeoutMsKey represents a config parameter for max time to wait for a pooled connection when pool is bounded, default 30000
+	PoolAcquireTimeoutMsKey = "poolAcquireTimeoutMs"
+	//PoolIdleTimeoutMsKey represents a config parameter for max idle time of a pooled connection, 0 disables idle eviction
+	PoolIdleTimeoutMsKey = "poolIdleTimeoutMs"
+	//PoolMaxLifetimeMsKey represents a config parameter for max lifetime of a pooled connection, 0 disables lifetime eviction
+	PoolMaxLifetimeMsKey = "poolMaxLifetimeMs"
+	//PoolHealthCheckMsKey represents a config parameter for background health check frequency, 0 disables health checks
+	PoolHealthCheckMsKey = "poolHealthCheckMs"
+
+	defaultPoolAcquireTimeoutMs = 30000
+	defaultPoolEvictionMs       = 30000
+	poolPingTimeout             = 5 * time.Second
+	poolOverflowWait            = 100 * time.Millisecond
+)
+
+var errPoolClosed = errors.New("connection pool was closed")
+
+//PoolStats represents connection pool statistics
+type PoolStats struct {
+	MaxSize  int           //max number of open connections
+	Open     int           //number of open connections, both in use and idle
+	InUse    int           //number of connections currently in use
+	Idle     int           //number of idle connections
+	Waits    int64         //total number of acquires that had to wait for a connection
+	WaitTime time.Duration //total time spent waiting for a connection
+	Timeouts int64         //total number of acquires that timed out
+	Created  int64         //total number of created connections
+	Closed   int64         //total number of closed connections
+}
+
+//PooledConnectionProvider represents a connection provider exposing pool statistics
+type PooledConnectionProvider interface {
+	ConnectionProvider
+
+	//PoolStats returns connection pool statistics
+	PoolStats() PoolStats
+}
+
+//pooledConnection represents a connection tracked by connection pool
+type pooledConnection interface {
+	setPool(pool *connectionPool, created time.Time)
+
+	createdAt() time.Time
+}
+
+//pingableConnection represents a connection that can be checked by pool health checker
+type pingableConnection interface {
+	ping(ctx context.Context) error
+}
+
+//connectionPool tracks open connections for a connection provider idle channel
+type connectionPool struct {
+	mux            *sync.Mutex
+	idle           chan Connection
+	maxSize        int
+	bounded        bool
+	acquireTimeout time.Duration
+	idleTimeout    time.Duration
+	maxLifetime    time.Duration
+	healthCheck    time.Duration
+	open           int
+	waits          int64
+	waitTime       time.Duration
+	timeouts       int64
+	created        int64
+	closed         int64
+	isClosed       bool
+	freed          chan struct{}
+	stop           chan struct{}
+	logger         Logger
+}
+
+//reserve reserves a slot for a new connection, it returns false if pool reached max size
+func (p *connectionPool) reserve() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.open >= p.maxSize {
+		return false
+	}
+	p.open++
+	return true
+}
+
+//reserveOverflow reserves a slot for a connection exceeding max size of unbounded pool
+func (p *connectionPool) reserveOverflow() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.open++
+}
+
+//unreserve releases slot reserved for a connection that failed to open
+func (p *connectionPool) unreserve() {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.open--
+	p.notifyFreed()
+}
+
+//track registers newly created connection with the pool
+func (p *connectionPool) track(connection Connection) {
+	if member, ok := connection.(pooledConnection); ok {
+		member.setPool(p, time.Now())
+	}
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.created++
+}
+
+func (p *connectionPool) notifyFreed() {
+	close(p.freed)
+	p.freed = make(chan struct{})
+}
+
+func (p *connectionPool) freedChannel() chan struct{} {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.freed
+}
+
+//discard closes connection and releases its pool slot
+func (p *connectionPool) discard(connection Connection) error {
+	err := connection.CloseNow()
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.open--
+	p.closed++
+	p.notifyFreed()
+	return err
+}
+
+//expired returns true if connection exceeded idle timeout or max lifetime
+func (p *connectionPool) expired(connection Connection) bool {
+	now := time.Now()
+	if p.maxLifetime > 0 {
+		if member, ok := connection.(pooledConnection); ok && now.Sub(member.createdAt()) > p.maxLifetime {
+			return true
+		}
+	}
+	if p.idleTimeout > 0 {
+		if lastUsed := connection.LastUsed(); lastUsed != nil && now.Sub(*lastUsed) > p.idleTimeout {
+			return true
+		}
+	}
+	return false
+}
+
+//release returns connection to the idle channel, or closes it if pool was closed, it expired or channel is full
+func (p *connectionPool) release(connection Connection) error {
+	var ts = time.Now()
+	connection.SetLastUsed(&ts)
+	if p.expired(connection) {
+		return p.discard(connection)
+	}
+	p.mux.Lock()
+	returned := false
+	if !p.isClosed { //idle channel is written under lock, so that close drains every connection released before it was closed
+		select {
+		case p.idle <- connection:
+			returned = true
+		default:
+		}
+	}
+	p.mux.Unlock()
+	if returned {
+		return nil
+	}
+	return p.discard(connection)
+}
+
+//wasClosed returns true if pool was closed
+func (p *connectionPool) wasClosed() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.isClosed
+}
+
+//recordWait records time spent waiting for a connection
+func (p *connectionPool) recordWait(startTime time.Time, timeout bool) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	p.waits++
+	p.waitTime += time.Now().Sub(startTime)
+	if timeout {
+		p.timeouts++
+	}
+}
+
+//stats returns pool statistics
+func (p *connectionPool) stats() PoolStats {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	idle := len(p.idle)
+	inUse := p.open - idle
+	if inUse < 0 {
+		inUse = 0
+	}
+	return PoolStats{
+		MaxSize:  p.maxSize,
+		Open:     p.open,
+		InUse:    inUse,
+		Idle:     idle,
+		Waits:    p.waits,
+		WaitTime: p.waitTime,
+		Timeouts: p.timeouts,
+		Created:  p.created,
+		Closed:   p.closed,
+	}
+}
+
+//acquire takes idle connection or creates a new one if pool has not reached max size, otherwise it waits till connection is released, context is done or acquire timeout elapsed,
+//unbounded pool opens a new connection if none was released within overflow wait time
+func (p *connectionPool) acquire(ctx context.Context, provider ConnectionProvider) (Connection, error) {
+	var startTime time.Time
+	var timer *time.Timer
+	var overflow bool
+	for {
+		freed := p.freedChannel()
+		select {
+		case result := <-p.idle:
+			if p.expired(result) {
+				_ = p.discard(result)
+				continue
+			}
+			if timer != nil {
+				timer.Stop()
+				p.recordWait(startTime, false)
+			}
+			return result, nil
+		default:
+		}
+		if reserved := p.reserve(); reserved || overflow {
+			if !reserved {
+				p.reserveOverflow()
+			}
+			result, err := provider.NewConnection()
+			if err != nil {
+				p.unreserve()
+				return nil, err
+			}
+			p.track(result)
+			if timer != nil {
+				timer.Stop()
+				p.recordWait(startTime, false)
+			}
+			return result, nil
+		}
+		if timer == nil {
+			startTime = time.Now()
+			timeout := p.acquireTimeout
+			if !p.bounded {
+				timeout = poolOverflowWait
+			}
+			timer = time.NewTimer(timeout)
+		}
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			p.recordWait(startTime, false)
+			return nil, ctx.Err()
+		case <-timer.C:
+			if !p.bounded {
+				overflow = true
+				continue
+			}
+			p.recordWait(startTime, true)
+			return nil, fmt.Errorf("failed to acquire connection: timeout after %v, pool max size: %v", p.acquireTimeout, p.maxSize)
+		case result := <-p.idle:
+			if p.expired(result) {
+				_ = p.discard(result)
+				continue
+			}
+			timer.Stop()
+			p.recordWait(startTime, false)
+			return result, nil
+		case <-freed:
+		}
+	}
+}
+
+//startHealthCheckIfNeeded starts background health checker if eviction or health check was configured
+func (p *connectionPool) startHealthCheckIfNeeded(provider ConnectionProvider) {
+	frequency := p.healthCheck
+	if frequency == 0 && (p.idleTimeout > 0 || p.maxLifetime > 0) {
+		frequency = defaultPoolEvictionMs * time.Millisecond
+	}
+	if frequency == 0 {
+		return
+	}
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	p.stop = stop
+	go func() {
+		ticker := time.NewTicker(frequency)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.checkIdle()
+				provider.SpawnConnectionIfNeeded()
+			}
+		}
+	}()
+}
+
+//checkIdle evicts expired or unhealthy idle connections
+func (p *connectionPool) checkIdle() {
+	count := len(p.idle)
+	for i := 0; i < count; i++ {
+		var connection Connection
+		select {
+		case connection = <-p.idle:
+		default:
+			return
+		}
+		if p.expired(connection) {
+			_ = p.discard(connection)
+			continue
+		}
+		if pingable, ok := connection.(pingableConnection); ok && p.healthCheck > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), poolPingTimeout)
+			err := pingable.ping(ctx)
+			cancel()
+			if err != nil {
+				p.logger.Log(context.Background(), slog.LevelWarn, "dsc: evicting unhealthy connection", "error", err)
+				_ = p.discard(connection)
+				continue
+			}
+		}
+		select {
+		case p.idle <- connection:
+		default:
+			_ = p.discard(connection)
+		}
+	}
+}
+
+//close stops health checker and closes all idle connections, connections released later are closed by release
+func (p *connectionPool) close() error {
+	p.mux.Lock()
+	p.isClosed = true
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+	p.mux.Unlock()
+	var err error
+	for {
+		select {
+		case connection := <-p.idle:
+			if closeErr := p.discard(connection); closeErr != nil {
+				err = closeErr
+			}
+		default:
+			return err
+		}
+	}
+}
+
+func newConnectionPool(config *Config, idle chan Connection) *connectionPool {
+	maxSize := config.MaxPoolSize
+	if maxSize < config.PoolSize {
+		maxSize = config.PoolSize
+	}
+	if maxSize == 0 {
+		maxSize = 1
+	}
+	result := &connectionPool{
+		mux:     &sync.Mutex{},
+		idle:    idle,
+		maxSize: maxSize,
+		freed:   make(chan struct{}),
+		logger:  config.logger(),
+	}
+	config.initLock()
+	result.bounded = config.GetBoolean(PoolBoundedKey, false)
+	result.acquireTimeout = config.GetDuration(PoolAcquireTimeoutMsKey, time.Millisecond, defaultPoolAcquireTimeoutMs*time.Millisecond)
+	result.idleTimeout = config.GetDuration(PoolIdleTimeoutMsKey, time.Millisecond, 0)
+	result.maxLifetime = config.GetDuration(PoolMaxLifetimeMsKey, time.Millisecond, 0)
+	result.healthCheck = config.GetDuration(PoolHealthCheckMsKey, time.Millisecond, 0)
+	if result.acquireTimeout <= 0 {
+		result.acquireTimeout = defaultPoolAcquireTimeoutMs * time.Millisecond
+	}
+	return result
+}
@@ -14,6 +14,7 @@ type QueryBuilder struct {
 	QueryHint       string
 	TableDescriptor *TableDescriptor
 	reserved        *Reserved
+	includeDeleted  bool
 }
 
 // BuildQueryAll builds query all data without where clause
@@ -25,8 +26,12 @@ func (qb *QueryBuilder) BuildQueryAll(columns []string) *ParametrizedSQL {
 	}
 	var columnsLiteral = qb.QueryHint + " " + strings.Join(columns, ",")
 	table := qb.TableDescriptor.From()
+	SQL := fmt.Sprintf(queryAllSQLTemplate, columnsLiteral, table)
+	if criterion := qb.notDeletedCriterion(); criterion != "" {
+		SQL += " WHERE " + criterion
+	}
 	return &ParametrizedSQL{
-		SQL:    fmt.Sprintf(queryAllSQLTemplate, columnsLiteral, table),
+		SQL:    SQL,
 		Values: make([]interface{}, 0),
 	}
 
@@ -51,18 +56,51 @@ func (qb *QueryBuilder) BuildQueryWithInColumns(columns []string, inCriteriaColu
 	} else {
 		updateReserved(inCriteriaColumns)
 	}
+	whereCriteria, sqlArguments := buildInCriteria(inCriteriaColumns, pkRowValues, true)
+	if criterion := qb.notDeletedCriterion(); criterion != "" {
+		whereCriteria += " AND " + criterion
+	}
+	table := qb.TableDescriptor.From()
+	return &ParametrizedSQL{
+		SQL:    fmt.Sprintf(querySQLTemplate, columnsLiteral, table, whereCriteria),
+		Values: sqlArguments,
+	}
+
+}
+
+// notDeletedCriterion returns criterion excluding soft deleted rows, or empty string
+func (qb *QueryBuilder) notDeletedCriterion() string {
+	if qb.includeDeleted || qb.TableDescriptor.SoftDeleteColumn == "" {
+		return ""
+	}
+	return quoteColumn(qb.TableDescriptor.SoftDeleteColumn, qb.reserved) + " IS NULL"
+}
+
+// buildInCriteria returns IN criteria with arguments for passed in columns and row values, multi column values use tuple IN,
+// or OR-ed key predicates if rowValueIn is false.
+func buildInCriteria(inCriteriaColumns []string, rowValues [][]interface{}, rowValueIn bool) (string, []interface{}) {
 	var inColumns = strings.Join(inCriteriaColumns, ",")
 	var sqlArguments = make([]interface{}, 0)
 	var criteria = ""
 	var multiValuePk = false
-	for _, pkValues := range pkRowValues {
-		if len(pkValues) > 1 {
+	for _, values := range rowValues {
+		if len(values) > 1 {
 			multiValuePk = true
 		}
-		var rowCriteria = strings.Repeat("?,", len(pkValues))
+	}
+	if multiValuePk && !rowValueIn {
+		keyCriteria := "(" + strings.TrimPrefix(buildAssignValueSQL(inCriteriaColumns, " AND"), " ") + ")"
+		var keysCriteria = make([]string, len(rowValues))
+		for i, values := range rowValues {
+			keysCriteria[i] = keyCriteria
+			sqlArguments = append(sqlArguments, values...)
+		}
+		return "(" + strings.Join(keysCriteria, " OR ") + ")", sqlArguments
+	}
+	for _, values := range rowValues {
+		var rowCriteria = strings.Repeat("?,", len(values))
 		rowCriteria = rowCriteria[0 : len(rowCriteria)-1]
-
-		sqlArguments = append(sqlArguments, pkValues...)
+		sqlArguments = append(sqlArguments, values...)
 		if len(criteria) > 0 {
 			criteria = criteria + ","
 		}
@@ -72,17 +110,10 @@ func (qb *QueryBuilder) BuildQueryWithInColumns(columns []string, inCriteriaColu
 			criteria = criteria + rowCriteria
 		}
 	}
-
-	var whereCriteria = inColumns + " IN (" + criteria + ")"
 	if multiValuePk {
-		whereCriteria = "(" + inColumns + ") IN (" + criteria + ")"
+		return "(" + inColumns + ") IN (" + criteria + ")", sqlArguments
 	}
-	table := qb.TableDescriptor.From()
-	return &ParametrizedSQL{
-		SQL:    fmt.Sprintf(querySQLTemplate, columnsLiteral, table, whereCriteria),
-		Values: sqlArguments,
-	}
-
+	return inColumns + " IN (" + criteria + ")", sqlArguments
 }
 
 // BuildBatchedQueryOnPk builds batches of ParametrizedSQL for passed in query columns and pk values. Batch size specifies number of rows in one parametrized sql.
@@ -121,3 +152,9 @@ func (qb QueryBuilder) WithReserved(res *Reserved) QueryBuilder {
 func (qb QueryBuilder) WithKeywords(keywords []string) QueryBuilder {
 	return qb.WithReserved(NewReservedFromKeywords(keywords))
 }
+
+// WithDeleted returns a copy of QueryBuilder including soft deleted rows
+func (qb QueryBuilder) WithDeleted() QueryBuilder {
+	qb.includeDeleted = true
+	return qb
+}
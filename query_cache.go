@@ -0,0 +1,344 @@
+package dsc
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//QueryCacheTTLMsKey represents a config parameter with query result cache time to live, 0 disables query cache
+	QueryCacheTTLMsKey = "queryCacheTtlMs"
+	//QueryCacheSizeKey represents a config parameter with max number of cached query results of default LRU store
+	QueryCacheSizeKey = "queryCacheSize"
+
+	defaultQueryCacheSize = 1000
+	//queryCacheVersionKeyPrefix prefixes store keys holding table versions
+	queryCacheVersionKeyPrefix = "dsc:tableVersion:"
+	//minQueryCacheVersionTTL represents min time to live of table version, expired version only makes results cached with it stale
+	minQueryCacheVersionTTL = time.Hour
+)
+
+//QueryCacheStore represents query result cache store, it keeps both cached results and table versions,
+//so that managers sharing the store invalidate each other results. Cached results hold Go values, thus store has to keep them in process memory.
+type QueryCacheStore interface {
+	//Get returns cached value, or false if value is missing or expired
+	Get(key string) (interface{}, bool)
+	//Put stores value for passed in time to live
+	Put(key string, value interface{}, ttl time.Duration)
+	//Delete removes value
+	Delete(key string)
+	//Clear removes all values
+	Clear()
+}
+
+type lruEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+//lruQueryCacheStore represents in-process least recently used query cache store
+type lruQueryCacheStore struct {
+	mutex      *sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+func (s *lruQueryCacheStore) Get(key string) (interface{}, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (s *lruQueryCacheStore) Put(key string, value interface{}, ttl time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, value: value, expires: time.Now().Add(ttl)})
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (s *lruQueryCacheStore) Delete(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+}
+
+func (s *lruQueryCacheStore) Clear() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = make(map[string]*list.Element)
+	s.order.Init()
+}
+
+//NewLRUQueryCacheStore creates a new in-process LRU query cache store holding up to maxEntries values
+func NewLRUQueryCacheStore(maxEntries int) QueryCacheStore {
+	return &lruQueryCacheStore{
+		mutex:      &sync.Mutex{},
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+//cachedResult represents cached query result with versions of queried tables at read time
+type cachedResult struct {
+	value    reflect.Value
+	success  bool
+	versions []string
+}
+
+//queryCache represents query result cache, table version kept in store is replaced by any write to the table, results read with other table version are stale
+type queryCache struct {
+	store      QueryCacheStore
+	ttl        time.Duration
+	versionTTL time.Duration
+}
+
+//newTableVersion returns random table version, unlike counter it is never reused once version was evicted from store
+func newTableVersion() string {
+	var version = make([]byte, 8)
+	if _, err := rand.Read(version); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(version)
+}
+
+//tableVersion returns table version from store, missing version is initialized
+func (c *queryCache) tableVersion(table string) string {
+	key := queryCacheVersionKeyPrefix + table
+	if value, ok := c.store.Get(key); ok {
+		if version, ok := value.(string); ok {
+			return version
+		}
+	}
+	version := newTableVersion()
+	c.store.Put(key, version, c.versionTTL)
+	return version
+}
+
+func (c *queryCache) tableVersions(tables []string) []string {
+	var result = make([]string, len(tables)+1)
+	for i, table := range tables {
+		result[i] = c.tableVersion(table)
+	}
+	result[len(tables)] = c.tableVersion("")
+	return result
+}
+
+func (c *queryCache) get(key string, tables []string) (*cachedResult, bool) {
+	value, ok := c.store.Get(key)
+	if !ok {
+		return nil, false
+	}
+	result, ok := value.(*cachedResult)
+	if !ok || !reflect.DeepEqual(result.versions, c.tableVersions(tables)) {
+		return nil, false
+	}
+	return result, true
+}
+
+//invalidate replaces table version in store, empty table invalidates all cached results
+func (c *queryCache) invalidate(table string) {
+	c.store.Put(queryCacheVersionKeyPrefix+strings.ToLower(table), newTableVersion(), c.versionTTL)
+	if table == "" {
+		c.store.Clear()
+	}
+}
+
+//newQueryCache creates query cache from config, or returns nil if query cache is disabled
+func newQueryCache(config *Config) *queryCache {
+	config.initLock()
+	ttl := config.GetDuration(QueryCacheTTLMsKey, time.Millisecond, 0)
+	if ttl <= 0 {
+		return nil
+	}
+	store := config.QueryCacheStore
+	if store == nil {
+		store = NewLRUQueryCacheStore(config.GetInt(QueryCacheSizeKey, defaultQueryCacheSize))
+	}
+	versionTTL := minQueryCacheVersionTTL
+	if versionTTL < ttl {
+		versionTTL = ttl
+	}
+	return &queryCache{store: store, ttl: ttl, versionTTL: versionTTL}
+}
+
+//queryTables returns lower case tables of cacheable query, queries that can not be parsed or use JOIN are not cacheable
+func queryTables(query string) ([]string, bool) {
+	if strings.Contains(strings.ToUpper(query), " JOIN ") {
+		return nil, false
+	}
+	statement, err := NewQueryParser().Parse(query)
+	if err != nil || statement.Table == "" {
+		return nil, false
+	}
+	var result = []string{strings.ToLower(statement.Table)}
+	for _, table := range statement.UnionTables {
+		result = append(result, strings.ToLower(table))
+	}
+	return result, true
+}
+
+//queryCacheKey returns cache key for normalized query, parameters, result and mapper type
+func queryCacheKey(query string, parameters []interface{}, resultType reflect.Type, mapper RecordMapper) string {
+	var key = strings.Builder{}
+	key.WriteString(strings.Join(strings.Fields(query), " "))
+	for _, parameter := range parameters {
+		key.WriteString(fmt.Sprintf("|%T:%v", parameter, parameter))
+	}
+	key.WriteString(fmt.Sprintf("|%v|%T", resultType, mapper))
+	return key.String()
+}
+
+//copyCachedValue returns a copy of cached value, pointed structs are copied so that callers do not share cached instances
+func copyCachedValue(value reflect.Value) reflect.Value {
+	if value.Kind() == reflect.Ptr && !value.IsNil() && value.Elem().Kind() == reflect.Struct {
+		result := reflect.New(value.Type().Elem())
+		result.Elem().Set(value.Elem())
+		return result
+	}
+	return value
+}
+
+//readAllCached reads query result into result slice pointer with passed in read function, or appends cached result if available
+func (m *AbstractManager) readAllCached(ctx context.Context, resultSlicePointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper, read func(resultSlicePointer interface{}) error) error {
+	if m.queryCache == nil || isSkipCache(ctx) {
+		return read(resultSlicePointer)
+	}
+	tables, ok := queryTables(query)
+	if !ok {
+		return read(resultSlicePointer)
+	}
+	sliceType := reflect.TypeOf(resultSlicePointer).Elem()
+	key := queryCacheKey(query, queryParameters, sliceType, mapper)
+	slice := reflect.ValueOf(resultSlicePointer).Elem()
+	if cached, ok := m.queryCache.get(key, tables); ok {
+		for i := 0; i < cached.value.Len(); i++ {
+			slice.Set(reflect.Append(slice, copyCachedValue(cached.value.Index(i))))
+		}
+		return nil
+	}
+	versions := m.queryCache.tableVersions(tables)
+	fetched := reflect.New(sliceType)
+	if err := read(fetched.Interface()); err != nil {
+		return err
+	}
+	m.queryCache.store.Put(key, &cachedResult{value: fetched.Elem(), versions: versions}, m.queryCache.ttl)
+	for i := 0; i < fetched.Elem().Len(); i++ {
+		slice.Set(reflect.Append(slice, copyCachedValue(fetched.Elem().Index(i))))
+	}
+	return nil
+}
+
+//readSingleCached reads single row into result pointer with passed in read function, or sets cached result if available, slice and map results are not cached
+func (m *AbstractManager) readSingleCached(ctx context.Context, resultPointer interface{}, query string, queryParameters []interface{}, mapper RecordMapper, read func(resultPointer interface{}) (bool, error)) (bool, error) {
+	elementType := reflect.TypeOf(resultPointer).Elem()
+	if m.queryCache == nil || isSkipCache(ctx) || elementType.Kind() == reflect.Slice || elementType.Kind() == reflect.Map {
+		return read(resultPointer)
+	}
+	tables, ok := queryTables(query)
+	if !ok {
+		return read(resultPointer)
+	}
+	key := queryCacheKey(query, queryParameters, elementType, mapper)
+	if cached, ok := m.queryCache.get(key, tables); ok {
+		if cached.success {
+			reflect.ValueOf(resultPointer).Elem().Set(copyCachedValue(cached.value))
+		}
+		return cached.success, nil
+	}
+	versions := m.queryCache.tableVersions(tables)
+	fetched := reflect.New(elementType)
+	success, err := read(fetched.Interface())
+	if err != nil {
+		return false, err
+	}
+	m.queryCache.store.Put(key, &cachedResult{value: fetched.Elem(), success: success, versions: versions}, m.queryCache.ttl)
+	if success {
+		reflect.ValueOf(resultPointer).Elem().Set(copyCachedValue(fetched.Elem()))
+	}
+	return success, nil
+}
+
+//invalidateQueryCache invalidates cached results of table modified by passed in SQL, all results are invalidated if table can not be determined
+func (m *AbstractManager) invalidateQueryCache(ctx context.Context, connection Connection, SQL string) {
+	if m.queryCache == nil {
+		return
+	}
+	if hint := getOperationHint(ctx); hint != nil && hint.table != "" {
+		m.invalidateQueryCacheOnConnection(connection, hint.table)
+		return
+	}
+	if statement, err := NewDmlParser().Parse(SQL); err == nil && statement.Table != "" {
+		m.invalidateQueryCacheOnConnection(connection, statement.Table)
+		return
+	}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(SQL)), "SELECT") {
+		return
+	}
+	m.invalidateQueryCacheOnConnection(connection, "")
+}
+
+//invalidateQueryCacheOnConnection invalidates cached results of table modified on connection, if connection has an active transaction
+//table is invalidated again once transaction is committed or rolled back, so that results read before commit are not served afterwards
+func (m *AbstractManager) invalidateQueryCacheOnConnection(connection Connection, table string) {
+	if m.queryCache == nil {
+		return
+	}
+	m.queryCache.invalidate(table)
+	if transactional, ok := connection.(transactionalConnection); ok && transactional.inTransaction() {
+		transactional.afterTransaction(func(bool) {
+			m.queryCache.invalidate(table)
+		})
+	}
+}
+
+//InvalidateQueryCache invalidates cached query results of passed in table, or all cached results if table is empty
+func (m *AbstractManager) InvalidateQueryCache(table string) {
+	if m.queryCache != nil {
+		m.queryCache.invalidate(table)
+	}
+}
+
+type skipCacheKey struct{}
+
+//WithoutCache returns context making context reads bypass query cache
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCacheKey{}, true)
+}
+
+func isSkipCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipCacheKey{}).(bool)
+	return skip
+}
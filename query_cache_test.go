@@ -0,0 +1,137 @@
+package dsc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+	"github.com/viant/toolbox"
+)
+
+func TestLRUQueryCacheStore(t *testing.T) {
+	store := dsc.NewLRUQueryCacheStore(2)
+	store.Put("k1", 1, time.Minute)
+	store.Put("k2", 2, time.Minute)
+	_, ok := store.Get("k1")
+	assert.True(t, ok)
+	store.Put("k3", 3, time.Minute) //evicts least recently used k2
+	_, ok = store.Get("k2")
+	assert.False(t, ok)
+	value, ok := store.Get("k1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	store.Put("k4", 4, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	_, ok = store.Get("k4")
+	assert.False(t, ok)
+}
+
+func TestQueryCache(t *testing.T) {
+	cached, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/cache.db,queryCacheTtlMs:60000"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer cached.ConnectionProvider().Close()
+	direct, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/cache.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer direct.ConnectionProvider().Close()
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS products",
+		"CREATE TABLE products (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+		"INSERT INTO products(id, name) VALUES(1, 'p1')",
+	} {
+		_, err = cached.Execute(SQL)
+		assert.Nil(t, err)
+	}
+
+	type Product struct {
+		Id   int `primaryKey:"true"`
+		Name string
+	}
+	var readName = func() string {
+		var product = &Product{}
+		success, err := cached.ReadSingle(product, "SELECT id, name FROM products WHERE id = ?", []interface{}{1}, nil)
+		assert.Nil(t, err)
+		assert.True(t, success)
+		return product.Name
+	}
+	assert.Equal(t, "p1", readName())
+	_, err = direct.Execute("UPDATE products SET name = ? WHERE id = ?", "bypassed", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "p1", readName()) //served from cache
+
+	_, err = cached.Execute("UPDATE products SET name = ? WHERE id = ?", "updated", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "updated", readName())
+
+	err = cached.WithTransaction(func(connection dsc.Connection) error {
+		if _, err := cached.ExecuteOnConnection(connection, "UPDATE products SET name = ? WHERE id = ?", []interface{}{"committed", 1}); err != nil {
+			return err
+		}
+		assert.Equal(t, "updated", readName()) //uncommitted change is not visible, result is cached
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "committed", readName()) //invalidated again after commit
+
+	var products = make([]*Product, 0)
+	err = cached.ReadAll(&products, "SELECT id, name FROM products", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(products))
+	products[0].Name = "mutated" //callers do not share cached instances
+
+	_, _, err = cached.PersistAll(&[]*Product{{Id: 2, Name: "p2"}}, "products", nil)
+	assert.Nil(t, err)
+	products = make([]*Product, 0)
+	err = cached.ReadAll(&products, "SELECT id, name FROM products", nil, nil)
+	assert.Nil(t, err)
+	if assert.Equal(t, 2, len(products)) {
+		assert.Equal(t, "committed", products[0].Name)
+	}
+
+	_, err = cached.DeleteAll(&products, "products", nil)
+	assert.Nil(t, err)
+	products = make([]*Product, 0)
+	err = cached.ReadAll(&products, "SELECT id, name FROM products", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(products))
+}
+
+func TestQueryCache_SharedStore(t *testing.T) {
+	store := dsc.NewLRUQueryCacheStore(100)
+	var managers = make([]dsc.Manager, 2)
+	for i := range managers {
+		config := dsc.NewConfig("sqlite3", "[url]", "url:./test/cache.db,queryCacheTtlMs:60000")
+		config.QueryCacheStore = store
+		manager, err := dsc.NewManagerFactory().Create(config)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer manager.ConnectionProvider().Close()
+		managers[i] = manager
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS items",
+		"CREATE TABLE items (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+		"INSERT INTO items(id, name) VALUES(1, 'i1')",
+	} {
+		_, err := managers[0].Execute(SQL)
+		assert.Nil(t, err)
+	}
+	var readName = func() string {
+		var records = make([]map[string]interface{}, 0)
+		err := managers[0].ReadAll(&records, "SELECT id, name FROM items WHERE id = ?", []interface{}{1}, nil)
+		assert.Nil(t, err)
+		if !assert.Equal(t, 1, len(records)) {
+			return ""
+		}
+		return toolbox.AsString(records[0]["name"])
+	}
+	assert.Equal(t, "i1", readName())
+	_, err := managers[1].Execute("UPDATE items SET name = ? WHERE id = ?", "updated", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "updated", readName()) //write with other manager sharing store invalidates cached result
+}
@@ -0,0 +1,283 @@
+package dsc
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/viant/toolbox"
+)
+
+const (
+	//SortBufferSizeKey represents a config parameter for max number of records sorted in memory before spilling to a temp file, default 100000
+	SortBufferSizeKey     = "sortBufferSize"
+	defaultSortBufferSize = 100000
+)
+
+func init() {
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+	gob.Register(time.Time{})
+	gob.Register(json.Number(""))
+}
+
+//sortEntry represents sortable record
+type sortEntry struct {
+	Keys   []interface{}
+	Record map[string]interface{}
+	Seq    int64
+}
+
+//recordSorter sorts records with bounded memory, it keeps only top offset+limit records when limit is set,
+//otherwise it spills sorted runs to temp files and merges them
+type recordSorter struct {
+	descending []bool
+	limit      int
+	bufferSize int
+	seq        int64
+	entries    []*sortEntry
+	runs       []string
+}
+
+//less returns true if entry x sorts before y
+func (s *recordSorter) less(x, y *sortEntry) bool {
+	for i := range x.Keys {
+		compared := compareValues(x.Keys[i], y.Keys[i])
+		if compared == 0 {
+			continue
+		}
+		if s.descending[i] {
+			return compared > 0
+		}
+		return compared < 0
+	}
+	return x.Seq < y.Seq
+}
+
+//Len, Less, Swap, Push and Pop implement max heap used to keep top records
+func (s *recordSorter) Len() int           { return len(s.entries) }
+func (s *recordSorter) Less(i, j int) bool { return s.less(s.entries[j], s.entries[i]) }
+func (s *recordSorter) Swap(i, j int)      { s.entries[i], s.entries[j] = s.entries[j], s.entries[i] }
+func (s *recordSorter) Push(x interface{}) { s.entries = append(s.entries, x.(*sortEntry)) }
+func (s *recordSorter) Pop() interface{} {
+	last := s.entries[len(s.entries)-1]
+	s.entries = s.entries[:len(s.entries)-1]
+	return last
+}
+
+//add adds record with its sort keys
+func (s *recordSorter) add(keys []interface{}, record map[string]interface{}) error {
+	entry := &sortEntry{Keys: keys, Record: record, Seq: s.seq}
+	s.seq++
+	if s.limit > 0 {
+		if len(s.entries) < s.limit {
+			heap.Push(s, entry)
+		} else if s.less(entry, s.entries[0]) {
+			s.entries[0] = entry
+			heap.Fix(s, 0)
+		}
+		return nil
+	}
+	s.entries = append(s.entries, entry)
+	if len(s.entries) >= s.bufferSize {
+		return s.spill()
+	}
+	return nil
+}
+
+func (s *recordSorter) sort() {
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.less(s.entries[i], s.entries[j])
+	})
+}
+
+//spill writes sorted buffered records to a temp file
+func (s *recordSorter) spill() error {
+	s.sort()
+	file, err := os.CreateTemp("", "dsc_sort_*.gob")
+	if err != nil {
+		return fmt.Errorf("failed to create sort run file: %v", err)
+	}
+	s.runs = append(s.runs, file.Name())
+	writer := bufio.NewWriter(file)
+	encoder := gob.NewEncoder(writer)
+	for _, entry := range s.entries {
+		if err = encoder.Encode(entry); err != nil {
+			_ = file.Close()
+			return fmt.Errorf("failed to write sort run: %v", err)
+		}
+	}
+	if err = writer.Flush(); err == nil {
+		err = file.Close()
+	}
+	s.entries = s.entries[:0]
+	return err
+}
+
+//sortRun represents a sorted run reader with its current entry
+type sortRun struct {
+	file    *os.File
+	decoder *gob.Decoder
+	current *sortEntry
+}
+
+func (r *sortRun) next() error {
+	entry := &sortEntry{}
+	if err := r.decoder.Decode(entry); err != nil {
+		r.current = nil
+		if err == io.EOF {
+			return nil
+		}
+		return fmt.Errorf("failed to read sort run: %v", err)
+	}
+	r.current = entry
+	return nil
+}
+
+//runHeap represents a min heap of sorted runs used by k-way merge
+type runHeap struct {
+	runs   []*sortRun
+	sorter *recordSorter
+}
+
+func (h *runHeap) Len() int { return len(h.runs) }
+func (h *runHeap) Less(i, j int) bool {
+	return h.sorter.less(h.runs[i].current, h.runs[j].current)
+}
+func (h *runHeap) Swap(i, j int)      { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*sortRun)) }
+func (h *runHeap) Pop() interface{} {
+	last := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return last
+}
+
+//iterate passes sorted records to handler till handler returns false
+func (s *recordSorter) iterate(handler func(record map[string]interface{}) (bool, error)) error {
+	if len(s.runs) == 0 {
+		s.sort()
+		for _, entry := range s.entries {
+			if toContinue, err := handler(entry.Record); err != nil || !toContinue {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(s.entries) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	merger := &runHeap{sorter: s}
+	defer func() {
+		for _, run := range merger.runs {
+			_ = run.file.Close()
+		}
+	}()
+	for _, name := range s.runs {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		run := &sortRun{file: file, decoder: gob.NewDecoder(bufio.NewReader(file))}
+		if err = run.next(); err != nil {
+			_ = file.Close()
+			return err
+		}
+		if run.current == nil {
+			_ = file.Close()
+			continue
+		}
+		merger.runs = append(merger.runs, run)
+	}
+	heap.Init(merger)
+	for merger.Len() > 0 {
+		run := merger.runs[0]
+		if toContinue, err := handler(run.current.Record); err != nil || !toContinue {
+			return err
+		}
+		if err := run.next(); err != nil {
+			return err
+		}
+		if run.current == nil {
+			_ = run.file.Close()
+			heap.Pop(merger)
+			continue
+		}
+		heap.Fix(merger, 0)
+	}
+	return nil
+}
+
+//close removes temp files
+func (s *recordSorter) close() {
+	for _, name := range s.runs {
+		_ = os.Remove(name)
+	}
+	s.runs = nil
+	s.entries = nil
+}
+
+func newRecordSorter(orderBy []*SQLOrderColumn, limit int, bufferSize int) *recordSorter {
+	var descending = make([]bool, len(orderBy))
+	for i, column := range orderBy {
+		descending[i] = column.IsDescending()
+	}
+	if bufferSize <= 0 {
+		bufferSize = defaultSortBufferSize
+	}
+	return &recordSorter{descending: descending, limit: limit, bufferSize: bufferSize}
+}
+
+//compareValues compares two values, nil sorts first, numbers and times are compared by value, other types as text
+func compareValues(x, y interface{}) int {
+	if x == nil || y == nil {
+		switch {
+		case x == nil && y == nil:
+			return 0
+		case x == nil:
+			return -1
+		}
+		return 1
+	}
+	if xNumber, ok := asNumber(x); ok {
+		if yNumber, ok := asNumber(y); ok {
+			switch {
+			case xNumber < yNumber:
+				return -1
+			case xNumber > yNumber:
+				return 1
+			}
+			return 0
+		}
+	}
+	if xTime, ok := x.(time.Time); ok {
+		if yTime, ok := y.(time.Time); ok {
+			return xTime.Compare(yTime)
+		}
+	}
+	return strings.Compare(toolbox.AsString(x), toolbox.AsString(y))
+}
+
+func asNumber(value interface{}) (float64, bool) {
+	switch actual := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return toolbox.AsFloat(actual), true
+	case json.Number:
+		result, err := actual.Float64()
+		return result, err == nil
+	case bool:
+		if actual {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,275 @@
+package dsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	//ReplicaBalancingKey represents a config parameter with replica balancing strategy: roundRobin (default) or leastLoaded
+	ReplicaBalancingKey = "replicaBalancing"
+	//ReplicaHealthCheckMsKey represents a config parameter with replica health check interval, default 30000, 0 disables periodic health checks
+	ReplicaHealthCheckMsKey = "replicaHealthCheckMs"
+
+	defaultReplicaHealthCheckMs = 30000
+
+	//RoundRobinBalancing sends each read to the next healthy replica
+	RoundRobinBalancing = "roundRobin"
+	//LeastLoadedBalancing sends read to the healthy replica with the fewest reads in progress
+	LeastLoadedBalancing = "leastLoaded"
+)
+
+type primaryReadKey struct{}
+
+//WithPrimary returns context making ReplicatedManager context reads use primary, i.e. to read own writes
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+func isPrimaryRead(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadKey{}).(bool)
+	return primary
+}
+
+//replica represents read replica manager with its health and load
+type replica struct {
+	ContextManager
+	inFlight int32
+	down     int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.down) == 0
+}
+
+func (r *replica) setHealthy(healthy bool) {
+	var down int32 = 1
+	if healthy {
+		down = 0
+	}
+	atomic.StoreInt32(&r.down, down)
+}
+
+//ReplicatedManager represents primary/replica manager, ReadAll*, ReadSingle* and ReadAllWithHandler* go to a healthy replica,
+//all other operations, including reads on connection (i.e. within transaction), go to primary.
+type ReplicatedManager struct {
+	ContextManager //primary
+	replicas       []*replica
+	balancing      string
+	next           uint32
+	provider       *replicatedConnectionProvider
+}
+
+//Primary returns primary manager, it can be used to read own writes
+func (m *ReplicatedManager) Primary() Manager {
+	return m.ContextManager
+}
+
+//ConnectionProvider returns primary connection provider, closing it closes replicas and stops health checks
+func (m *ReplicatedManager) ConnectionProvider() ConnectionProvider {
+	return m.provider
+}
+
+//reader returns manager for read operation, primary is used when context requests it or no replica is healthy
+func (m *ReplicatedManager) reader(ctx context.Context) (ContextManager, func()) {
+	if isPrimaryRead(ctx) {
+		return m.ContextManager, func() {}
+	}
+	var selected *replica
+	switch m.balancing {
+	case LeastLoadedBalancing:
+		for _, candidate := range m.replicas {
+			if candidate.isHealthy() && (selected == nil || atomic.LoadInt32(&candidate.inFlight) < atomic.LoadInt32(&selected.inFlight)) {
+				selected = candidate
+			}
+		}
+	default:
+		offset := int(atomic.AddUint32(&m.next, 1))
+		for i := range m.replicas {
+			if candidate := m.replicas[(offset+i)%len(m.replicas)]; candidate.isHealthy() {
+				selected = candidate
+				break
+			}
+		}
+	}
+	if selected == nil {
+		return m.ContextManager, func() {}
+	}
+	atomic.AddInt32(&selected.inFlight, 1)
+	return selected, func() {
+		atomic.AddInt32(&selected.inFlight, -1)
+	}
+}
+
+//read runs read on selected manager, if replica connection fails, replica is marked unhealthy and read runs on primary
+//unless read returned nonRetryableError, i.e. some rows had already been fetched
+func (m *ReplicatedManager) read(ctx context.Context, read func(manager ContextManager) error) error {
+	manager, done := m.reader(ctx)
+	err := read(manager)
+	done()
+	stop, fetched := err.(*nonRetryableError)
+	if fetched {
+		err = stop.error
+	}
+	if err == nil || manager == m.ContextManager || !errors.Is(err, ErrConnection) {
+		return err
+	}
+	if selected, ok := manager.(*replica); ok {
+		m.markDown(ctx, selected, err)
+	}
+	if fetched {
+		return err
+	}
+	return read(m.ContextManager)
+}
+
+func (m *ReplicatedManager) markDown(ctx context.Context, selected *replica, err error) {
+	selected.setHealthy(false)
+	logger := m.Config().logger()
+	if logger.Enabled(ctx, slog.LevelWarn) {
+		logger.Log(ctx, slog.LevelWarn, "dsc: replica is down", "driver", selected.Config().DriverName, "error", err)
+	}
+}
+
+//CheckHealth pings all replicas and updates their health
+func (m *ReplicatedManager) CheckHealth() {
+	for _, candidate := range m.replicas {
+		err := GetDatastoreDialect(candidate.Config().DriverName).Ping(candidate)
+		if err != nil && candidate.isHealthy() {
+			m.markDown(context.Background(), candidate, err)
+			continue
+		}
+		candidate.setHealthy(err == nil)
+	}
+}
+
+//ReadSingle reads single row from replica
+func (m *ReplicatedManager) ReadSingle(resultPointer interface{}, query string, parameters []interface{}, mapper RecordMapper) (success bool, err error) {
+	return m.ReadSingleContext(context.Background(), resultPointer, query, parameters, mapper)
+}
+
+//ReadSingleContext reads single row from replica, unless context uses WithPrimary
+func (m *ReplicatedManager) ReadSingleContext(ctx context.Context, resultPointer interface{}, query string, parameters []interface{}, mapper RecordMapper) (success bool, err error) {
+	err = m.read(ctx, func(manager ContextManager) (err error) {
+		success, err = manager.ReadSingleContext(ctx, resultPointer, query, parameters, mapper)
+		return err
+	})
+	return success, err
+}
+
+//ReadAll reads all rows from replica
+func (m *ReplicatedManager) ReadAll(resultSlicePointer interface{}, query string, parameters []interface{}, mapper RecordMapper) error {
+	return m.ReadAllContext(context.Background(), resultSlicePointer, query, parameters, mapper)
+}
+
+//ReadAllContext reads all rows from replica, unless context uses WithPrimary
+func (m *ReplicatedManager) ReadAllContext(ctx context.Context, resultSlicePointer interface{}, query string, parameters []interface{}, mapper RecordMapper) error {
+	slice := reflect.ValueOf(resultSlicePointer).Elem()
+	return m.read(ctx, func(manager ContextManager) error {
+		length := slice.Len()
+		err := manager.ReadAllContext(ctx, resultSlicePointer, query, parameters, mapper)
+		if err != nil && slice.Len() > length {
+			return &nonRetryableError{err}
+		}
+		return err
+	})
+}
+
+//ReadAllWithHandler reads all rows from replica with reading handler
+func (m *ReplicatedManager) ReadAllWithHandler(query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	return m.ReadAllWithHandlerContext(context.Background(), query, parameters, readingHandler)
+}
+
+//ReadAllWithHandlerContext reads all rows from replica with reading handler, unless context uses WithPrimary
+func (m *ReplicatedManager) ReadAllWithHandlerContext(ctx context.Context, query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	var fetched bool
+	return m.read(ctx, func(manager ContextManager) error {
+		err := manager.ReadAllWithHandlerContext(ctx, query, parameters, func(scanner Scanner) (bool, error) {
+			fetched = true
+			return readingHandler(scanner)
+		})
+		if err != nil && fetched {
+			return &nonRetryableError{err}
+		}
+		return err
+	})
+}
+
+//replicatedConnectionProvider represents primary connection provider, it also closes replicas and stops health checks
+type replicatedConnectionProvider struct {
+	ConnectionProvider
+	replicas []*replica
+	stop     chan bool
+	once     sync.Once
+}
+
+//GetContext returns primary connection, it honours context if primary provider supports it
+func (p *replicatedConnectionProvider) GetContext(ctx context.Context) (Connection, error) {
+	if provider, ok := p.ConnectionProvider.(ContextConnectionProvider); ok {
+		return provider.GetContext(ctx)
+	}
+	return p.ConnectionProvider.Get()
+}
+
+//Close closes primary and replicas connection providers
+func (p *replicatedConnectionProvider) Close() error {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	err := p.ConnectionProvider.Close()
+	for _, candidate := range p.replicas {
+		if closeErr := candidate.ConnectionProvider().Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//startHealthCheck periodically checks replicas health until connection provider is closed
+func (m *ReplicatedManager) startHealthCheck(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.provider.stop:
+				return
+			case <-ticker.C:
+				m.CheckHealth()
+			}
+		}
+	}()
+}
+
+//NewReplicatedManager creates a new primary/replica manager, balancing and health check interval are taken from primary config parameters
+func NewReplicatedManager(primary Manager, replicas ...Manager) (*ReplicatedManager, error) {
+	primaryManager, ok := primary.(ContextManager)
+	if !ok {
+		return nil, fmt.Errorf("unsupported primary manager: %T", primary)
+	}
+	config := primary.Config()
+	config.initLock()
+	var result = &ReplicatedManager{
+		ContextManager: primaryManager,
+		replicas:       make([]*replica, 0, len(replicas)),
+		balancing:      config.GetString(ReplicaBalancingKey, RoundRobinBalancing),
+	}
+	for _, candidate := range replicas {
+		replicaManager, ok := candidate.(ContextManager)
+		if !ok {
+			return nil, fmt.Errorf("unsupported replica manager: %T", candidate)
+		}
+		result.replicas = append(result.replicas, &replica{ContextManager: replicaManager})
+	}
+	result.provider = &replicatedConnectionProvider{ConnectionProvider: primary.ConnectionProvider(), replicas: result.replicas, stop: make(chan bool)}
+	if interval := config.GetDuration(ReplicaHealthCheckMsKey, time.Millisecond, defaultReplicaHealthCheckMs*time.Millisecond); interval > 0 {
+		result.startHealthCheck(interval)
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package dsc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestReplicatedManager(t *testing.T) {
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/primary.db")
+	config.Replicas = []*dsc.Config{
+		dsc.NewConfig("sqlite3", "[url]", "url:./test/replica.db"),
+		dsc.NewConfig("sqlite3", "[url]", "url:./test/missing/replica.db"),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	replicated, ok := manager.(*dsc.ReplicatedManager)
+	if !assert.True(t, ok) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	registry := dsc.NewManagerRegistry()
+	registry.Register("app", manager)
+	manager = registry.Get("app")
+
+	setup := func(target dsc.Manager, name string) {
+		for _, SQL := range []string{
+			"DROP TABLE IF EXISTS nodes",
+			"CREATE TABLE nodes (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+			"INSERT INTO nodes(id, name) VALUES(1, '" + name + "')",
+		} {
+			_, err = target.Execute(SQL)
+			assert.Nil(t, err)
+		}
+	}
+	setup(manager, "primary")
+	replica, err := dsc.NewManagerFactory().Create(config.Replicas[0])
+	if !assert.Nil(t, err) {
+		return
+	}
+	setup(replica, "replica")
+
+	type Node struct {
+		Id   int `primaryKey:"true"`
+		Name string
+	}
+	var readName = func(ctx context.Context) string {
+		var node = Node{}
+		_, err := manager.(dsc.ContextManager).ReadSingleContext(ctx, &node, "SELECT id, name FROM nodes WHERE id = ?", []interface{}{1}, nil)
+		assert.Nil(t, err)
+		return node.Name
+	}
+	for i := 0; i < 4; i++ { //missing replica is marked down and read falls back to primary
+		name := readName(context.Background())
+		assert.True(t, name == "replica" || name == "primary", name)
+	}
+	assert.Equal(t, "replica", readName(context.Background()))
+	assert.Equal(t, "replica", readName(context.Background()))
+	assert.Equal(t, "primary", readName(dsc.WithPrimary(context.Background())))
+
+	nodes := []Node{{1, "updated"}}
+	inserted, updated, err := manager.PersistAll(&nodes, "nodes", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, inserted+updated)
+	assert.Equal(t, "updated", readName(dsc.WithPrimary(context.Background())))
+	assert.Equal(t, "replica", readName(context.Background()))
+
+	replicated.CheckHealth()
+	assert.Equal(t, "replica", readName(context.Background()))
+}
+
+func TestReplicatedManager_PartialRead(t *testing.T) {
+	primary, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/primary.db,replicaHealthCheckMs:0"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	replicaConfig := dsc.NewConfig("sqlite3", "[url]", "url:./test/primary.db")
+	replicaConfig.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			if err := next(operation); err != nil || operation.Kind != dsc.OperationRead {
+				return err
+			}
+			return fmt.Errorf("injected: %w", dsc.ErrConnection)
+		}),
+	}
+	replica, err := dsc.NewManagerFactory().Create(replicaConfig)
+	if !assert.Nil(t, err) {
+		return
+	}
+	manager, err := dsc.NewReplicatedManager(primary, replica)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS partial_nodes",
+		"CREATE TABLE partial_nodes (id INTEGER NOT NULL PRIMARY KEY)",
+		"INSERT INTO partial_nodes(id) VALUES(1)",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM partial_nodes", nil, nil)
+	assert.True(t, errors.Is(err, dsc.ErrConnection), "fetched rows are not read again from primary")
+	assert.Equal(t, 1, len(records))
+
+	records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM partial_nodes", nil, nil)
+	assert.Nil(t, err, "replica is down, read goes to primary")
+	assert.Equal(t, 1, len(records))
+}
@@ -0,0 +1,163 @@
+package dsc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+const (
+	//RetryMaxAttemptsKey represents a config parameter with max number of attempts of retryable operation, 1 or less disables retries
+	RetryMaxAttemptsKey = "retryMaxAttempts"
+	//RetryBackoffMsKey represents a config parameter with initial retry backoff, doubled after each failed attempt
+	RetryBackoffMsKey = "retryBackoffMs"
+	//RetryMaxBackoffMsKey represents a config parameter with max retry backoff
+	RetryMaxBackoffMsKey = "retryMaxBackoffMs"
+	//RetryTransactionsKey represents a config parameter enabling WithTransaction handler reruns, default false
+	RetryTransactionsKey = "retryTransactions"
+
+	defaultRetryBackoff    = 50 * time.Millisecond
+	defaultRetryMaxBackoff = 2 * time.Second
+	defaultRetryJitter     = 0.2
+)
+
+//errCommitFailed marks commit failure, transaction outcome is unknown unless datastore reports it was rolled back
+var errCommitFailed = errors.New("failed to commit")
+
+//RetryPolicy represents retry policy for transient failures, it is applied to reads outside transaction (until the first row is fetched)
+//and to whole transactional units: PersistAll and DeleteAll. WithTransaction handler is rerun only if RetryTransactions is set.
+type RetryPolicy struct {
+	MaxAttempts       int                  //max number of attempts, including the first one
+	Backoff           time.Duration        //initial backoff, doubled after each failed attempt
+	MaxBackoff        time.Duration        //max backoff
+	Jitter            float64              //backoff fraction (0-1) randomly added or subtracted from backoff
+	Retryable         func(err error) bool //optional classifier, dialect IsRetryable is used by default
+	RetryTransactions bool                 //reruns whole WithTransaction handler on a new connection, handler has to be safe to rerun
+}
+
+//delay returns backoff with jitter for passed in failed attempt
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	backoff := p.Backoff
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		backoff += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(backoff))
+	}
+	return backoff
+}
+
+//NewRetryPolicy creates a new retry policy with default exponential backoff and jitter
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		Backoff:     defaultRetryBackoff,
+		MaxBackoff:  defaultRetryMaxBackoff,
+		Jitter:      defaultRetryJitter,
+	}
+}
+
+//retryPolicy returns config retry policy or policy created from config parameters, or nil if retries are disabled
+func (c *Config) retryPolicy() *RetryPolicy {
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy
+	}
+	c.initLock()
+	maxAttempts := c.GetInt(RetryMaxAttemptsKey, 0)
+	if maxAttempts <= 1 {
+		return nil
+	}
+	result := NewRetryPolicy(maxAttempts)
+	result.Backoff = c.GetDuration(RetryBackoffMsKey, time.Millisecond, defaultRetryBackoff)
+	result.MaxBackoff = c.GetDuration(RetryMaxBackoffMsKey, time.Millisecond, defaultRetryMaxBackoff)
+	result.RetryTransactions = c.GetBoolean(RetryTransactionsKey, false)
+	return result
+}
+
+//isRetryable returns true if failed operation can be safely retried
+func (m *AbstractManager) isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, errCommitFailed) && !errors.Is(err, ErrDeadlock) { //transaction might have been committed
+		return false
+	}
+	if m.retry.Retryable != nil {
+		return m.retry.Retryable(err)
+	}
+	return GetDatastoreDialect(m.config.DriverName).IsRetryable(m.translateError(err))
+}
+
+//withRetry runs operation, and reruns it with backoff on retryable failure as long as retry policy allows
+func (m *AbstractManager) withRetry(ctx context.Context, operation string, run func() error) error {
+	if m.retry == nil || m.retry.MaxAttempts <= 1 {
+		return run()
+	}
+	for attempt := 1; ; attempt++ {
+		err := run()
+		if stop, ok := err.(*nonRetryableError); ok {
+			return stop.error
+		}
+		if err == nil || attempt >= m.retry.MaxAttempts || !m.isRetryable(ctx, err) {
+			return err
+		}
+		delay := m.retry.delay(attempt)
+		if logger := m.Logger(); logger != nil && logger.Enabled(ctx, slog.LevelWarn) {
+			logger.Log(ctx, slog.LevelWarn, "dsc: retrying operation", "driver", m.config.DriverName, "operation", operation, "attempt", attempt, "delay", delay, "error", err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+//runTransactionalUnit gets a connection and runs handler in a transaction, on retryable failure whole transaction is rerun on a new connection,
+//dialects that can not handle transaction are never retried
+func (m *AbstractManager) runTransactionalUnit(ctx context.Context, operation string, handler func(connection Connection) error) error {
+	run := func() error {
+		connection, err := m.getConnection(ctx)
+		if err != nil {
+			return err
+		}
+		defer connection.Close()
+		return m.runInTransaction(connection, func() error {
+			return handler(connection)
+		})
+	}
+	if !GetDatastoreDialect(m.config.DriverName).CanHandleTransaction() {
+		return run()
+	}
+	return m.withRetry(ctx, operation, run)
+}
+
+//readWithRetry runs read, and reruns it on retryable failure if connection has no active transaction and no row has been passed to reading handler yet
+func (m *AbstractManager) readWithRetry(ctx context.Context, connection Connection, readingHandler func(scanner Scanner) (toContinue bool, err error), read func(readingHandler func(scanner Scanner) (toContinue bool, err error)) error) error {
+	if transactional, ok := connection.(transactionalConnection); m.retry == nil || (ok && transactional.inTransaction()) {
+		return read(readingHandler)
+	}
+	var fetched bool
+	return m.withRetry(ctx, "read", func() error {
+		err := read(func(scanner Scanner) (bool, error) {
+			fetched = true
+			return readingHandler(scanner)
+		})
+		if err != nil && fetched {
+			return &nonRetryableError{err}
+		}
+		return err
+	})
+}
+
+//nonRetryableError marks operation error that must not be retried
+type nonRetryableError struct {
+	error
+}
@@ -0,0 +1,102 @@
+package dsc_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	type Task struct {
+		Id   int `primaryKey:"true"`
+		Name string
+	}
+	buffer := new(bytes.Buffer)
+	var failures = map[string]error{}
+	var attempts = map[string]int{}
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db")
+	config.Parameters[dsc.UpsertKey] = false
+	config.RetryPolicy = &dsc.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Jitter: 0.5}
+	config.Logger = slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			if operation.Table != "tasks" {
+				return next(operation)
+			}
+			attempts[operation.Kind]++
+			if err, ok := failures[operation.Kind]; ok {
+				if attempts[operation.Kind] < 3 || errors.Is(err, dsc.ErrSyntax) {
+					return err
+				}
+			}
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS tasks",
+		"CREATE TABLE tasks (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+
+	failures[dsc.OperationPersist] = fmt.Errorf("injected: %w", dsc.ErrDeadlock)
+	attempts = map[string]int{}
+	tasks := []Task{{1, "build"}, {2, "test"}}
+	inserted, _, err := manager.PersistAll(&tasks, "tasks", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, inserted)
+	assert.Equal(t, 3, attempts[dsc.OperationRead])
+	assert.Equal(t, 2, strings.Count(buffer.String(), "dsc: retrying operation"))
+
+	failures = map[string]error{dsc.OperationRead: driver.ErrBadConn}
+	attempts = map[string]int{}
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM tasks ORDER BY id", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts[dsc.OperationRead])
+	assert.EqualValues(t, [][]interface{}{{int64(1)}, {int64(2)}}, records)
+
+	failures = map[string]error{dsc.OperationRead: fmt.Errorf("injected: %w", dsc.ErrSyntax)}
+	attempts = map[string]int{}
+	err = manager.ReadAll(&records, "SELECT id FROM tasks", nil, nil)
+	assert.True(t, errors.Is(err, dsc.ErrSyntax))
+	assert.Equal(t, 1, attempts[dsc.OperationRead])
+
+	var runs = 0
+	var handler = func(connection dsc.Connection) error {
+		runs++
+		_, err := manager.ExecuteOnConnection(connection, "UPDATE tasks SET name = ? WHERE id = ?", []interface{}{"deploy", 1})
+		return err
+	}
+	failures = map[string]error{dsc.OperationExecute: fmt.Errorf("injected: %w", dsc.ErrDeadlock)}
+	attempts = map[string]int{}
+	err = manager.WithTransaction(handler)
+	assert.True(t, errors.Is(err, dsc.ErrDeadlock))
+	assert.Equal(t, 1, runs, "transaction handler is not rerun by default")
+
+	config.RetryPolicy.RetryTransactions = true
+	runs = 0
+	attempts = map[string]int{}
+	err = manager.WithTransaction(handler)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, runs)
+
+	clone := config.Clone()
+	if assert.NotNil(t, clone.RetryPolicy) {
+		assert.Equal(t, 3, clone.RetryPolicy.MaxAttempts)
+		assert.False(t, clone.RetryPolicy == config.RetryPolicy)
+	}
+}
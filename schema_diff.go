@@ -0,0 +1,379 @@
+package dsc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/viant/toolbox"
+)
+
+const (
+	//ColumnMissing represents expected column missing in the actual table
+	ColumnMissing = "missing"
+	//ColumnExtra represents actual table column that is not expected
+	ColumnExtra = "extra"
+	//ColumnTypeMismatch represents incompatible column type
+	ColumnTypeMismatch = "type"
+	//ColumnNullableMismatch represents column nullability difference
+	ColumnNullableMismatch = "nullable"
+	//ColumnLengthMismatch represents column length difference
+	ColumnLengthMismatch = "length"
+)
+
+var sqlTypeLengthExpr = regexp.MustCompile(`\(\s*(\d+)\s*\)`)
+
+//ColumnDiff represents a column difference between expected and actual table
+type ColumnDiff struct {
+	Column   string
+	Kind     string
+	Expected string
+	Actual   string
+}
+
+//SchemaDiff represents differences between expected table descriptor and actual table with statements reconciling actual table
+type SchemaDiff struct {
+	Table        string
+	TableMissing bool
+	Columns      []*ColumnDiff
+	ExpectedPk   []string
+	ActualPk     []string
+	PkMismatch   bool
+	DropColumns  bool //emit DROP COLUMN for extra columns, dropping columns destroys data, set it and call dialect AlterTableSQL to regenerate statements
+	Statements   []string
+	nullables    map[string]bool //actual column nullability reported by driver
+}
+
+//HasChanges returns true if actual table differs from expected one
+func (d *SchemaDiff) HasChanges() bool {
+	return d.TableMissing || d.PkMismatch || len(d.Columns) > 0
+}
+
+//ColumnDiffs returns column differences of passed in kind
+func (d *SchemaDiff) ColumnDiffs(kind string) []*ColumnDiff {
+	var result = make([]*ColumnDiff, 0)
+	for _, diff := range d.Columns {
+		if diff.Kind == kind {
+			result = append(result, diff)
+		}
+	}
+	return result
+}
+
+//sqlTypeCategory returns comparable category of SQL type name, or empty string if unknown
+func sqlTypeCategory(typeName string) string {
+	typeName = strings.ToUpper(typeName)
+	if index := strings.Index(typeName, "("); index != -1 {
+		typeName = typeName[:index]
+	}
+	typeName = strings.TrimSpace(strings.Replace(typeName, "UNSIGNED", "", 1))
+	switch {
+	case typeName == "":
+		return ""
+	case strings.Contains(typeName, "BOOL") || typeName == "BIT":
+		return "bool"
+	case strings.Contains(typeName, "DATE") || strings.Contains(typeName, "TIME") || strings.Contains(typeName, "INTERVAL"):
+		return "time"
+	case strings.Contains(typeName, "FLOAT") || strings.Contains(typeName, "DOUBLE") || strings.Contains(typeName, "REAL"):
+		return "float"
+	case strings.Contains(typeName, "INT") || strings.Contains(typeName, "SERIAL"):
+		return "int"
+	case strings.Contains(typeName, "DEC") || strings.Contains(typeName, "NUM") || strings.Contains(typeName, "MONEY"):
+		return "decimal"
+	case strings.Contains(typeName, "BLOB") || strings.Contains(typeName, "BINARY") || strings.Contains(typeName, "BYTEA") || strings.Contains(typeName, "RAW") || typeName == "IMAGE":
+		return "bytes"
+	case strings.Contains(typeName, "CHAR") || strings.Contains(typeName, "TEXT") || strings.Contains(typeName, "CLOB") || strings.Contains(typeName, "STRING") ||
+		strings.Contains(typeName, "UUID") || strings.Contains(typeName, "JSON") || strings.Contains(typeName, "XML") || typeName == "ENUM":
+		return "string"
+	}
+	return ""
+}
+
+//goTypeCategory returns comparable category of go type name
+func goTypeCategory(typeName string) string {
+	switch kind := goTypeKind(typeName); kind {
+	case "int", "int64":
+		return "int"
+	case "float32", "float64":
+		return "float"
+	default:
+		return kind
+	}
+}
+
+//isCompatibleCategory returns true if values of both type categories can be stored interchangeably, i.e. NUMBER(1) for bool
+func isCompatibleCategory(expected, actual string) bool {
+	if expected == actual || expected == "" || actual == "" {
+		return true
+	}
+	var numeric = map[string]bool{"int": true, "float": true, "bool": true, "decimal": true}
+	if (expected == "decimal" && numeric[actual]) || (actual == "decimal" && numeric[expected]) {
+		return true
+	}
+	return numeric[expected] && numeric[actual] && expected != "float" && actual != "float"
+}
+
+//sqlTypeLength returns length declared in SQL type, i.e. 64 for VARCHAR(64)
+func sqlTypeLength(typeName string) int64 {
+	if matched := sqlTypeLengthExpr.FindStringSubmatch(typeName); len(matched) > 1 {
+		return int64(toolbox.AsInt(matched[1]))
+	}
+	return 0
+}
+
+//expectedColumnType returns expected column type name and category
+func expectedColumnType(descriptor *TableDescriptor, column string) (string, string) {
+	if sqlType, ok := descriptor.SQLTypes[column]; ok {
+		return sqlType, sqlTypeCategory(sqlType)
+	}
+	goType, ok := descriptor.ColumnTypes[column]
+	if !ok {
+		return "", ""
+	}
+	return goType, goTypeCategory(goType)
+}
+
+//expectedColumnLength returns explicitly declared column length, size tag takes precedence over sqlType tag
+func expectedColumnLength(descriptor *TableDescriptor, column string) int64 {
+	if size, ok := descriptor.ColumnSizes[column]; ok {
+		return int64(size)
+	}
+	return sqlTypeLength(descriptor.SQLTypes[column])
+}
+
+//actualColumnLength returns live column length, or 0 if unknown or unbounded
+func actualColumnLength(column Column) int64 {
+	if length, ok := column.Length(); ok {
+		if length == math.MaxInt64 {
+			return 0
+		}
+		return length
+	}
+	return sqlTypeLength(column.DatabaseTypeName())
+}
+
+//isExpectedNullable returns false for primary key column or column declared NOT NULL, non pointer fields are NOT NULL in created table,
+//but NOT NULL is only required from existing column if declared with nullable:"false" tag
+func isExpectedNullable(descriptor *TableDescriptor, column string) bool {
+	if hasColumn(descriptor.PkColumns, column) || hasColumn(descriptor.NotNullColumns, column) {
+		return false
+	}
+	return descriptor.Nullables[column]
+}
+
+func nullability(nullable bool) string {
+	if nullable {
+		return "NULL"
+	}
+	return "NOT NULL"
+}
+
+//compareColumns returns differences between expected descriptor and actual table columns with primary key
+func compareColumns(descriptor *TableDescriptor, columns []Column, pkColumns []string) *SchemaDiff {
+	var result = &SchemaDiff{Table: descriptor.Table, Columns: make([]*ColumnDiff, 0), ExpectedPk: descriptor.PkColumns, ActualPk: pkColumns, nullables: make(map[string]bool)}
+	var actualColumns = make(map[string]Column)
+	for _, column := range columns {
+		actualColumns[strings.ToLower(column.Name())] = column
+	}
+	for _, name := range descriptor.Columns {
+		expectedType, expectedCategory := expectedColumnType(descriptor, name)
+		actual, ok := actualColumns[strings.ToLower(name)]
+		if !ok {
+			result.Columns = append(result.Columns, &ColumnDiff{Column: name, Kind: ColumnMissing, Expected: expectedType})
+			continue
+		}
+		actualType := actual.DatabaseTypeName()
+		if !isCompatibleCategory(expectedCategory, sqlTypeCategory(actualType)) {
+			result.Columns = append(result.Columns, &ColumnDiff{Column: name, Kind: ColumnTypeMismatch, Expected: expectedType, Actual: actualType})
+		}
+		expectedLength, actualLength := expectedColumnLength(descriptor, name), actualColumnLength(actual)
+		if expectedLength > 0 && actualLength > 0 && expectedLength != actualLength {
+			result.Columns = append(result.Columns, &ColumnDiff{Column: name, Kind: ColumnLengthMismatch, Expected: toolbox.AsString(expectedLength), Actual: toolbox.AsString(actualLength)})
+		}
+		if actualNullable, ok := actual.Nullable(); ok {
+			result.nullables[name] = actualNullable
+			expectedNullable := isExpectedNullable(descriptor, name)
+			if expectedNullable != actualNullable && (expectedNullable || hasColumn(descriptor.NotNullColumns, name)) {
+				result.Columns = append(result.Columns, &ColumnDiff{Column: name, Kind: ColumnNullableMismatch, Expected: nullability(expectedNullable), Actual: nullability(actualNullable)})
+			}
+		}
+	}
+	for _, column := range columns {
+		if !hasColumn(descriptor.Columns, column.Name()) {
+			result.Columns = append(result.Columns, &ColumnDiff{Column: column.Name(), Kind: ColumnExtra, Actual: column.DatabaseTypeName()})
+		}
+	}
+	if len(descriptor.PkColumns) != len(pkColumns) {
+		result.PkMismatch = true
+	} else {
+		for _, column := range pkColumns {
+			if !hasColumn(descriptor.PkColumns, column) {
+				result.PkMismatch = true
+			}
+		}
+	}
+	return result
+}
+
+//alterTableSQL returns statements reconciling actual table with descriptor, column changes are skipped if dialect can not modify columns,
+//extra columns are only dropped with diff DropColumns, modified column keeps actual nullability unless nullability differs
+func (s *ddlSpec) alterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	if diff.TableMissing {
+		DDL, err := s.createTableSQL(descriptor)
+		if err != nil {
+			return nil, err
+		}
+		return []string{DDL}, nil
+	}
+	var result = make([]string, 0)
+	var columns = make([]string, 0)
+	var changes = make(map[string]map[string]bool)
+	for _, columnDiff := range diff.Columns {
+		column := columnDiff.Column
+		switch columnDiff.Kind {
+		case ColumnMissing:
+			result = append(result, fmt.Sprintf(s.addColumn, descriptor.Table, s.columnDefinition(descriptor, column)))
+		case ColumnExtra:
+			if diff.DropColumns {
+				result = append(result, fmt.Sprintf(ansiDropColumn, descriptor.Table, column))
+			}
+		default:
+			if _, ok := changes[column]; !ok {
+				changes[column] = make(map[string]bool)
+				columns = append(columns, column)
+			}
+			changes[column][columnDiff.Kind] = true
+		}
+	}
+	for _, column := range columns {
+		if len(s.modifyColumn) == 0 {
+			break
+		}
+		kinds := changes[column]
+		nullable, ok := diff.nullables[column]
+		if kinds[ColumnNullableMismatch] || !ok {
+			nullable = isExpectedNullable(descriptor, column)
+		}
+		nullability, setNullability := "NOT NULL", "SET NOT NULL"
+		if nullable {
+			nullability, setNullability = "NULL", "DROP NOT NULL"
+		}
+		var templates = s.modifyColumn
+		if s.modifyNullable != "" {
+			templates = make([]string, 0)
+			if kinds[ColumnTypeMismatch] || kinds[ColumnLengthMismatch] {
+				templates = append(templates, s.modifyColumn...)
+			}
+			if kinds[ColumnNullableMismatch] {
+				templates = append(templates, s.modifyNullable)
+			}
+		}
+		for _, template := range templates {
+			result = append(result, fmt.Sprintf(template, descriptor.Table, column, s.columnSQLType(descriptor, column), nullability, setNullability))
+		}
+	}
+	if diff.PkMismatch && len(descriptor.PkColumns) > 0 && s.addPrimaryKey != "" {
+		if len(diff.ActualPk) > 0 {
+			if s.dropPrimaryKey == "" {
+				return result, nil
+			}
+			result = append(result, fmt.Sprintf(s.dropPrimaryKey, descriptor.Table))
+		}
+		result = append(result, fmt.Sprintf(s.addPrimaryKey, descriptor.Table, strings.Join(descriptor.PkColumns, ", ")))
+	}
+	return result, nil
+}
+
+//liveTable returns live table columns and primary key columns, or false if table does not exist
+func liveTable(manager Manager, table string) ([]Column, []string, bool, error) {
+	dialect := GetDatastoreDialect(manager.Config().DriverName)
+	if dialect == nil {
+		return nil, nil, false, fmt.Errorf("failed to lookup dialect for driver: %v", manager.Config().DriverName)
+	}
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	tables, err := dialect.GetTables(manager, datastore)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("unable to get tables for %v, %w", datastore, err)
+	}
+	if !hasColumn(tables, table) {
+		return nil, nil, false, nil
+	}
+	columns, err := dialect.GetColumns(manager, datastore, table)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("unable to get columns for %v.%v, %w", datastore, table, err)
+	}
+	var pkColumns = make([]string, 0)
+	if keyName := dialect.GetKeyName(manager, datastore, table); keyName != "" {
+		for _, column := range strings.Split(keyName, ",") {
+			pkColumns = append(pkColumns, strings.TrimSpace(column))
+		}
+	}
+	return columns, pkColumns, true, nil
+}
+
+//CompareSchema compares expected table descriptor, i.e. created with NewTableDescriptor from a struct, with live table of passed in manager.
+//Returned diff statements are ALTER TABLE (or CREATE TABLE if table is missing) statements in manager dialect reconciling live table.
+func CompareSchema(manager Manager, descriptor *TableDescriptor) (*SchemaDiff, error) {
+	columns, pkColumns, exists, err := liveTable(manager, descriptor.Table)
+	if err != nil {
+		return nil, err
+	}
+	var result *SchemaDiff
+	if exists {
+		result = compareColumns(descriptor, columns, pkColumns)
+	} else {
+		result = &SchemaDiff{Table: descriptor.Table, TableMissing: true, Columns: make([]*ColumnDiff, 0), ExpectedPk: descriptor.PkColumns, PkMismatch: len(descriptor.PkColumns) > 0}
+		for _, column := range descriptor.Columns {
+			expectedType, _ := expectedColumnType(descriptor, column)
+			result.Columns = append(result.Columns, &ColumnDiff{Column: column, Kind: ColumnMissing, Expected: expectedType})
+		}
+	}
+	if !result.HasChanges() {
+		return result, nil
+	}
+	dialect := GetDatastoreDialect(manager.Config().DriverName)
+	if result.Statements, err = dialect.AlterTableSQL(descriptor, result); errors.Is(err, errUnsupportedOperation) {
+		err = nil
+	}
+	return result, err
+}
+
+//CompareTableSchema compares live table of expected manager with the same table of actual manager, i.e. staging and production database of the same vendor
+func CompareTableSchema(expected, actual Manager, table string) (*SchemaDiff, error) {
+	columns, pkColumns, exists, err := liveTable(expected, table)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("table %v does not exist in expected datastore", table)
+	}
+	descriptor := &TableDescriptor{
+		Table:     table,
+		PkColumns: pkColumns,
+		Columns:   make([]string, 0, len(columns)),
+		SQLTypes:  make(map[string]string),
+		Nullables: make(map[string]bool),
+	}
+	for _, column := range columns {
+		name := column.Name()
+		descriptor.Columns = append(descriptor.Columns, name)
+		sqlType := column.DatabaseTypeName()
+		if length := actualColumnLength(column); length > 0 && !strings.Contains(sqlType, "(") {
+			if category := sqlTypeCategory(sqlType); category == "string" || category == "bytes" {
+				sqlType = fmt.Sprintf("%v(%v)", sqlType, length)
+			}
+		}
+		descriptor.SQLTypes[name] = sqlType
+		nullable, ok := column.Nullable()
+		descriptor.Nullables[name] = nullable || !ok //unknown nullability does not add NOT NULL constraint
+		if !descriptor.Nullables[name] {
+			descriptor.NotNullColumns = append(descriptor.NotNullColumns, name)
+		}
+	}
+	return CompareSchema(actual, descriptor)
+}
@@ -0,0 +1,136 @@
+package dsc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+type diffAccount struct {
+	Id      int    `primaryKey:"true"`
+	Name    string `size:"64" nullable:"false"`
+	Balance float64
+	Email   *string `size:"128"`
+}
+
+func TestCompareSchema(t *testing.T) {
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/schema_diff.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	_, _ = manager.Execute("DROP TABLE IF EXISTS diff_accounts")
+	descriptor, err := dsc.NewTableDescriptor("diff_accounts", diffAccount{})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	diff, err := dsc.CompareSchema(manager, descriptor)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, diff.TableMissing)
+	assert.Equal(t, 4, len(diff.ColumnDiffs(dsc.ColumnMissing)))
+	if assert.Equal(t, 1, len(diff.Statements)) {
+		assert.Contains(t, diff.Statements[0], "CREATE TABLE diff_accounts")
+	}
+
+	_, err = manager.Execute("CREATE TABLE diff_accounts (Id INTEGER NOT NULL PRIMARY KEY, Name VARCHAR(32), Balance TIMESTAMP, legacy_code TEXT)")
+	if !assert.Nil(t, err) {
+		return
+	}
+	diff, err = dsc.CompareSchema(manager, descriptor)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.True(t, diff.HasChanges())
+	assert.False(t, diff.PkMismatch)
+	missing := diff.ColumnDiffs(dsc.ColumnMissing)
+	if assert.Equal(t, 1, len(missing)) {
+		assert.Equal(t, "Email", missing[0].Column)
+	}
+	extra := diff.ColumnDiffs(dsc.ColumnExtra)
+	if assert.Equal(t, 1, len(extra)) {
+		assert.Equal(t, "legacy_code", extra[0].Column)
+	}
+	mismatched := diff.ColumnDiffs(dsc.ColumnTypeMismatch)
+	if assert.Equal(t, 1, len(mismatched)) {
+		assert.Equal(t, "Balance", mismatched[0].Column)
+	}
+	lengths := diff.ColumnDiffs(dsc.ColumnLengthMismatch)
+	if assert.Equal(t, 1, len(lengths)) {
+		assert.Equal(t, "64", lengths[0].Expected)
+		assert.Equal(t, "32", lengths[0].Actual)
+	}
+	nullables := diff.ColumnDiffs(dsc.ColumnNullableMismatch)
+	if assert.Equal(t, 1, len(nullables)) { //NOT NULL Balance is not required without nullable tag
+		assert.Equal(t, "Name", nullables[0].Column)
+		assert.Equal(t, "NOT NULL", nullables[0].Expected)
+	}
+	//SQLite can not modify columns
+	assert.Equal(t, []string{
+		"ALTER TABLE diff_accounts ADD COLUMN Email VARCHAR(128)",
+	}, diff.Statements)
+	diff.DropColumns = true
+	statements, err := dsc.GetDatastoreDialect("sqlite3").AlterTableSQL(descriptor, diff)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE diff_accounts ADD COLUMN Email VARCHAR(128)",
+		"ALTER TABLE diff_accounts DROP COLUMN legacy_code",
+	}, statements)
+	for _, SQL := range statements {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	diff, err = dsc.CompareSchema(manager, descriptor)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(diff.ColumnDiffs(dsc.ColumnMissing)))
+	assert.Equal(t, 0, len(diff.ColumnDiffs(dsc.ColumnExtra)))
+
+	pgStatements, err := dsc.GetDatastoreDialect("pg").AlterTableSQL(descriptor, &dsc.SchemaDiff{Table: "diff_accounts", Columns: []*dsc.ColumnDiff{
+		{Column: "Name", Kind: dsc.ColumnLengthMismatch, Expected: "64", Actual: "32"},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE diff_accounts ALTER COLUMN Name TYPE VARCHAR(64)",
+	}, pgStatements)
+	pgStatements, err = dsc.GetDatastoreDialect("pg").AlterTableSQL(descriptor, &dsc.SchemaDiff{Table: "diff_accounts", Columns: []*dsc.ColumnDiff{
+		{Column: "Name", Kind: dsc.ColumnNullableMismatch, Expected: "NOT NULL", Actual: "NULL"},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ALTER TABLE diff_accounts ALTER COLUMN Name SET NOT NULL"}, pgStatements)
+	mySQLStatements, err := dsc.GetDatastoreDialect("mysql").AlterTableSQL(descriptor, &dsc.SchemaDiff{Table: "diff_accounts", PkMismatch: true, ActualPk: []string{"Name"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE diff_accounts DROP PRIMARY KEY",
+		"ALTER TABLE diff_accounts ADD PRIMARY KEY(Id)",
+	}, mySQLStatements)
+}
+
+func TestCompareTableSchema(t *testing.T) {
+	expected, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/schema_expected.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer expected.ConnectionProvider().Close()
+	actual, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/schema_actual.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer actual.ConnectionProvider().Close()
+	for manager, DDL := range map[dsc.Manager]string{
+		expected: "CREATE TABLE diff_events (id INTEGER NOT NULL PRIMARY KEY, name VARCHAR(64), created TIMESTAMP)",
+		actual:   "CREATE TABLE diff_events (id INTEGER NOT NULL PRIMARY KEY, name VARCHAR(64))",
+	} {
+		_, _ = manager.Execute("DROP TABLE IF EXISTS diff_events")
+		_, err = manager.Execute(DDL)
+		assert.Nil(t, err)
+	}
+	diff, err := dsc.CompareTableSchema(expected, actual, "diff_events")
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, len(diff.Columns))
+	assert.Equal(t, []string{"ALTER TABLE diff_events ADD COLUMN created TIMESTAMP"}, diff.Statements)
+}
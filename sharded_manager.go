@@ -0,0 +1,602 @@
+package dsc
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+
+	"github.com/viant/toolbox"
+)
+
+//ShardFunc returns shard index for passed in shard key value and number of shards
+type ShardFunc func(value interface{}, shards int) (int, error)
+
+//HashShardFunc assigns shard with FNV-1a hash of shard key value text representation
+func HashShardFunc(value interface{}, shards int) (int, error) {
+	if value == nil {
+		return 0, errors.New("shard key value was nil")
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(toolbox.AsString(value)))
+	return int(hash.Sum32() % uint32(shards)), nil
+}
+
+//NewRangeShardFunc creates a shard function assigning value lower than upperBounds[i] to shard i, and value not lower than the last bound to the next shard,
+//bounds have to be sorted, numeric values are compared as numbers, other values as text.
+func NewRangeShardFunc(upperBounds ...interface{}) ShardFunc {
+	return func(value interface{}, shards int) (int, error) {
+		if value == nil {
+			return 0, errors.New("shard key value was nil")
+		}
+		index := len(upperBounds)
+		for i, bound := range upperBounds {
+			if compareShardKeys(value, bound) < 0 {
+				index = i
+				break
+			}
+		}
+		if index >= shards {
+			return 0, fmt.Errorf("shard key %v is out of range, %v range(s) for %v shard(s)", value, len(upperBounds)+1, shards)
+		}
+		return index, nil
+	}
+}
+
+func compareShardKeys(left, right interface{}) int {
+	leftNumber, leftErr := toolbox.ToFloat(left)
+	rightNumber, rightErr := toolbox.ToFloat(right)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case leftNumber < rightNumber:
+			return -1
+		case leftNumber > rightNumber:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(toolbox.AsString(left), toolbox.AsString(right))
+}
+
+//ShardedManager represents horizontally sharded manager, it routes persist and delete to shards by shard key column value.
+//Reads and DML with shard key equality (or IN) predicate go to matching shard(s), other reads scatter across all shards and gather results
+//in shard order, reads spanning shards with ORDER BY, LIMIT, OFFSET, GROUP BY or aggregate function return error since shard results are not merged.
+//Operations spanning shards are not atomic, use Shard(key) for transaction.
+type ShardedManager struct {
+	shards       []Manager
+	column       string
+	shardFunc    ShardFunc
+	tableColumns map[string]string
+	provider     *shardedConnectionProvider
+}
+
+//Shards returns shard managers
+func (m *ShardedManager) Shards() []Manager {
+	return m.shards
+}
+
+//Shard returns shard manager for passed in shard key value
+func (m *ShardedManager) Shard(key interface{}) (Manager, error) {
+	index, err := m.shardIndex(key)
+	if err != nil {
+		return nil, err
+	}
+	return m.shards[index], nil
+}
+
+//SetShardColumn sets shard key column for passed in table, it overrides default shard column
+func (m *ShardedManager) SetShardColumn(table, column string) {
+	m.tableColumns[strings.ToLower(table)] = column
+}
+
+//Config returns the first shard config
+func (m *ShardedManager) Config() *Config {
+	return m.shards[0].Config()
+}
+
+//ConnectionProvider returns the first shard connection provider, closing it closes all shards connection providers
+func (m *ShardedManager) ConnectionProvider() ConnectionProvider {
+	return m.provider
+}
+
+//TableDescriptorRegistry returns the first shard table descriptor registry
+func (m *ShardedManager) TableDescriptorRegistry() TableDescriptorRegistry {
+	return m.shards[0].TableDescriptorRegistry()
+}
+
+func (m *ShardedManager) shardColumn(table string) string {
+	if column, ok := m.tableColumns[strings.ToLower(table)]; ok {
+		return column
+	}
+	return m.column
+}
+
+func (m *ShardedManager) shardIndex(key interface{}) (int, error) {
+	index, err := m.shardFunc(key, len(m.shards))
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(m.shards) {
+		return 0, fmt.Errorf("invalid shard %v for key %v, shards: %v", index, key, len(m.shards))
+	}
+	return index, nil
+}
+
+//connectionShard returns shard owning passed in connection
+func (m *ShardedManager) connectionShard(connection Connection) (Manager, error) {
+	for _, shard := range m.shards {
+		if shard.Config() == connection.Config() {
+			return shard, nil
+		}
+	}
+	return nil, errors.New("connection does not belong to any shard")
+}
+
+//itemShardKey returns shard key value of passed in map or struct item
+func itemShardKey(item interface{}, column string) (interface{}, error) {
+	if toolbox.IsMap(item) {
+		for key, value := range toolbox.AsMap(item) {
+			if strings.EqualFold(key, column) {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("shard column %v was missing", column)
+	}
+	value := reflect.ValueOf(item)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unsupported item type: %T, expected struct or map", item)
+	}
+	fieldSetting := toolbox.NewFieldSettingByKey(value.Interface(), "column")[strings.ToLower(column)]
+	fieldName, ok := fieldSetting["fieldName"]
+	if !ok {
+		return nil, fmt.Errorf("shard column %v was missing on %T", column, item)
+	}
+	field := value.FieldByName(fieldName)
+	return toolbox.UnwrapValue(&field), nil
+}
+
+//shardPartition represents items of a slice assigned to a shard
+type shardPartition struct {
+	slicePointer reflect.Value
+	indexes      []int
+}
+
+//partition splits slice items by shard, returned partitions are indexed by shard
+func (m *ShardedManager) partition(slicePointer interface{}, table string) ([]*shardPartition, error) {
+	slice := reflect.ValueOf(slicePointer)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("expected slice pointer but had: %T", slicePointer)
+	}
+	slice = slice.Elem()
+	column := m.shardColumn(table)
+	var result = make([]*shardPartition, len(m.shards))
+	for i := 0; i < slice.Len(); i++ {
+		key, err := itemShardKey(slice.Index(i).Interface(), column)
+		if err != nil {
+			return nil, err
+		}
+		index, err := m.shardIndex(key)
+		if err != nil {
+			return nil, err
+		}
+		if result[index] == nil {
+			result[index] = &shardPartition{slicePointer: reflect.New(slice.Type())}
+		}
+		partition := result[index]
+		partition.slicePointer.Elem().Set(reflect.Append(partition.slicePointer.Elem(), slice.Index(i)))
+		partition.indexes = append(partition.indexes, i)
+	}
+	return result, nil
+}
+
+//PersistAll persists all passed in data to shards by shard key, autoincrement and version values are set back on passed in slice
+func (m *ShardedManager) PersistAll(slicePointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	partitions, err := m.partition(slicePointer, table)
+	if err != nil {
+		return 0, 0, err
+	}
+	slice := reflect.ValueOf(slicePointer).Elem()
+	for i, partition := range partitions {
+		if partition == nil {
+			continue
+		}
+		shardInserted, shardUpdated, err := m.shards[i].PersistAll(partition.slicePointer.Interface(), table, provider)
+		inserted += shardInserted
+		updated += shardUpdated
+		if err != nil {
+			return inserted, updated, err
+		}
+		persisted := partition.slicePointer.Elem()
+		for j, index := range partition.indexes {
+			slice.Index(index).Set(persisted.Index(j))
+		}
+	}
+	return inserted, updated, nil
+}
+
+//PersistAllOnConnection persists all passed in data on connection shard
+func (m *ShardedManager) PersistAllOnConnection(connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return 0, 0, err
+	}
+	return shard.PersistAllOnConnection(connection, dataPointer, table, provider)
+}
+
+//PersistSingle persists single row into shard by shard key
+func (m *ShardedManager) PersistSingle(dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	key, err := itemShardKey(dataPointer, m.shardColumn(table))
+	if err != nil {
+		return 0, 0, err
+	}
+	shard, err := m.Shard(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	return shard.PersistSingle(dataPointer, table, provider)
+}
+
+//PersistSingleOnConnection persists single row on connection shard
+func (m *ShardedManager) PersistSingleOnConnection(connection Connection, dataPointer interface{}, table string, provider DmlProvider) (inserted int, updated int, err error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return 0, 0, err
+	}
+	return shard.PersistSingleOnConnection(connection, dataPointer, table, provider)
+}
+
+//PersistData persists data on connection shard
+func (m *ShardedManager) PersistData(connection Connection, data interface{}, table string, keySetter KeySetter, sqlProvider func(item interface{}) *ParametrizedSQL) (int, error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return 0, err
+	}
+	return shard.PersistData(connection, data, table, keySetter, sqlProvider)
+}
+
+//ClassifyDataAsInsertableOrUpdatable classifies data on connection shard
+func (m *ShardedManager) ClassifyDataAsInsertableOrUpdatable(connection Connection, slicePointer interface{}, table string, provider DmlProvider) (insertables, updatables []interface{}, err error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return nil, nil, err
+	}
+	return shard.ClassifyDataAsInsertableOrUpdatable(connection, slicePointer, table, provider)
+}
+
+//DeleteAll deletes all passed in data from shards by shard key
+func (m *ShardedManager) DeleteAll(slicePointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error) {
+	partitions, err := m.partition(slicePointer, table)
+	if err != nil {
+		return 0, err
+	}
+	for i, partition := range partitions {
+		if partition == nil {
+			continue
+		}
+		shardDeleted, err := m.shards[i].DeleteAll(partition.slicePointer.Interface(), table, keyProvider)
+		deleted += shardDeleted
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+//DeleteAllOnConnection deletes all passed in data on connection shard
+func (m *ShardedManager) DeleteAllOnConnection(connection Connection, resultPointer interface{}, table string, keyProvider KeyGetter) (deleted int, err error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return 0, err
+	}
+	return shard.DeleteAllOnConnection(connection, resultPointer, table, keyProvider)
+}
+
+//DeleteSingle deletes single row from shard by shard key
+func (m *ShardedManager) DeleteSingle(resultPointer interface{}, table string, keyProvider KeyGetter) (success bool, err error) {
+	key, err := itemShardKey(resultPointer, m.shardColumn(table))
+	if err != nil {
+		return false, err
+	}
+	shard, err := m.Shard(key)
+	if err != nil {
+		return false, err
+	}
+	return shard.DeleteSingle(resultPointer, table, keyProvider)
+}
+
+//DeleteSingleOnConnection deletes single row on connection shard
+func (m *ShardedManager) DeleteSingleOnConnection(connection Connection, resultPointer interface{}, table string, keyProvider KeyGetter) (success bool, err error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return false, err
+	}
+	return shard.DeleteSingleOnConnection(connection, resultPointer, table, keyProvider)
+}
+
+//criteriaShardKeys returns shard key values restricted by shard column equality or IN predicate joined with AND, criteria values are bound in order
+func criteriaShardKeys(criteria *SQLCriteria, column, alias string, parameters toolbox.Iterator) ([]interface{}, error) {
+	var result []interface{}
+	if criteria == nil {
+		return result, nil
+	}
+	conjunction := len(criteria.Criteria) == 1 || strings.EqualFold(criteria.LogicalOperator, "AND")
+	for _, criterion := range criteria.Criteria {
+		if criterion.Criteria != nil && len(criterion.Criteria.Criteria) > 0 {
+			if _, err := criterion.Criteria.CriteriaValues(parameters); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var operands = criterion.RightOperands
+		if len(operands) == 0 {
+			operands = []interface{}{criterion.RightOperand}
+		}
+		var values = make([]interface{}, 0, len(operands))
+		for _, operand := range operands {
+			value, err := bindValueIfNeeded(operand, parameters)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		operator := strings.ToUpper(criterion.Operator)
+		if !conjunction || criterion.Inverse || (operator != "=" && operator != "IN") {
+			continue
+		}
+		leftOperand := toolbox.AsString(criterion.LeftOperand)
+		if alias != "" {
+			leftOperand = strings.TrimPrefix(leftOperand, alias+".")
+		}
+		if strings.EqualFold(leftOperand, column) && result == nil {
+			result = values
+		}
+	}
+	return result, nil
+}
+
+//target returns shards for passed in SQL, all shards are returned unless SQL restricts shard column
+func (m *ShardedManager) target(SQL string, parameters []interface{}) []Manager {
+	var keys []interface{}
+	iterator := toolbox.NewSliceIterator(parameters)
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(SQL)), "SELECT") {
+		statement, err := NewQueryParser().Parse(SQL)
+		if err != nil {
+			return m.shards
+		}
+		keys, _ = criteriaShardKeys(statement.SQLCriteria, m.shardColumn(statement.Table), statement.Alias, iterator)
+	} else {
+		statement, err := NewDmlParser().Parse(SQL)
+		if err != nil {
+			return m.shards
+		}
+		column := m.shardColumn(statement.Table)
+		switch strings.ToUpper(strings.Fields(statement.Type)[0]) {
+		case "INSERT":
+			values, err := statement.ColumnValueMap(iterator)
+			if err != nil {
+				return m.shards
+			}
+			for candidate, value := range values {
+				if strings.EqualFold(candidate, column) {
+					keys = []interface{}{value}
+				}
+			}
+		case "UPDATE":
+			if _, err = statement.ColumnValues(iterator); err != nil {
+				return m.shards
+			}
+			fallthrough
+		default:
+			keys, _ = criteriaShardKeys(statement.SQLCriteria, column, statement.Alias, iterator)
+		}
+	}
+	if len(keys) == 0 {
+		return m.shards
+	}
+	var selected = make([]bool, len(m.shards))
+	for _, key := range keys {
+		index, err := m.shardIndex(key)
+		if err != nil {
+			return m.shards
+		}
+		selected[index] = true
+	}
+	var result = make([]Manager, 0)
+	for i, shard := range m.shards {
+		if selected[i] {
+			result = append(result, shard)
+		}
+	}
+	return result
+}
+
+//Execute executes SQL on shard(s) restricted by shard column predicate or insert value, or on all shards otherwise, rows affected are summed up
+func (m *ShardedManager) Execute(SQL string, parameters ...interface{}) (sql.Result, error) {
+	var rowsAffected int64
+	for _, shard := range m.target(SQL, parameters) {
+		result, err := shard.Execute(SQL, parameters...)
+		if err != nil {
+			return nil, err
+		}
+		if affected, err := result.RowsAffected(); err == nil {
+			rowsAffected += affected
+		}
+	}
+	return NewSQLResult(rowsAffected, 0), nil
+}
+
+//ExecuteAll executes all SQL
+func (m *ShardedManager) ExecuteAll(sqls []string) ([]sql.Result, error) {
+	var result = make([]sql.Result, len(sqls))
+	for i, SQL := range sqls {
+		var err error
+		if result[i], err = m.Execute(SQL); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+//ExecuteOnConnection executes SQL on connection shard
+func (m *ShardedManager) ExecuteOnConnection(connection Connection, SQL string, parameters []interface{}) (sql.Result, error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return nil, err
+	}
+	return shard.ExecuteOnConnection(connection, SQL, parameters)
+}
+
+//ExecuteAllOnConnection executes all SQL on connection shard
+func (m *ShardedManager) ExecuteAllOnConnection(connection Connection, sqls []string) ([]sql.Result, error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return nil, err
+	}
+	return shard.ExecuteAllOnConnection(connection, sqls)
+}
+
+//ReadSingle reads single row from shard restricted by shard column predicate, or the first row found in shard order
+func (m *ShardedManager) ReadSingle(resultPointer interface{}, query string, parameters []interface{}, mapper RecordMapper) (success bool, err error) {
+	shards := m.target(query, parameters)
+	if err = checkShardsQuery(query, shards); err != nil {
+		return false, err
+	}
+	for _, shard := range shards {
+		if success, err = shard.ReadSingle(resultPointer, query, parameters, mapper); err != nil || success {
+			return success, err
+		}
+	}
+	return false, nil
+}
+
+//ReadSingleOnConnection reads single row on connection shard
+func (m *ShardedManager) ReadSingleOnConnection(connection Connection, resultPointer interface{}, query string, parameters []interface{}, mapper RecordMapper) (success bool, err error) {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return false, err
+	}
+	return shard.ReadSingleOnConnection(connection, resultPointer, query, parameters, mapper)
+}
+
+//ReadAll reads all rows from shard(s) restricted by shard column predicate, or from all shards, rows are appended in shard order
+func (m *ShardedManager) ReadAll(resultSlicePointer interface{}, query string, parameters []interface{}, mapper RecordMapper) error {
+	shards := m.target(query, parameters)
+	if err := checkShardsQuery(query, shards); err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		if err := shard.ReadAll(resultSlicePointer, query, parameters, mapper); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//checkShardsQuery returns error if query read from more than one shard uses ORDER BY, LIMIT, OFFSET, GROUP BY or aggregate function, since rows are not merged across shards
+func checkShardsQuery(query string, shards []Manager) error {
+	if len(shards) < 2 {
+		return nil
+	}
+	statement, err := NewQueryParser().Parse(query)
+	if err != nil {
+		return nil
+	}
+	if len(statement.OrderBy) > 0 || statement.HasLimit || statement.Offset > 0 {
+		return fmt.Errorf("ORDER BY, LIMIT and OFFSET are not supported across %v shards, use shard column predicate or Shard(key): %v", len(shards), query)
+	}
+	if isAggregateQuery(statement) {
+		return fmt.Errorf("GROUP BY and aggregate functions are not supported across %v shards, use shard column predicate or Shard(key): %v", len(shards), query)
+	}
+	return nil
+}
+
+//ReadAllOnConnection reads all rows on connection shard
+func (m *ShardedManager) ReadAllOnConnection(connection Connection, resultSlicePointer interface{}, query string, parameters []interface{}, mapper RecordMapper) error {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return err
+	}
+	return shard.ReadAllOnConnection(connection, resultSlicePointer, query, parameters, mapper)
+}
+
+//ReadAllWithHandler reads rows from shard(s) restricted by shard column predicate, or from all shards in order, until handler stops reading
+func (m *ShardedManager) ReadAllWithHandler(query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	var stopped bool
+	shards := m.target(query, parameters)
+	if err := checkShardsQuery(query, shards); err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		err := shard.ReadAllWithHandler(query, parameters, func(scanner Scanner) (bool, error) {
+			toContinue, err := readingHandler(scanner)
+			stopped = !toContinue
+			return toContinue, err
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+//ReadAllOnWithHandlerOnConnection reads rows on connection shard
+func (m *ShardedManager) ReadAllOnWithHandlerOnConnection(connection Connection, query string, parameters []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return err
+	}
+	return shard.ReadAllOnWithHandlerOnConnection(connection, query, parameters, readingHandler)
+}
+
+//WithTransaction returns error as transaction can not span shards, use Shard(key).WithTransaction instead
+func (m *ShardedManager) WithTransaction(handler func(tx Connection) error) error {
+	return errors.New("transaction across shards is not supported, use Shard(key).WithTransaction")
+}
+
+//WithTransactionOnConnection runs handler in a transaction on connection shard
+func (m *ShardedManager) WithTransactionOnConnection(connection Connection, handler func(tx Connection) error) error {
+	shard, err := m.connectionShard(connection)
+	if err != nil {
+		return err
+	}
+	return shard.WithTransactionOnConnection(connection, handler)
+}
+
+//shardedConnectionProvider represents the first shard connection provider, it also closes other shards
+type shardedConnectionProvider struct {
+	ConnectionProvider
+	shards []Manager
+}
+
+//Close closes all shards connection providers
+func (p *shardedConnectionProvider) Close() error {
+	var err error
+	for _, shard := range p.shards {
+		if closeErr := shard.ConnectionProvider().Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+//NewShardedManager creates a new sharded manager routing by shard key column with passed in shard function, i.e. HashShardFunc
+func NewShardedManager(column string, shardFunc ShardFunc, shards ...Manager) (*ShardedManager, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("shards were empty")
+	}
+	if column == "" || shardFunc == nil {
+		return nil, errors.New("shard column and shard function are required")
+	}
+	return &ShardedManager{
+		shards:       shards,
+		column:       column,
+		shardFunc:    shardFunc,
+		tableColumns: make(map[string]string),
+		provider:     &shardedConnectionProvider{ConnectionProvider: shards[0].ConnectionProvider(), shards: shards},
+	}, nil
+}
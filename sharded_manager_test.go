@@ -0,0 +1,97 @@
+package dsc_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+func TestShardedManager(t *testing.T) {
+	var shards = make([]dsc.Manager, 0)
+	for _, URL := range []string{"./test/shard0.db", "./test/shard1.db"} {
+		shard, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:"+URL))
+		if !assert.Nil(t, err) {
+			return
+		}
+		shards = append(shards, shard)
+	}
+	manager, err := dsc.NewShardedManager("id", dsc.NewRangeShardFunc(100), shards...)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS accounts",
+		"CREATE TABLE accounts (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+
+	type Account struct {
+		Id   int `primaryKey:"true"`
+		Name string
+	}
+	var accounts = []*Account{{Id: 1, Name: "a1"}, {Id: 150, Name: "a150"}, {Id: 2, Name: "a2"}}
+	inserted, updated, err := manager.PersistAll(&accounts, "accounts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, inserted+updated)
+
+	var shardAccounts = make([]*Account, 0)
+	err = shards[1].ReadAll(&shardAccounts, "SELECT id, name FROM accounts", nil, nil)
+	assert.Nil(t, err)
+	if assert.Equal(t, 1, len(shardAccounts)) {
+		assert.Equal(t, "a150", shardAccounts[0].Name)
+	}
+
+	var account = &Account{}
+	success, err := manager.ReadSingle(account, "SELECT id, name FROM accounts WHERE id = ?", []interface{}{150}, nil)
+	assert.Nil(t, err)
+	assert.True(t, success)
+	assert.Equal(t, "a150", account.Name)
+
+	var all = make([]*Account, 0)
+	err = manager.ReadAll(&all, "SELECT id, name FROM accounts", nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(all))
+
+	var selected = make([]*Account, 0)
+	err = manager.ReadAll(&selected, "SELECT id, name FROM accounts WHERE id IN(?, ?)", []interface{}{1, 2}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(selected))
+
+	var ordered = make([]*Account, 0)
+	err = manager.ReadAll(&ordered, "SELECT id, name FROM accounts ORDER BY id LIMIT 2", nil, nil)
+	assert.NotNil(t, err)
+	err = manager.ReadAll(&ordered, "SELECT id, name FROM accounts WHERE id = ? ORDER BY id LIMIT 2", []interface{}{1}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(ordered))
+
+	var counts = make([][]interface{}, 0)
+	err = manager.ReadAll(&counts, "SELECT COUNT(*) FROM accounts", nil, nil)
+	assert.NotNil(t, err)
+	var count = make([]interface{}, 0)
+	_, err = manager.ReadSingle(&count, "SELECT COUNT(*) FROM accounts", nil, nil)
+	assert.NotNil(t, err)
+	_, err = manager.ReadSingle(&count, "SELECT COUNT(*) FROM accounts WHERE id = ?", []interface{}{1}, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []interface{}{int64(1)}, count)
+
+	result, err := manager.Execute("UPDATE accounts SET name = ? WHERE id = ?", "renamed", 2)
+	if assert.Nil(t, err) {
+		affected, _ := result.RowsAffected()
+		assert.EqualValues(t, 1, affected)
+	}
+
+	deleted, err := manager.DeleteAll(&accounts, "accounts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, deleted)
+
+	err = manager.WithTransaction(func(tx dsc.Connection) error { return nil })
+	assert.NotNil(t, err)
+	shard, err := manager.Shard(150)
+	if assert.Nil(t, err) {
+		assert.True(t, shard == shards[1])
+	}
+}
@@ -0,0 +1,87 @@
+package dsc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/viant/toolbox"
+)
+
+var softDeleteSQLTemplate = "UPDATE %v SET %v = ? WHERE %v AND %v IS NULL"
+
+type hardDeleteKey struct{}
+
+type includeDeletedKey struct{}
+
+//WithHardDelete returns context making DeleteAll and DeleteSingle context variants remove rows from soft delete table
+func WithHardDelete(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hardDeleteKey{}, true)
+}
+
+//WithDeleted returns context making ClassifyDataAsInsertableOrUpdatableContext include soft deleted rows
+func WithDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey{}, true)
+}
+
+func isHardDelete(ctx context.Context) bool {
+	hardDelete, _ := ctx.Value(hardDeleteKey{}).(bool)
+	return hardDelete
+}
+
+func isIncludeDeleted(ctx context.Context) bool {
+	includeDeleted, _ := ctx.Value(includeDeletedKey{}).(bool)
+	return includeDeleted
+}
+
+//configSoftDeleteColumn returns config soft delete column if it is one of passed in columns
+func configSoftDeleteColumn(config *Config, columns []string) string {
+	if config == nil || !config.Has(SoftDeleteColumnKey) {
+		return ""
+	}
+	column := config.Get(SoftDeleteColumnKey)
+	for _, candidate := range columns {
+		if strings.EqualFold(candidate, column) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+//softDeleteAll sets soft delete column to the current time for all not yet deleted rows, it uses pk IN criteria, composite keys use tuple IN
+func (m *AbstractManager) softDeleteAll(ctx context.Context, connection Connection, dataPointer interface{}, descriptor *TableDescriptor, keyProvider KeyGetter, batchSize int) (int, error) {
+	var pk = append([]string{}, descriptor.PkColumns...)
+	if m.reserved != nil {
+		m.reserved.quoteIfReserved(pk)
+	} else {
+		updateReserved(pk)
+	}
+	column := quoteColumn(descriptor.SoftDeleteColumn, m.reserved)
+	var keys = make([][]interface{}, 0)
+	toolbox.ProcessSlice(dataPointer, func(item interface{}) bool {
+		keys = append(keys, keyProvider.Key(item))
+		return true
+	})
+	var deleted = 0
+	var deletedAt = time.Now()
+	var rowValueIn = canUseRowValueIn(m.Config().DriverName)
+	for i := 0; i < len(keys); i += batchSize {
+		end := i + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		where, values := buildInCriteria(pk, keys[i:end], rowValueIn)
+		SQL := fmt.Sprintf(softDeleteSQLTemplate, descriptor.Table, column, where, column)
+		result, err := m.contextManager().ExecuteOnConnectionContext(withOperationHint(ctx, OperationDelete, descriptor.Table), connection, SQL, append([]interface{}{deletedAt}, values...))
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		deleted += int(affected)
+	}
+	return deleted, nil
+}
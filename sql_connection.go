@@ -1,8 +1,10 @@
 package dsc
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 	"time"
 )
 
@@ -15,9 +17,11 @@ const (
 type sqlConnection struct {
 	canHandleTransaction bool
 	*AbstractConnection
-	db   *sql.DB
-	tx   *sql.Tx
-	init bool
+	db         *sql.DB
+	tx         *sql.Tx
+	init       bool
+	savepoints int
+	callbacks  []func(committed bool)
 }
 
 func (c *sqlConnection) CloseNow() error {
@@ -45,6 +49,53 @@ func (c *sqlConnection) Begin() error {
 	return nil
 }
 
+func (c *sqlConnection) ping(ctx context.Context) error {
+	db, err := asSQLDb(c.db)
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+func (c *sqlConnection) inTransaction() bool {
+	return c.tx != nil
+}
+
+func (c *sqlConnection) nextSavepoint() string {
+	c.savepoints++
+	return fmt.Sprintf("dsc_sp_%v", c.savepoints)
+}
+
+func (c *sqlConnection) afterTransaction(callback func(committed bool)) {
+	c.callbacks = append(c.callbacks, callback)
+}
+
+func (c *sqlConnection) callbackMark() int {
+	return len(c.callbacks)
+}
+
+func (c *sqlConnection) rollbackCallbacks(mark int) {
+	if mark >= len(c.callbacks) {
+		return
+	}
+	callbacks := c.callbacks[mark:]
+	c.callbacks = c.callbacks[:mark:mark]
+	for _, callback := range callbacks {
+		callback(false)
+	}
+}
+
+//endTransaction clears transaction state and runs after transaction callbacks
+func (c *sqlConnection) endTransaction(committed bool) {
+	c.tx = nil
+	c.savepoints = 0
+	callbacks := c.callbacks
+	c.callbacks = nil
+	for _, callback := range callbacks {
+		callback(committed)
+	}
+}
+
 func (c *sqlConnection) Unwrap(target interface{}) interface{} {
 	if target == sqlDbPointer {
 		return c.db
@@ -62,7 +113,7 @@ func (c *sqlConnection) Commit() error {
 		return fmt.Errorf("no active transaction")
 	}
 	err := c.tx.Commit()
-	c.tx = nil
+	c.endTransaction(err == nil)
 	return err
 }
 
@@ -74,7 +125,7 @@ func (c *sqlConnection) Rollback() error {
 		return fmt.Errorf("no active transaction")
 	}
 	err := c.tx.Rollback()
-	c.tx = nil
+	c.endTransaction(false)
 	return err
 }
 
@@ -90,15 +141,24 @@ func (c *sqlConnectionProvider) NewConnection() (Connection, error) {
 	}
 	db, err := sql.Open(config.DriverName, dsn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open connection to %v on %v due to %v", config.DriverName, config.Descriptor, err)
+		return nil, fmt.Errorf("%w: failed to open connection to %v on %v due to %w", ErrConnection, config.DriverName, config.Descriptor, err)
 	}
 	if len(config.InitSQL) > 0 {
 		for _, SQL := range config.InitSQL {
 			if _, err = db.Exec(SQL); err != nil {
-				return nil, fmt.Errorf("failed to execute init SQL %v on %v due to %v", SQL, config.Descriptor, err)
+				return nil, fmt.Errorf("failed to execute init SQL %v on %v due to %w", SQL, config.Descriptor, err)
 			}
 		}
 	}
+	if config.Has(connMaxLifetimeMsKey) {
+		connMaxLifetime := config.GetDuration(connMaxLifetimeMsKey, time.Millisecond, defaultConnMaxLifetimeMs)
+		if connMaxLifetime != 0 {
+			db.SetConnMaxLifetime(connMaxLifetime)
+		}
+	}
+	if config.Has(maxIdleConnsKey) {
+		db.SetMaxIdleConns(config.GetInt(maxIdleConnsKey, 1))
+	}
 	dialect := GetDatastoreDialect(config.DriverName)
 	var sqlConnection = &sqlConnection{db: db, canHandleTransaction: dialect.CanHandleTransaction()}
 	var connection Connection = sqlConnection
@@ -109,37 +169,24 @@ func (c *sqlConnectionProvider) NewConnection() (Connection, error) {
 }
 
 func (c *sqlConnectionProvider) Get() (Connection, error) {
-	result, err := c.AbstractConnectionProvider.Get()
-	if err != nil {
-		return nil, err
-	}
-	db, err := asSQLDb(result.Unwrap(sqlDbPointer))
+	return c.GetContext(context.Background())
+}
+
+func (c *sqlConnectionProvider) GetContext(ctx context.Context) (Connection, error) {
+	result, err := c.AbstractConnectionProvider.GetContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	if result.LastUsed() != nil && (time.Now().Sub(*result.LastUsed()) > 60*time.Second) {
-		err = db.Ping()
-	}
-
-	if err == nil {
+	if result.LastUsed() == nil || time.Now().Sub(*result.LastUsed()) <= 60*time.Second {
 		return result, nil
 	}
-
-	if c.config.Has(connMaxLifetimeMsKey) {
-		connMaxLifetime := c.config.GetDuration(connMaxLifetimeMsKey, time.Millisecond, defaultConnMaxLifetimeMs)
-		if connMaxLifetime != 0 {
-			db.SetConnMaxLifetime(connMaxLifetime)
+	if pingable, ok := result.(pingableConnection); ok {
+		if err = pingable.ping(ctx); err != nil {
+			c.config.logger().Log(ctx, slog.LevelWarn, "dsc: discarding stale connection", "driver", c.config.DriverName, "error", err)
+			_ = c.pool.discard(result)
+			return c.AbstractConnectionProvider.GetContext(ctx)
 		}
 	}
-	if c.config.Has(maxIdleConnsKey) {
-		db.SetMaxIdleConns(c.config.GetInt(maxIdleConnsKey, 1))
-	}
-
-	result, err = c.NewConnection()
-	if err != nil {
-		return nil, err
-	}
 	return result, nil
 }
 
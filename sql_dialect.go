@@ -1,10 +1,13 @@
 package dsc
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/viant/toolbox"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -32,6 +35,55 @@ FROM information_schema.columns
 WHERE  table_name = '%s' AND table_schema = '%s' 
 ORDER BY ordinal_position`
 
+const ansiForeignKeySQL = `SELECT k.constraint_name AS name, k.column_name AS column_name, r.table_name AS referenced_table, r.column_name AS referenced_column,
+	rc.delete_rule AS on_delete, rc.update_rule AS on_update
+FROM information_schema.referential_constraints rc
+JOIN information_schema.key_column_usage k ON k.constraint_schema = rc.constraint_schema AND k.constraint_name = rc.constraint_name
+JOIN information_schema.key_column_usage r ON r.constraint_schema = rc.unique_constraint_schema AND r.constraint_name = rc.unique_constraint_name
+	AND r.ordinal_position = k.position_in_unique_constraint
+WHERE k.table_name = '%v' AND k.table_schema = '%v'
+ORDER BY k.constraint_name, k.ordinal_position`
+
+const ansiConstraintSQL = `SELECT tc.constraint_name AS name, tc.constraint_type AS constraint_type, k.column_name AS column_name, cc.check_clause AS expression
+FROM information_schema.table_constraints tc
+LEFT JOIN information_schema.key_column_usage k ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name AND k.table_name = tc.table_name
+LEFT JOIN information_schema.check_constraints cc ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+WHERE tc.table_name = '%v' AND tc.table_schema = '%v'
+ORDER BY tc.constraint_name, k.ordinal_position`
+
+const mysqlIndexSQL = `SELECT index_name AS name, column_name AS column_name, CASE WHEN non_unique = 0 THEN 1 ELSE 0 END AS is_unique, CASE WHEN index_name = 'PRIMARY' THEN 1 ELSE 0 END AS is_primary
+FROM information_schema.statistics
+WHERE table_name = '%v' AND table_schema = '%v'
+ORDER BY index_name, seq_in_index`
+
+const mysqlForeignKeySQL = `SELECT k.constraint_name AS name, k.column_name AS column_name, k.referenced_table_name AS referenced_table, k.referenced_column_name AS referenced_column,
+	r.delete_rule AS on_delete, r.update_rule AS on_update
+FROM information_schema.key_column_usage k
+JOIN information_schema.referential_constraints r ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name AND r.table_name = k.table_name
+WHERE k.table_name = '%v' AND k.table_schema = '%v' AND k.referenced_table_name IS NOT NULL
+ORDER BY k.constraint_name, k.ordinal_position`
+
+const msSQLIndexSQL = `SELECT i.name AS name, c.name AS column_name, i.is_unique AS is_unique, i.is_primary_key AS is_primary
+FROM sys.indexes i
+JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+JOIN sys.tables t ON t.object_id = i.object_id
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+WHERE t.name = '%v' AND s.name = '%v' AND ic.is_included_column = 0
+ORDER BY i.name, ic.key_ordinal`
+
+const msSQLForeignKeySQL = `SELECT f.name AS name, c.name AS column_name, rt.name AS referenced_table, rc.name AS referenced_column,
+	REPLACE(f.delete_referential_action_desc, '_', ' ') AS on_delete, REPLACE(f.update_referential_action_desc, '_', ' ') AS on_update
+FROM sys.foreign_keys f
+JOIN sys.foreign_key_columns fc ON fc.constraint_object_id = f.object_id
+JOIN sys.columns c ON c.object_id = fc.parent_object_id AND c.column_id = fc.parent_column_id
+JOIN sys.tables rt ON rt.object_id = fc.referenced_object_id
+JOIN sys.columns rc ON rc.object_id = fc.referenced_object_id AND rc.column_id = fc.referenced_column_id
+JOIN sys.tables t ON t.object_id = f.parent_object_id
+JOIN sys.schemas s ON s.schema_id = t.schema_id
+WHERE t.name = '%v' AND s.name = '%v'
+ORDER BY f.name, fc.constraint_column_id`
+
 const casandraVersion = "SELECT cql_version AS version from system.local"
 const casandraSchemaListV3SQL = "SELECT keyspace_name as name FROM system_schema.keyspaces"
 const casandraSchemaListV2SQL = "SELECT keyspace_name AS name FROM system.schema_keyspaces"
@@ -52,6 +104,9 @@ const sqlLightTableSQL = "SELECT name FROM SQLITE_MASTER WHERE type='table' AND
 const sqlLightSequenceSQL = "SELECT COALESCE(MAX(name), 0) + 1   FROM (SELECT seq AS name FROM SQLITE_SEQUENCE WHERE name = '%v')"
 const sqlLightSchemaSQL = "PRAGMA database_list"
 const sqlLightPkSQL = "pragma table_info(%v);"
+const sqlLightIndexListSQL = "pragma index_list(%v);"
+const sqlLightIndexInfoSQL = "pragma index_info(%v);"
+const sqlLightForeignKeySQL = "pragma foreign_key_list(%v);"
 
 const pgCurrentSchemaSQL = "SELECT current_database() AS name"
 const pgSchemaListSQL = "SELECT datname AS name FROM pg_catalog.pg_database"
@@ -77,6 +132,32 @@ WHERE u.table_name =  c.table_name
 	AND c.table_catalog = '%v'
 `
 
+const pgIndexSQL = `SELECT i.relname AS name, a.attname AS column_name, ix.indisunique AS is_unique, ix.indisprimary AS is_primary
+FROM pg_index ix
+JOIN pg_class t ON t.oid = ix.indrelid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_namespace n ON n.oid = t.relnamespace
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, position) ON TRUE
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE t.relname = '%v' AND current_database() = '%v' AND n.nspname = 'public'
+ORDER BY i.relname, k.position`
+
+const pgForeignKeySQL = `SELECT k.constraint_name AS name, k.column_name AS column_name, r.table_name AS referenced_table, r.column_name AS referenced_column,
+	rc.delete_rule AS on_delete, rc.update_rule AS on_update
+FROM information_schema.referential_constraints rc
+JOIN information_schema.key_column_usage k ON k.constraint_schema = rc.constraint_schema AND k.constraint_name = rc.constraint_name
+JOIN information_schema.key_column_usage r ON r.constraint_schema = rc.unique_constraint_schema AND r.constraint_name = rc.unique_constraint_name
+	AND r.ordinal_position = k.position_in_unique_constraint
+WHERE k.table_name = '%v' AND k.table_catalog = '%v' AND k.table_schema = 'public'
+ORDER BY k.constraint_name, k.ordinal_position`
+
+const pgConstraintSQL = `SELECT tc.constraint_name AS name, tc.constraint_type AS constraint_type, k.column_name AS column_name, cc.check_clause AS expression
+FROM information_schema.table_constraints tc
+LEFT JOIN information_schema.key_column_usage k ON k.constraint_schema = tc.constraint_schema AND k.constraint_name = tc.constraint_name AND k.table_name = tc.table_name
+LEFT JOIN information_schema.check_constraints cc ON cc.constraint_schema = tc.constraint_schema AND cc.constraint_name = tc.constraint_name
+WHERE tc.table_name = '%v' AND tc.table_catalog = '%v' AND tc.table_schema = 'public' AND tc.constraint_name NOT LIKE '%%_not_null'
+ORDER BY tc.constraint_name, k.ordinal_position`
+
 const oraTableSQL = `SELECT table_name AS "name" FROM all_tables WHERE owner = ?`
 const oraSchemaSQL = `SELECT sys_context( 'userenv', 'current_schema' ) AS "name" FROM dual`
 const oraSchemaListSQL = `SELECT USERNAME AS "name"  FROM ALL_USERS`
@@ -101,6 +182,32 @@ WHERE UPPER(TABLE_NAME) = UPPER('%s') AND OWNER = '%s'
 ORDER BY COLUMN_ID
 `
 
+const oraIndexSQL = `SELECT i.index_name AS "name", c.column_name AS "column_name", CASE WHEN i.uniqueness = 'UNIQUE' THEN 1 ELSE 0 END AS "is_unique",
+	CASE WHEN p.constraint_name IS NOT NULL THEN 1 ELSE 0 END AS "is_primary"
+FROM all_indexes i
+JOIN all_ind_columns c ON c.index_owner = i.owner AND c.index_name = i.index_name
+LEFT JOIN all_constraints p ON p.owner = i.table_owner AND p.index_name = i.index_name AND p.constraint_type = 'P'
+WHERE i.table_name = UPPER('%v') AND i.table_owner = UPPER('%v')
+ORDER BY i.index_name, c.column_position`
+
+const oraForeignKeySQL = `SELECT c.constraint_name AS "name", cc.column_name AS "column_name", r.table_name AS "referenced_table", rc.column_name AS "referenced_column",
+	c.delete_rule AS "on_delete", 'NO ACTION' AS "on_update"
+FROM all_constraints c
+JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+JOIN all_constraints r ON r.owner = c.r_owner AND r.constraint_name = c.r_constraint_name
+JOIN all_cons_columns rc ON rc.owner = r.owner AND rc.constraint_name = r.constraint_name AND rc.position = cc.position
+WHERE c.constraint_type = 'R' AND c.table_name = UPPER('%v') AND c.owner = UPPER('%v')
+ORDER BY c.constraint_name, cc.position`
+
+const oraConstraintSQL = `SELECT c.constraint_name AS "name",
+	CASE c.constraint_type WHEN 'P' THEN 'PRIMARY KEY' WHEN 'U' THEN 'UNIQUE' WHEN 'R' THEN 'FOREIGN KEY' ELSE 'CHECK' END AS "constraint_type",
+	cc.column_name AS "column_name", c.search_condition_vc AS "expression"
+FROM all_constraints c
+LEFT JOIN all_cons_columns cc ON cc.owner = c.owner AND cc.constraint_name = c.constraint_name
+WHERE c.constraint_type IN ('P', 'U', 'R', 'C') AND c.table_name = UPPER('%v') AND c.owner = UPPER('%v')
+	AND NOT (c.constraint_type = 'C' AND c.search_condition_vc LIKE '%%IS NOT NULL')
+ORDER BY c.constraint_name, cc.position`
+
 /*
 
  ` SELECT
@@ -126,6 +233,20 @@ FROM v_catalog.columns
 WHERE table_name = '%s' AND  table_schema = '%s' 
 ORDER BY ordinal_position`
 
+const verticaForeignKeySQL = `SELECT constraint_name AS name, column_name, reference_table_name AS referenced_table, reference_column_name AS referenced_column,
+	'NO ACTION' AS on_delete, 'NO ACTION' AS on_update
+FROM v_catalog.constraint_columns
+WHERE constraint_type = 'f' AND table_name = '%v' AND table_schema = '%v'
+ORDER BY constraint_name`
+
+const verticaConstraintSQL = `SELECT c.constraint_name AS name,
+	CASE c.constraint_type WHEN 'p' THEN 'PRIMARY KEY' WHEN 'u' THEN 'UNIQUE' WHEN 'f' THEN 'FOREIGN KEY' ELSE 'CHECK' END AS constraint_type,
+	c.column_name, k.predicate AS expression
+FROM v_catalog.constraint_columns c
+LEFT JOIN v_catalog.check_constraints k ON k.constraint_id = c.constraint_id
+WHERE c.constraint_type IN ('p', 'u', 'f', 'c') AND c.table_name = '%v' AND c.table_schema = '%v'
+ORDER BY c.constraint_name`
+
 const verticaSchemaSQL = "SELECT DISTINCT SCHEMA_NAME AS name FROM v_catalog.schemata"
 const verticaTableListSQL = "SELECT table_name AS name FROM  v_catalog.tables WHERE table_schema = ?"
 const verticaCurrentSchema = "SELECT current_schema"
@@ -180,7 +301,7 @@ func (d *sqlDatastoreDialect) ShowCreateTable(manager Manager, table string) (st
 	for _, column := range columns {
 		var dataType = column.DatabaseTypeName()
 		ddlColumn := fmt.Sprintf("%v %v", column.Name(), dataType)
-		if nullable, ok := column.Nullable(); ok && !nullable {
+		if nullable, ok := column.Nullable(); ok && !nullable && !indexPk[column.Name()] { //primary key implies NOT NULL
 			ddlColumn += " NOT NULL "
 		}
 		if indexPk[column.Name()] {
@@ -226,6 +347,54 @@ func (d sqlDatastoreDialect) CanHandleTransaction() bool {
 	return true
 }
 
+//CanUseSavepoint returns true if dialect supports savepoints within a transaction
+func (d sqlDatastoreDialect) CanUseSavepoint() bool {
+	return true
+}
+
+//DeferConstraintsSQL returns empty string, constraints are checked immediately
+func (d sqlDatastoreDialect) DeferConstraintsSQL() string {
+	return ""
+}
+
+//CanUseRowValueIn returns true, composite keys use row value IN criteria
+func (d sqlDatastoreDialect) CanUseRowValueIn() bool {
+	return true
+}
+
+//SavepointSQL returns ANSI savepoint statement for passed in action and name
+func (d sqlDatastoreDialect) SavepointSQL(action int, name string) string {
+	switch action {
+	case SavepointCreate:
+		return "SAVEPOINT " + name
+	case SavepointRollback:
+		return "ROLLBACK TO SAVEPOINT " + name
+	case SavepointRelease:
+		return "RELEASE SAVEPOINT " + name
+	}
+	return ""
+}
+
+//ReturningSQL returns empty string, generated keys are read with LastInsertId
+func (d sqlDatastoreDialect) ReturningSQL(columns []string) string {
+	return ""
+}
+
+//UpsertSQL returns empty string, upsert is supported only by vendor specific dialects
+func (d sqlDatastoreDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	return ""
+}
+
+//TranslateError returns error kind for database/sql, network and SQLSTATE errors
+func (d sqlDatastoreDialect) TranslateError(err error) error {
+	return translateCommonError(err)
+}
+
+//IsRetryable returns true for deadlock and connection errors
+func (d sqlDatastoreDialect) IsRetryable(err error) bool {
+	return isTransientError(err)
+}
+
 //CanDropDatastore returns true if this dialect can create datastore
 func (d sqlDatastoreDialect) CanCreateDatastore(manager Manager) bool {
 	return true
@@ -402,12 +571,34 @@ func (d sqlDatastoreDialect) DropTable(manager Manager, datastore string, table
 	return err
 }
 
-//CreateTable creates table on in datastore managed by manager.
+//CreateTable creates table on in datastore managed by manager, specification is either columns definition text, or *TableDescriptor or struct instance used to build DDL.
 func (d sqlDatastoreDialect) CreateTable(manager Manager, datastore string, table string, specification interface{}) error {
-	_, err := manager.Execute(fmt.Sprintf("CREATE TABLE %v(%v)", table, specification))
+	if _, ok := specification.(string); ok {
+		_, err := manager.Execute(fmt.Sprintf("CREATE TABLE %v(%v)", table, specification))
+		return err
+	}
+	descriptor, err := tableDescriptorFor(table, specification)
+	if err != nil {
+		return err
+	}
+	DDL, err := d.DatastoreDialect.CreateTableSQL(descriptor)
+	if err != nil {
+		return err
+	}
+	_, err = manager.Execute(DDL)
 	return err
 }
 
+//CreateTableSQL returns CREATE TABLE statement with ANSI column types
+func (d sqlDatastoreDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return ansiDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with ANSI syntax
+func (d sqlDatastoreDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return ansiDDLSpec.alterTableSQL(descriptor, diff)
+}
+
 //GetTables return tables names for passed in datastore managed by manager.
 func (d sqlDatastoreDialect) GetTables(manager Manager, datastore string) ([]string, error) {
 	var rows = make([]nameRecord, 0)
@@ -458,6 +649,21 @@ func (d sqlDatastoreDialect) GetKeyName(manager Manager, datastore, table string
 	return strings.Join(result, ",")
 }
 
+//GetIndexes returns error, there is no ANSI index catalog
+func (d sqlDatastoreDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return nil, errUnsupportedOperation
+}
+
+//GetForeignKeys returns foreign keys from information_schema
+func (d sqlDatastoreDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(ansiForeignKeySQL, table, datastore))
+}
+
+//GetConstraints returns constraints from information_schema
+func (d sqlDatastoreDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return readConstraints(manager, table, fmt.Sprintf(ansiConstraintSQL, table, datastore))
+}
+
 //GetDatastores returns name of datastores, takes  manager as parameter
 func (d sqlDatastoreDialect) GetDatastores(manager Manager) ([]string, error) {
 	var rows = make([][]interface{}, 0)
@@ -592,6 +798,48 @@ func (d mySQLDialect) CanPersistBatch() bool {
 	return true
 }
 
+//UpsertSQL returns INSERT ... ON DUPLICATE KEY UPDATE statement
+func (d mySQLDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	var assignments = make([]string, 0)
+	for _, column := range nonKeyColumns(columns, pkColumns) {
+		assignments = append(assignments, column+" = VALUES("+column+")")
+	}
+	if len(assignments) == 0 {
+		assignments = append(assignments, pkColumns[0]+" = "+pkColumns[0])
+	}
+	return insertValuesSQL(table, columns) + " ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", ")
+}
+
+//TranslateError returns error kind for MySQL error numbers
+func (d mySQLDialect) TranslateError(err error) error {
+	return translateErrorWithPatterns(err, mySQLErrorPatterns)
+}
+
+//IsRetryable returns true for deadlock, connection and lock wait timeout errors, InnoDB rolls back only the statement on lock wait timeout
+func (d mySQLDialect) IsRetryable(err error) bool {
+	return isTransientError(err) || (errors.Is(err, ErrTimeout) && !errors.Is(err, context.DeadlineExceeded))
+}
+
+//CreateTableSQL returns CREATE TABLE statement with MySQL column types and AUTO_INCREMENT
+func (d mySQLDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return mySQLDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with MySQL syntax, primary key is dropped with DROP PRIMARY KEY
+func (d mySQLDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return mySQLDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//GetIndexes returns indexes from information_schema.statistics
+func (d mySQLDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return readIndexes(manager, table, fmt.Sprintf(mysqlIndexSQL, table, datastore))
+}
+
+//GetForeignKeys returns foreign keys from information_schema, MySQL key_column_usage holds referenced columns
+func (d mySQLDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(mysqlForeignKeySQL, table, datastore))
+}
+
 func newMySQLDialect() mySQLDialect {
 	var result = mySQLDialect{}
 	sqlDialect := NewSQLDatastoreDialect(ansiTableListSQL, ansiSequenceSQL, defaultSchemaSQL, ansiSchemaListSQL, ansiPrimaryKeySQL, mysqlDisableForeignCheck, mysqlEnableForeignCheck, defaultAutoincremetSQL, ansiTableInfo, 0, result)
@@ -706,6 +954,15 @@ func (d casandraSQLDialect) CreateDatastore(manager Manager, datastore string) e
 	return err
 }
 
+//UpsertSQL returns INSERT statement, cassandra insert updates existing rows
+func (d casandraSQLDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	return insertValuesSQL(table, columns)
+}
+
+func (d casandraSQLDialect) CanUseSavepoint() bool {
+	return false
+}
+
 func (d casandraSQLDialect) CanHandleTransaction() bool {
 	return false
 }
@@ -779,6 +1036,21 @@ func (d casandraSQLDialect) GetCurrentDatastore(manager Manager) (string, error)
 	return keySpace, nil
 }
 
+//GetIndexes returns empty indexes, secondary indexes are not reported
+func (d casandraSQLDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return []*Index{}, nil
+}
+
+//GetForeignKeys returns empty foreign keys, Cassandra has no foreign keys
+func (d casandraSQLDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return []*ForeignKey{}, nil
+}
+
+//GetConstraints returns empty constraints, Cassandra has no constraints
+func (d casandraSQLDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return []*Constraint{}, nil
+}
+
 func newCasandraDialect() *casandraSQLDialect {
 	var result = &casandraSQLDialect{}
 	sqlDialect := NewSQLDatastoreDialect(casandraTableListV3SQL, ansiSequenceSQL, "", casandraSchemaListV3SQL, casandraPrimaryKeyV3SQL, "", "", "", casandraTableInfoV3SQL, 0, result)
@@ -852,6 +1124,152 @@ func (d sqlLiteDialect) GetKeyName(manager Manager, datastore, table string) str
 	return strings.Join(result, ",")
 }
 
+//GetColumns returns columns with declared type and nullability from table_info pragma, driver column types always report nullable columns
+func (d sqlLiteDialect) GetColumns(manager Manager, datastore, table string) ([]Column, error) {
+	var records = make([]map[string]interface{}, 0)
+	if err := manager.ReadAll(&records, fmt.Sprintf(sqlLightPkSQL, table), []interface{}{}, nil); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no such table: %v", table)
+	}
+	var result = make([]Column, 0, len(records))
+	for _, item := range records {
+		nullable := toolbox.AsString(item["notnull"]) == "0" && toolbox.AsString(item["pk"]) == "0"
+		result = append(result, &TableColumn{
+			ColumnName: toolbox.AsString(item["name"]),
+			DataType:   toolbox.AsString(item["type"]),
+			IsNullable: nullable,
+			Position:   toolbox.AsInt(item["cid"]),
+		})
+	}
+	return result, nil
+}
+
+//UpsertSQL returns INSERT ... ON CONFLICT DO UPDATE statement
+func (d sqlLiteDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	return onConflictUpsertSQL(table, columns, pkColumns)
+}
+
+//TranslateError returns error kind for SQLite error messages
+func (d sqlLiteDialect) TranslateError(err error) error {
+	return translateErrorWithPatterns(err, sqlLiteErrorPatterns)
+}
+
+//CreateTableSQL returns CREATE TABLE statement with SQLite column types, autoincrement key is declared as INTEGER PRIMARY KEY AUTOINCREMENT
+func (d sqlLiteDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return sqlLiteDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with SQLite syntax, SQLite can only add and drop columns
+func (d sqlLiteDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return sqlLiteDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//sqlLitePkColumns returns primary key columns in key order
+func sqlLitePkColumns(manager Manager, table string) ([]string, error) {
+	records, err := readCatalogRecords(manager, fmt.Sprintf(sqlLightPkSQL, table))
+	if err != nil {
+		return nil, err
+	}
+	var columns = make(map[int]string)
+	for _, record := range records {
+		if position := toolbox.AsInt(record["pk"]); position > 0 {
+			columns[position] = catalogString(record, "name")
+		}
+	}
+	var result = make([]string, 0, len(columns))
+	for i := 1; i <= len(columns); i++ {
+		result = append(result, columns[i])
+	}
+	return result, nil
+}
+
+//GetIndexes returns indexes from index_list and index_info pragmas, INTEGER PRIMARY KEY column is an alias of rowid and has no index
+func (d sqlLiteDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	records, err := readCatalogRecords(manager, fmt.Sprintf(sqlLightIndexListSQL, table))
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*Index, 0, len(records))
+	for _, record := range records {
+		var index = &Index{Name: catalogString(record, "name"), Table: table, Columns: make([]string, 0), Unique: toolbox.AsBoolean(record["unique"]), Primary: catalogString(record, "origin") == "pk"}
+		columns, err := readCatalogRecords(manager, fmt.Sprintf(sqlLightIndexInfoSQL, index.Name))
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range columns {
+			index.Columns = append(index.Columns, catalogString(column, "name"))
+		}
+		result = append(result, index)
+	}
+	return result, nil
+}
+
+//GetForeignKeys returns foreign keys from foreign_key_list pragma, SQLite does not keep foreign key names, keys are named fk_<table>_<id>
+func (d sqlLiteDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	records, err := readCatalogRecords(manager, fmt.Sprintf(sqlLightForeignKeySQL, table))
+	if err != nil {
+		return nil, err
+	}
+	var result = make([]*ForeignKey, 0)
+	var keys = make(map[string]*ForeignKey)
+	for _, record := range records {
+		name := fmt.Sprintf("fk_%v_%v", table, catalogString(record, "id"))
+		key, ok := keys[name]
+		if !ok {
+			key = &ForeignKey{Name: name, Table: table, Columns: make([]string, 0), ReferencedTable: catalogString(record, "table"), ReferencedColumns: make([]string, 0),
+				OnDelete: strings.ToUpper(catalogString(record, "on_delete")), OnUpdate: strings.ToUpper(catalogString(record, "on_update"))}
+			keys[name] = key
+			result = append(result, key)
+		}
+		key.Columns = append(key.Columns, catalogString(record, "from"))
+		key.ReferencedColumns = append(key.ReferencedColumns, catalogString(record, "to"))
+	}
+	for _, key := range result {
+		if hasColumn(key.ReferencedColumns, "") { //key references parent primary key implicitly
+			if key.ReferencedColumns, err = sqlLitePkColumns(manager, key.ReferencedTable); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//GetConstraints returns primary key, unique and foreign key constraints, check constraints are not reported as SQLite keeps them only in table DDL
+func (d sqlLiteDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	var result = make([]*Constraint, 0)
+	pkColumns, err := sqlLitePkColumns(manager, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkColumns) > 0 {
+		result = append(result, &Constraint{Name: "pk_" + table, Table: table, Type: ConstraintPrimaryKey, Columns: pkColumns})
+	}
+	indexes, err := d.GetIndexes(manager, datastore, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		if index.Unique && !index.Primary {
+			result = append(result, &Constraint{Name: index.Name, Table: table, Type: ConstraintUnique, Columns: index.Columns})
+		}
+	}
+	keys, err := d.GetForeignKeys(manager, datastore, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		result = append(result, &Constraint{Name: key.Name, Table: table, Type: ConstraintForeignKey, Columns: key.Columns})
+	}
+	return result, nil
+}
+
+//DeferConstraintsSQL returns pragma deferring foreign key checks until transaction commit
+func (d sqlLiteDialect) DeferConstraintsSQL() string {
+	return "PRAGMA defer_foreign_keys = ON"
+}
+
 func newSQLLiteDialect() *sqlLiteDialect {
 	result := &sqlLiteDialect{}
 	sqlDialect := NewSQLDatastoreDialect(sqlLightTableSQL, sqlLightSequenceSQL, sqlLightSchemaSQL, sqlLightSchemaSQL, sqlLightPkSQL, "", "", "", ansiTableInfo, 2, result)
@@ -868,6 +1286,51 @@ func (d pgDialect) CanPersistBatch() bool {
 	return true
 }
 
+//ReturningSQL returns RETURNING clause, postgres drivers do not support LastInsertId
+func (d pgDialect) ReturningSQL(columns []string) string {
+	return " RETURNING " + strings.Join(columns, ", ")
+}
+
+//UpsertSQL returns INSERT ... ON CONFLICT DO UPDATE statement
+func (d pgDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	return onConflictUpsertSQL(table, columns, pkColumns)
+}
+
+//TranslateError returns error kind for SQLSTATE codes and PostgreSQL error messages
+func (d pgDialect) TranslateError(err error) error {
+	return translateErrorWithPatterns(err, pgErrorPatterns)
+}
+
+//CreateTableSQL returns CREATE TABLE statement with PostgreSQL column types, autoincrement key uses BIGSERIAL
+func (d pgDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return pgDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with PostgreSQL syntax
+func (d pgDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return pgDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//GetIndexes returns indexes from pg_index catalog
+func (d pgDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return readIndexes(manager, table, fmt.Sprintf(pgIndexSQL, table, datastore))
+}
+
+//GetForeignKeys returns foreign keys from information_schema of current database public schema
+func (d pgDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(pgForeignKeySQL, table, datastore))
+}
+
+//GetConstraints returns constraints from information_schema of current database public schema, NOT NULL checks are skipped
+func (d pgDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return readConstraints(manager, table, fmt.Sprintf(pgConstraintSQL, table, datastore))
+}
+
+//DeferConstraintsSQL returns statement deferring constraints declared as DEFERRABLE
+func (d pgDialect) DeferConstraintsSQL() string {
+	return "SET CONSTRAINTS ALL DEFERRED"
+}
+
 func newPgDialect() *pgDialect {
 	result := &pgDialect{}
 	sqlDialect := NewSQLDatastoreDialect(pgTableListSQL, "", pgCurrentSchemaSQL, pgSchemaListSQL, pgPrimaryKeySQL, "", "", pgAutoincrementSQL, ansiTableInfo, 0, result)
@@ -876,19 +1339,27 @@ func newPgDialect() *pgDialect {
 	return result
 }
 
+//NormalizeSQL replaces ? placeholders with $n positional parameters
 func (d pgDialect) NormalizeSQL(SQL string) string {
-	count := 1
-	var normalizedSQL = ""
-	for _, r := range SQL {
-		aChar := string(r)
-		if aChar == "?" {
-			normalizedSQL += "$" + toolbox.AsString(count)
-			count++
-		} else {
-			normalizedSQL += aChar
+	count := strings.Count(SQL, "?")
+	if count == 0 {
+		return SQL
+	}
+	var normalizedSQL = strings.Builder{}
+	normalizedSQL.Grow(len(SQL) + count*len(strconv.Itoa(count)))
+	index := 1
+	for {
+		position := strings.IndexByte(SQL, '?')
+		if position == -1 {
+			normalizedSQL.WriteString(SQL)
+			return normalizedSQL.String()
 		}
+		normalizedSQL.WriteString(SQL[:position])
+		normalizedSQL.WriteByte('$')
+		normalizedSQL.WriteString(strconv.Itoa(index))
+		index++
+		SQL = SQL[position+1:]
 	}
-	return normalizedSQL
 }
 
 func (d pgDialect) IsAutoincrement(manager Manager, datastore, table string) bool {
@@ -991,6 +1462,61 @@ func (d msSQLDialect) NormalizeSQL(SQL string) string {
 	return normalizedSQL
 }
 
+//SavepointSQL returns savepoint statement, oracle does not release savepoints
+func (d oraDialect) SavepointSQL(action int, name string) string {
+	switch action {
+	case SavepointCreate:
+		return "SAVEPOINT " + name
+	case SavepointRollback:
+		return "ROLLBACK TO SAVEPOINT " + name
+	}
+	return ""
+}
+
+//UpsertSQL returns MERGE statement
+func (d oraDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	var selection = make([]string, len(columns))
+	for i, column := range columns {
+		selection[i] = "? AS " + column
+	}
+	return mergeUpsertSQL(table, "(SELECT "+strings.Join(selection, ", ")+" FROM DUAL) s", columns, pkColumns)
+}
+
+//TranslateError returns error kind for ORA error codes
+func (d oraDialect) TranslateError(err error) error {
+	return translateErrorWithPatterns(err, oraErrorPatterns)
+}
+
+//CreateTableSQL returns CREATE TABLE statement with Oracle column types and identity column (12c+)
+func (d oraDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return oraDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with Oracle syntax
+func (d oraDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return oraDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//GetIndexes returns indexes from all_indexes
+func (d oraDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return readIndexes(manager, table, fmt.Sprintf(oraIndexSQL, table, datastore))
+}
+
+//GetForeignKeys returns foreign keys from all_constraints, Oracle has no ON UPDATE action
+func (d oraDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(oraForeignKeySQL, table, datastore))
+}
+
+//GetConstraints returns constraints from all_constraints, NOT NULL checks are skipped
+func (d oraDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return readConstraints(manager, table, fmt.Sprintf(oraConstraintSQL, table, datastore))
+}
+
+//DeferConstraintsSQL returns statement deferring constraints declared as DEFERRABLE
+func (d oraDialect) DeferConstraintsSQL() string {
+	return "SET CONSTRAINTS ALL DEFERRED"
+}
+
 func newOraDialect() *oraDialect {
 	result := &oraDialect{}
 	sqlDialect := NewSQLDatastoreDialect(oraTableSQL, "", oraSchemaSQL, oraSchemaListSQL, oraPrimaryKeySQL, "", "", "", ansiTableInfo, 0, result)
@@ -1047,6 +1573,31 @@ func (d *verticaDialect) BulkInsertType() string {
 	return CopyLocalInsert
 }
 
+//CreateTableSQL returns CREATE TABLE statement with Vertica column types and AUTO_INCREMENT
+func (d verticaDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return verticaDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with Vertica syntax
+func (d verticaDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return verticaDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//GetIndexes returns empty indexes, Vertica uses projections instead of indexes
+func (d verticaDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return []*Index{}, nil
+}
+
+//GetForeignKeys returns foreign keys from v_catalog.constraint_columns
+func (d verticaDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(verticaForeignKeySQL, table, datastore))
+}
+
+//GetConstraints returns constraints from v_catalog.constraint_columns
+func (d verticaDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return readConstraints(manager, table, fmt.Sprintf(verticaConstraintSQL, table, datastore))
+}
+
 func newVerticaDialect() *verticaDialect {
 	result := &verticaDialect{}
 	sqlDialect := NewSQLDatastoreDialect(verticaTableListSQL, "", verticaCurrentSchema, verticaSchemaSQL, "", "", "", "", verticaTableInfo, 0, result)
@@ -1101,6 +1652,31 @@ func (d *odbcDialect) BulkInsertType() string {
 	return CopyLocalInsert
 }
 
+//CreateTableSQL returns CREATE TABLE statement with Vertica column types and AUTO_INCREMENT
+func (d *odbcDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return verticaDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with Vertica syntax
+func (d *odbcDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return verticaDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//GetIndexes returns empty indexes, Vertica uses projections instead of indexes
+func (d *odbcDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return []*Index{}, nil
+}
+
+//GetForeignKeys returns foreign keys from v_catalog.constraint_columns
+func (d *odbcDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(verticaForeignKeySQL, table, datastore))
+}
+
+//GetConstraints returns constraints from v_catalog.constraint_columns
+func (d *odbcDialect) GetConstraints(manager Manager, datastore, table string) ([]*Constraint, error) {
+	return readConstraints(manager, table, fmt.Sprintf(verticaConstraintSQL, table, datastore))
+}
+
 func newOdbcDialect() *odbcDialect {
 	result := &odbcDialect{}
 	sqlDialect := NewSQLDatastoreDialect(verticaTableListSQL, "", verticaCurrentSchema, verticaSchemaSQL, "", "", "", "", verticaTableInfo, 0, result)
@@ -1125,6 +1701,53 @@ type msSQLDialect struct {
 	DatastoreDialect
 }
 
+//CanUseRowValueIn returns false, sql server does not support row value IN, composite keys use OR-ed key predicates
+func (d msSQLDialect) CanUseRowValueIn() bool {
+	return false
+}
+
+//SavepointSQL returns savepoint statement, sql server uses SAVE TRANSACTION and does not release savepoints
+func (d msSQLDialect) SavepointSQL(action int, name string) string {
+	switch action {
+	case SavepointCreate:
+		return "SAVE TRANSACTION " + name
+	case SavepointRollback:
+		return "ROLLBACK TRANSACTION " + name
+	}
+	return ""
+}
+
+//UpsertSQL returns MERGE statement
+func (d msSQLDialect) UpsertSQL(table string, columns, pkColumns []string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	return mergeUpsertSQL(table, "(VALUES("+placeholders+")) AS s("+strings.Join(columns, ", ")+")", columns, pkColumns) + ";"
+}
+
+//TranslateError returns error kind for SQL Server error messages
+func (d msSQLDialect) TranslateError(err error) error {
+	return translateErrorWithPatterns(err, msSQLErrorPatterns)
+}
+
+//CreateTableSQL returns CREATE TABLE statement with SQL Server column types and IDENTITY
+func (d msSQLDialect) CreateTableSQL(descriptor *TableDescriptor) (string, error) {
+	return msSQLDDLSpec.createTableSQL(descriptor)
+}
+
+//AlterTableSQL returns ALTER TABLE statements with SQL Server syntax
+func (d msSQLDialect) AlterTableSQL(descriptor *TableDescriptor, diff *SchemaDiff) ([]string, error) {
+	return msSQLDDLSpec.alterTableSQL(descriptor, diff)
+}
+
+//GetIndexes returns indexes from sys.indexes, included columns are skipped
+func (d msSQLDialect) GetIndexes(manager Manager, datastore, table string) ([]*Index, error) {
+	return readIndexes(manager, table, fmt.Sprintf(msSQLIndexSQL, table, datastore))
+}
+
+//GetForeignKeys returns foreign keys from sys.foreign_keys, SQL Server key_column_usage has no position_in_unique_constraint
+func (d msSQLDialect) GetForeignKeys(manager Manager, datastore, table string) ([]*ForeignKey, error) {
+	return readForeignKeys(manager, table, fmt.Sprintf(msSQLForeignKeySQL, table, datastore))
+}
+
 func newMsSQLDialect() *msSQLDialect {
 	result := &msSQLDialect{}
 	sqlDialect := NewSQLDatastoreDialect(ansiTableListSQL, msSequenceSQL, msSchemaSQL, ansiSchemaListSQL, msSqlPrimaryKeySQL, "", "", "", ansiTableInfo, 0, result)
@@ -1132,3 +1755,53 @@ func newMsSQLDialect() *msSQLDialect {
 	sqlDialect.DatastoreDialect = result
 	return result
 }
+
+//nonKeyColumns returns columns that are not pk columns
+func nonKeyColumns(columns, pkColumns []string) []string {
+	var result = make([]string, 0)
+	for _, column := range columns {
+		if !toolbox.HasSliceAnyElements(pkColumns, column) {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+func insertValuesSQL(table string, columns []string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",")
+	return fmt.Sprintf(insertSQLTemplate, table, strings.Join(columns, ","), placeholders)
+}
+
+//onConflictUpsertSQL returns INSERT ... ON CONFLICT DO UPDATE statement
+func onConflictUpsertSQL(table string, columns, pkColumns []string) string {
+	var assignments = make([]string, 0)
+	for _, column := range nonKeyColumns(columns, pkColumns) {
+		assignments = append(assignments, column+" = excluded."+column)
+	}
+	SQL := insertValuesSQL(table, columns) + " ON CONFLICT(" + strings.Join(pkColumns, ", ") + ")"
+	if len(assignments) == 0 {
+		return SQL + " DO NOTHING"
+	}
+	return SQL + " DO UPDATE SET " + strings.Join(assignments, ", ")
+}
+
+//mergeUpsertSQL returns MERGE statement for passed in source
+func mergeUpsertSQL(table, source string, columns, pkColumns []string) string {
+	var criteria = make([]string, len(pkColumns))
+	for i, column := range pkColumns {
+		criteria[i] = "t." + column + " = s." + column
+	}
+	var assignments = make([]string, 0)
+	for _, column := range nonKeyColumns(columns, pkColumns) {
+		assignments = append(assignments, "t."+column+" = s."+column)
+	}
+	var values = make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = "s." + column
+	}
+	SQL := fmt.Sprintf("MERGE INTO %v t USING %v ON (%v)", table, source, strings.Join(criteria, " AND "))
+	if len(assignments) > 0 {
+		SQL += " WHEN MATCHED THEN UPDATE SET " + strings.Join(assignments, ", ")
+	}
+	return SQL + fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%v) VALUES (%v)", strings.Join(columns, ", "), strings.Join(values, ", "))
+}
@@ -1,11 +1,16 @@
 package dsc_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	_ "github.com/denisenkom/go-mssqldb"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/dsc"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSqlAllSqlDialect(t *testing.T) {
@@ -69,3 +74,107 @@ func TestSqlDialect(t *testing.T) {
 		assert.True(t, mySQLDialect.CanPersistBatch())
 	}
 }
+
+func TestSqlDialect_UpsertSQL(t *testing.T) {
+	var useCases = []struct {
+		driver string
+		expect string
+	}{
+		{"mysql", "INSERT INTO users(name,id) VALUES(?,?) ON DUPLICATE KEY UPDATE name = VALUES(name)"},
+		{"pg", "INSERT INTO users(name,id) VALUES(?,?) ON CONFLICT(id) DO UPDATE SET name = excluded.name"},
+		{"sqlite3", "INSERT INTO users(name,id) VALUES(?,?) ON CONFLICT(id) DO UPDATE SET name = excluded.name"},
+		{"ora", "MERGE INTO users t USING (SELECT ? AS name, ? AS id FROM DUAL) s ON (t.id = s.id) WHEN MATCHED THEN UPDATE SET t.name = s.name WHEN NOT MATCHED THEN INSERT (name, id) VALUES (s.name, s.id)"},
+		{"sqlserver", "MERGE INTO users t USING (VALUES(?, ?)) AS s(name, id) ON (t.id = s.id) WHEN MATCHED THEN UPDATE SET t.name = s.name WHEN NOT MATCHED THEN INSERT (name, id) VALUES (s.name, s.id);"},
+		{"ndjson", ""},
+	}
+	for _, useCase := range useCases {
+		dialect := dsc.GetDatastoreDialect(useCase.driver)
+		assert.Equal(t, useCase.expect, dialect.UpsertSQL("users", []string{"name", "id"}, []string{"id"}), useCase.driver)
+	}
+	assert.Equal(t, "INSERT INTO users(id) VALUES(?) ON CONFLICT(id) DO NOTHING", dsc.GetDatastoreDialect("pg").UpsertSQL("users", []string{"id"}, []string{"id"}))
+}
+
+func TestPgDialect_NormalizeSQL(t *testing.T) {
+	dialect := dsc.GetDatastoreDialect("pg")
+	assert.Equal(t, "SELECT name FROM users WHERE id = $1 AND name IN($2, $3) AND note = 'żółw'", dialect.NormalizeSQL("SELECT name FROM users WHERE id = ? AND name IN(?, ?) AND note = 'żółw'"))
+	assert.Equal(t, "SELECT 1", dialect.NormalizeSQL("SELECT 1"))
+	SQL := "INSERT INTO users(id) VALUES" + strings.TrimSuffix(strings.Repeat("(?),", 32767), ",")
+	normalized := dialect.NormalizeSQL(SQL)
+	assert.True(t, strings.HasPrefix(normalized, "INSERT INTO users(id) VALUES($1),($2),"))
+	assert.True(t, strings.HasSuffix(normalized, ",($32766),($32767)"))
+}
+
+func TestSqlDialect_TranslateError(t *testing.T) {
+	var useCases = []struct {
+		description string
+		driver      string
+		err         error
+		expect      error
+	}{
+		{"mysql duplicate", "mysql", errors.New("Error 1062 (23000): Duplicate entry '1' for key 'PRIMARY'"), dsc.ErrDuplicateKey},
+		{"mysql deadlock", "mysql", errors.New("Error 1213: Deadlock found when trying to get lock"), dsc.ErrDeadlock},
+		{"pg foreign key", "pg", errors.New(`pq: insert or update on table "orders" violates foreign key constraint "fk_user"`), dsc.ErrForeignKeyViolation},
+		{"pg syntax", "pg", errors.New(`pq: syntax error at or near "SELEC"`), dsc.ErrSyntax},
+		{"ora duplicate", "ora", errors.New("ORA-00001: unique constraint (APP.PK_USERS) violated"), dsc.ErrDuplicateKey},
+		{"mssql deadlock", "mssql", errors.New("mssql: Transaction (Process ID 52) was deadlocked on lock resources"), dsc.ErrDeadlock},
+		{"sqlite duplicate", "sqlite3", errors.New("UNIQUE constraint failed: users.id"), dsc.ErrDuplicateKey},
+		{"context deadline", "mysql", fmt.Errorf("query failed: %w", context.DeadlineExceeded), dsc.ErrTimeout},
+		{"unknown", "mysql", errors.New("Error 1146: Table 'db.x' doesn't exist"), nil},
+	}
+	for _, useCase := range useCases {
+		actual := dsc.GetDatastoreDialect(useCase.driver).TranslateError(useCase.err)
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+type ddlAccount struct {
+	Id      int       `autoincrement:"true"`
+	Email   string    `size:"128" unique:"true"`
+	Balance float64   `default:"0"`
+	Note    *string   `sqlType:"TEXT"`
+	Bio     *string   `size:"100000"`
+	Created time.Time `nullable:"true"`
+}
+
+func TestSqlDialect_CreateTableSQL(t *testing.T) {
+	var useCases = []struct {
+		driver string
+		expect string
+	}{
+		{"mysql", "CREATE TABLE accounts(Id BIGINT AUTO_INCREMENT NOT NULL, Email VARCHAR(128) NOT NULL UNIQUE, Balance DOUBLE DEFAULT 0 NOT NULL, Note TEXT, Bio LONGTEXT, Created DATETIME, PRIMARY KEY(Id))"},
+		{"pg", "CREATE TABLE accounts(Id BIGSERIAL NOT NULL, Email VARCHAR(128) NOT NULL UNIQUE, Balance DOUBLE PRECISION DEFAULT 0 NOT NULL, Note TEXT, Bio TEXT, Created TIMESTAMP, PRIMARY KEY(Id))"},
+		{"sqlite3", "CREATE TABLE accounts(Id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL, Email VARCHAR(128) NOT NULL UNIQUE, Balance REAL DEFAULT 0 NOT NULL, Note TEXT, Bio TEXT, Created TIMESTAMP)"},
+		{"sqlserver", "CREATE TABLE accounts(Id BIGINT IDENTITY(1,1) NOT NULL, Email NVARCHAR(128) NOT NULL UNIQUE, Balance FLOAT DEFAULT 0 NOT NULL, Note TEXT, Bio NVARCHAR(MAX), Created DATETIME2, PRIMARY KEY(Id))"},
+		{"ora", "CREATE TABLE accounts(Id NUMBER(19) GENERATED BY DEFAULT AS IDENTITY NOT NULL, Email VARCHAR2(128) NOT NULL UNIQUE, Balance BINARY_DOUBLE DEFAULT 0 NOT NULL, Note TEXT, Bio CLOB, Created TIMESTAMP, PRIMARY KEY(Id))"},
+		{"vertica", "CREATE TABLE accounts(Id AUTO_INCREMENT NOT NULL, Email VARCHAR(128) NOT NULL UNIQUE, Balance FLOAT DEFAULT 0 NOT NULL, Note TEXT, Bio LONG VARCHAR, Created TIMESTAMP, PRIMARY KEY(Id))"},
+	}
+	descriptor, err := dsc.NewTableDescriptor("accounts", ddlAccount{})
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, useCase := range useCases {
+		actual, err := dsc.GetDatastoreDialect(useCase.driver).CreateTableSQL(descriptor)
+		assert.Nil(t, err, useCase.driver)
+		assert.Equal(t, useCase.expect, actual, useCase.driver)
+	}
+}
+
+func TestSqlDialect_CreateTable(t *testing.T) {
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/ddl.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	dialect := dsc.GetDatastoreDialect("sqlite3")
+	_ = dialect.DropTable(manager, "", "accounts")
+	if !assert.Nil(t, dialect.CreateTable(manager, "", "accounts", &ddlAccount{})) {
+		return
+	}
+	var accounts = []*ddlAccount{{Email: "a@x.com"}, {Email: "b@x.com"}}
+	inserted, _, err := manager.PersistAll(&accounts, "accounts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, inserted)
+	assert.EqualValues(t, 2, accounts[1].Id)
+	_, err = manager.Execute("INSERT INTO accounts(Email) VALUES(?)", "a@x.com")
+	assert.True(t, errors.Is(err, dsc.ErrDuplicateKey))
+}
@@ -1,11 +1,11 @@
 package dsc
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/pkg/errors"
 	"reflect"
-	"time"
 )
 
 func asSQLDb(wrapped interface{}) (*sql.DB, error) {
@@ -52,7 +52,11 @@ func (s *sqlScanner) ColumnTypes() ([]ColumnType, error) {
 }
 
 type sqlExecutor interface {
-	Exec(sql string, parameters ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, sql string, parameters ...interface{}) (sql.Result, error)
+}
+
+type sqlPreparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
 }
 
 type sqlManager struct {
@@ -72,7 +76,17 @@ func (m *sqlManager) initConnectionIfNeeded(connection Connection) error {
 }
 
 func (m *sqlManager) ExecuteOnConnection(connection Connection, sql string, args []interface{}) (sql.Result, error) {
-	m.Acquire()
+	return m.ExecuteOnConnectionContext(context.Background(), connection, sql, args)
+}
+
+func (m *sqlManager) ExecuteOnConnectionContext(ctx context.Context, connection Connection, sql string, args []interface{}) (sql.Result, error) {
+	return m.interceptExecute(ctx, connection, sql, args, m.executeOnConnection)
+}
+
+func (m *sqlManager) executeOnConnection(ctx context.Context, connection Connection, sql string, args []interface{}) (sql.Result, error) {
+	if err := m.AcquireContext(ctx); err != nil {
+		return nil, err
+	}
 	db, err := asSQLDb(connection.Unwrap(sqlDbPointer))
 	if err == nil {
 		err = m.initConnectionIfNeeded(connection)
@@ -95,20 +109,30 @@ func (m *sqlManager) ExecuteOnConnection(connection Connection, sql string, args
 
 	dialect := GetDatastoreDialect(m.config.DriverName)
 	sql = dialect.NormalizeSQL(sql)
-	result, err := executable.Exec(sql, args...)
+	result, err := executable.ExecContext(ctx, sql, args...)
 	if !dialect.CanHandleTransaction() {
 		result = NewSQLResult(1, 0)
 	}
-	Logf("[%v]:%v %v", m.config.username, sql, args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute %w: %v %v on %v", err.Error(), sql, args, m.Manager.Config().Parameters)
+		return nil, fmt.Errorf("failed to execute %v due to %w", sql, m.translateError(err))
 	}
 	return result, err
 }
 
 func (m *sqlManager) ReadAllOnWithHandlerOnConnection(connection Connection, query string, args []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
-	m.Acquire()
-	startTime := time.Now()
+	return m.ReadAllOnWithHandlerOnConnectionContext(context.Background(), connection, query, args, readingHandler)
+}
+
+func (m *sqlManager) ReadAllOnWithHandlerOnConnectionContext(ctx context.Context, connection Connection, query string, args []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	return m.readWithRetry(ctx, connection, readingHandler, func(readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+		return m.interceptRead(ctx, connection, query, args, readingHandler, m.readAllOnWithHandlerOnConnection)
+	})
+}
+
+func (m *sqlManager) readAllOnWithHandlerOnConnection(ctx context.Context, connection Connection, query string, args []interface{}, readingHandler func(scanner Scanner) (toContinue bool, err error)) error {
+	if err := m.AcquireContext(ctx); err != nil {
+		return err
+	}
 	db, err := asSQLDb(connection.Unwrap((*sql.DB)(nil)))
 	if err == nil {
 		err = m.initConnectionIfNeeded(connection)
@@ -116,25 +140,27 @@ func (m *sqlManager) ReadAllOnWithHandlerOnConnection(connection Connection, que
 	if err != nil {
 		return err
 	}
+	var preparer sqlPreparer = db
+	tx, err := asSQLTx(connection.Unwrap(sqlTxtPointer))
+	if err != nil {
+		return err
+	}
+	if tx != nil { //read within transaction to see its changes
+		preparer = tx
+	}
 
 	dialect := GetDatastoreDialect(m.config.DriverName)
 	query = dialect.NormalizeSQL(query)
-	Logf("[%v]:%v", m.config.username, query)
-
-	sqlStatement, sqlError := db.Prepare(query)
+	sqlStatement, sqlError := preparer.PrepareContext(ctx, query)
 	if sqlError != nil {
-		return fmt.Errorf("failed to prepare sql: %v with %v due to:%v\n\t", query, args, sqlError.Error())
+		return fmt.Errorf("failed to prepare sql: %v due to %w", query, m.translateError(sqlError))
 	}
 
-	Logf("[%v]:prepare time: %v\n", m.config.username, time.Now().Sub(startTime))
-
 	defer sqlStatement.Close()
-	rows, queryError := m.executeQuery(sqlStatement, query, args)
+	rows, queryError := m.executeQuery(ctx, sqlStatement, query, args)
 	if queryError != nil {
-		return fmt.Errorf(fmt.Sprintf("failed to execute sql: %v with %v due to:%v\n\t", query, args, queryError.Error()))
+		return fmt.Errorf("failed to execute sql: %v due to %w", query, m.translateError(queryError))
 	}
-	Logf("[%v]:execute time: %v\n", m.config.username, time.Now().Sub(startTime))
-
 	defer rows.Close()
 
 	for rows.Next() {
@@ -148,15 +174,17 @@ func (m *sqlManager) ReadAllOnWithHandlerOnConnection(connection Connection, que
 			break
 		}
 	}
-	Logf("[%v]:fetched time: %v\n", m.config.username, time.Now().Sub(startTime))
-	return rows.Err()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read rows: %v due to %w", query, m.translateError(err))
+	}
+	return nil
 }
 
-func (m *sqlManager) executeQuery(sqlStatement *sql.Stmt, query string, args []interface{}) (rows *sql.Rows, err error) {
+func (m *sqlManager) executeQuery(ctx context.Context, sqlStatement *sql.Stmt, query string, args []interface{}) (rows *sql.Rows, err error) {
 	if args == nil {
 		args = make([]interface{}, 0)
 	}
-	rows, err = sqlStatement.Query(args...)
+	rows, err = sqlStatement.QueryContext(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
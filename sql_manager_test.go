@@ -1,11 +1,15 @@
 package dsc_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/dsc"
+	"strings"
 	"testing"
 	"time"
 )
@@ -438,3 +442,458 @@ func TestDeleteSingle(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, deleted)
 }
+
+func TestContextManager(t *testing.T) {
+	manager, ok := GetManager(t).(dsc.ContextManager)
+	if !assert.True(t, ok) {
+		return
+	}
+	ctx := context.Background()
+	{
+		var users = make([]User, 0)
+		err := manager.ReadAllContext(ctx, &users, "SELECT id, username, active, salary, comments, last_access_time FROM users", nil, &UserRecordMapper{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(users))
+	}
+	{
+		user := &User{Username: "Sam", Active: true}
+		inserted, updated, err := manager.PersistSingleContext(ctx, user, "users", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, inserted)
+		assert.Equal(t, 0, updated)
+		assert.Equal(t, 2, user.Id)
+
+		deleted, err := manager.DeleteSingleContext(ctx, user, "users", nil)
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+	}
+	{
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := manager.ExecuteContext(cancelledCtx, "DELETE FROM users")
+		assert.Equal(t, context.Canceled, err)
+
+		var users = make([]User, 0)
+		err = manager.ReadAllContext(cancelledCtx, &users, "SELECT id, username, active, salary, comments, last_access_time FROM users", nil, &UserRecordMapper{})
+		assert.NotNil(t, err)
+
+		_, _, err = manager.PersistAllContext(cancelledCtx, &[]User{{Username: "Bob"}}, "users", nil)
+		assert.NotNil(t, err)
+
+		users = make([]User, 0)
+		err = manager.ReadAll(&users, "SELECT id, username, active, salary, comments, last_access_time FROM users", nil, &UserRecordMapper{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(users))
+	}
+}
+
+func TestWithTransaction(t *testing.T) {
+	manager := GetManager(t)
+	var count = func() int {
+		var users = make([][]interface{}, 0)
+		err := manager.ReadAll(&users, "SELECT id, username FROM users", nil, nil)
+		assert.Nil(t, err)
+		return len(users)
+	}
+
+	err := manager.WithTransaction(func(tx dsc.Connection) error {
+		_, err := manager.ExecuteOnConnection(tx, "INSERT INTO users(username) VALUES(?)", []interface{}{"Bob"})
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count())
+
+	err = manager.WithTransaction(func(tx dsc.Connection) error {
+		if _, err := manager.ExecuteOnConnection(tx, "INSERT INTO users(username) VALUES(?)", []interface{}{"Sam"}); err != nil {
+			return err
+		}
+		return fmt.Errorf("test error")
+	})
+	assert.EqualError(t, err, "test error")
+	assert.Equal(t, 2, count())
+
+	assert.Panics(t, func() {
+		_ = manager.WithTransaction(func(tx dsc.Connection) error {
+			_, _ = manager.ExecuteOnConnection(tx, "INSERT INTO users(username) VALUES(?)", []interface{}{"Sam"})
+			panic("test panic")
+		})
+	})
+	assert.Equal(t, 2, count())
+
+	err = manager.WithTransaction(func(tx dsc.Connection) error {
+		if _, err := manager.ExecuteOnConnection(tx, "INSERT INTO users(username) VALUES(?)", []interface{}{"Dan"}); err != nil {
+			return err
+		}
+		nestedErr := manager.WithTransactionOnConnection(tx, func(tx dsc.Connection) error {
+			if _, err := manager.ExecuteOnConnection(tx, "INSERT INTO users(username) VALUES(?)", []interface{}{"Sam"}); err != nil {
+				return err
+			}
+			return fmt.Errorf("nested error")
+		})
+		assert.EqualError(t, nestedErr, "nested error")
+		return manager.WithTransactionOnConnection(tx, func(tx dsc.Connection) error {
+			_, err := manager.ExecuteOnConnection(tx, "INSERT INTO users(username) VALUES(?)", []interface{}{"Ela"})
+			return err
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count())
+}
+
+func TestPersistAllUpsert(t *testing.T) {
+	type Account struct {
+		Id   int `primaryKey:"true"`
+		Name string
+	}
+	var operations = make([]string, 0)
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db,upsert:true")
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			operations = append(operations, operation.SQL)
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS accounts",
+		"CREATE TABLE accounts (id INTEGER NOT NULL PRIMARY KEY, name varchar(255))",
+		"INSERT INTO accounts(id, name) VALUES(1, 'Bob')",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	operations = operations[:0]
+	accounts := []Account{{Id: 1, Name: "Robert"}, {Id: 2, Name: "Sam"}}
+	inserted, updated, err := manager.PersistAll(&accounts, "accounts", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 2, inserted) //upsert reports all rows as inserted
+	assert.Equal(t, 0, updated)
+	if assert.Equal(t, 2, len(operations)) {
+		assert.Equal(t, "INSERT INTO accounts(Name,Id) VALUES(?,?) ON CONFLICT(Id) DO UPDATE SET Name = excluded.Name", operations[0])
+	}
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id, name FROM accounts ORDER BY id", nil, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][]interface{}{{int64(1), "Robert"}, {int64(2), "Sam"}}, records)
+
+	config.Parameters[dsc.UpsertKey] = false
+	operations = operations[:0]
+	inserted, updated, err = manager.PersistAll(&accounts, "accounts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, inserted)
+	assert.Equal(t, 2, updated)
+	assert.Equal(t, 3, len(operations))
+}
+
+//batchDialect represents sqlite dialect with batch and RETURNING support
+type batchDialect struct {
+	dsc.DatastoreDialect
+}
+
+func init() {
+	sql.Register("sqlite3_batch", &sqlite3.SQLiteDriver{})
+	dsc.RegisterDatastoreDialect("sqlite3_batch", batchDialect{dsc.GetDatastoreDialect("sqlite3")})
+}
+
+func (d batchDialect) CanPersistBatch() bool {
+	return true
+}
+
+func (d batchDialect) ReturningSQL(columns []string) string {
+	return " RETURNING " + strings.Join(columns, ", ")
+}
+
+func TestPersistAllReturning(t *testing.T) {
+	var operations = make([]*dsc.Operation, 0)
+	config := dsc.NewConfig("sqlite3_batch", "[url]", "url:./test/foo.db")
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			operations = append(operations, operation)
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS users",
+		"CREATE TABLE users (id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, username varchar(255), active tinyint(1), salary decimal(7,2), comments text, last_access_time timestamp)",
+		"INSERT INTO users(id, username) VALUES(10, 'Edi')",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	operations = operations[:0]
+	users := []User{{Username: "Bob"}, {Username: "Sam"}, {Username: "Ted"}}
+	inserted, _, err := manager.PersistAll(&users, "users", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 3, inserted)
+	assert.Equal(t, []int{11, 12, 13}, []int{users[0].Id, users[1].Id, users[2].Id})
+	if assert.Equal(t, 2, len(operations)) {
+		assert.Equal(t, dsc.OperationBatch, operations[1].Kind)
+		assert.True(t, strings.HasSuffix(operations[1].SQL, ",(?,?,?,?,?) RETURNING Id"), operations[1].SQL)
+		assert.EqualValues(t, 3, operations[1].Rows)
+	}
+}
+
+func TestPersistAllBatchUpdateAndDelete(t *testing.T) {
+	type Account struct {
+		Id      int `primaryKey:"true"`
+		Name    string
+		Balance float64
+	}
+	var operations = make([]*dsc.Operation, 0)
+	config := dsc.NewConfig("sqlite3_batch", "[url]", "url:./test/foo.db")
+	config.Parameters[dsc.UpsertKey] = false
+	config.Parameters[dsc.BatchSizeKey] = 2
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			operations = append(operations, operation)
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS accounts",
+		"CREATE TABLE accounts (id INTEGER NOT NULL PRIMARY KEY, name varchar(255), balance decimal(7,2))",
+		"INSERT INTO accounts(id, name, balance) VALUES(1, 'Bob', 1), (2, 'Sam', 2), (3, 'Ted', 3), (4, 'Ann', 4)",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	operations = operations[:0]
+	accounts := []Account{{1, "Robert", 10}, {2, "Samuel", 20}, {3, "Theodore", 30}}
+	inserted, updated, err := manager.PersistAll(&accounts, "accounts", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 0, inserted)
+	assert.Equal(t, 3, updated)
+	if assert.Equal(t, 3, len(operations)) {
+		assert.Equal(t, dsc.OperationBatch, operations[1].Kind)
+		assert.True(t, strings.HasPrefix(operations[1].SQL, "UPDATE accounts SET "), operations[1].SQL)
+		assert.True(t, strings.Contains(operations[1].SQL, "Name = CASE WHEN Id = ? THEN ? WHEN Id = ? THEN ? ELSE Name END"), operations[1].SQL)
+		assert.True(t, strings.HasSuffix(operations[1].SQL, " END WHERE Id IN (?,?)"), operations[1].SQL)
+		assert.EqualValues(t, 2, operations[1].Rows)
+		assert.EqualValues(t, 1, operations[2].Rows)
+	}
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT name, balance FROM accounts ORDER BY id", nil, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][]interface{}{{"Robert", int64(10)}, {"Samuel", int64(20)}, {"Theodore", int64(30)}, {"Ann", int64(4)}}, records)
+
+	operations = operations[:0]
+	accounts = append(accounts, Account{Id: 4})
+	deleted, err := manager.DeleteAll(&accounts, "accounts", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, deleted)
+	if assert.Equal(t, 2, len(operations)) {
+		assert.Equal(t, "DELETE FROM accounts WHERE Id IN (?,?)", operations[0].SQL)
+		assert.Equal(t, dsc.OperationDelete, operations[0].Kind)
+	}
+}
+
+//keyPredicateDialect represents batch dialect without row value IN support
+type keyPredicateDialect struct {
+	batchDialect
+}
+
+func init() {
+	sql.Register("sqlite3_key_predicate", &sqlite3.SQLiteDriver{})
+	dsc.RegisterDatastoreDialect("sqlite3_key_predicate", keyPredicateDialect{batchDialect{dsc.GetDatastoreDialect("sqlite3")}})
+}
+
+func (d keyPredicateDialect) CanUseRowValueIn() bool {
+	return false
+}
+
+func TestDeleteAllCompositeKey(t *testing.T) {
+	type Line struct {
+		OrderId int    `primaryKey:"true" column:"order_id"`
+		LineId  int    `primaryKey:"true" column:"line_id"`
+		Product string `column:"product"`
+	}
+	var operations = make([]*dsc.Operation, 0)
+	config := dsc.NewConfig("sqlite3_key_predicate", "[url]", "url:./test/foo.db")
+	config.Interceptors = []dsc.Interceptor{
+		dsc.InterceptorFunc(func(operation *dsc.Operation, next dsc.OperationHandler) error {
+			operations = append(operations, operation)
+			return next(operation)
+		}),
+	}
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS lines",
+		"CREATE TABLE lines (order_id INTEGER NOT NULL, line_id INTEGER NOT NULL, product varchar(255), PRIMARY KEY(order_id, line_id))",
+		"INSERT INTO lines(order_id, line_id, product) VALUES(1, 1, 'a'), (1, 2, 'b'), (2, 1, 'c')",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	operations = operations[:0]
+	lines := []Line{{1, 1, ""}, {2, 1, ""}}
+	deleted, err := manager.DeleteAll(&lines, "lines", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	if assert.Equal(t, 1, len(operations)) {
+		assert.Equal(t, "DELETE FROM lines WHERE ((order_id = ? AND line_id = ?) OR (order_id = ? AND line_id = ?))", operations[0].SQL)
+	}
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT product FROM lines", nil, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][]interface{}{{"b"}}, records)
+}
+
+func TestPersistAllOptimisticLock(t *testing.T) {
+	type Document struct {
+		Id      int `primaryKey:"true"`
+		Title   string
+		Version int `version:"true"`
+	}
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS documents",
+		"CREATE TABLE documents (id INTEGER NOT NULL PRIMARY KEY, title varchar(255), version INTEGER)",
+		"INSERT INTO documents(id, title, version) VALUES(1, 'Draft', 1), (2, 'Notes', 1)",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	documents := []Document{{1, "Final", 1}, {2, "Memo", 1}}
+	_, updated, err := manager.PersistAll(&documents, "documents", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 2, updated)
+	assert.Equal(t, 2, documents[0].Version)
+	assert.Equal(t, 2, documents[1].Version)
+
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT title, version FROM documents ORDER BY id", nil, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][]interface{}{{"Final", int64(2)}, {"Memo", int64(2)}}, records)
+
+	rolledBack := []*Document{{Id: 1, Title: "Discarded", Version: 2}}
+	err = manager.WithTransaction(func(connection dsc.Connection) error {
+		if _, _, err := manager.PersistAllOnConnection(connection, &rolledBack, "documents", nil); err != nil {
+			return err
+		}
+		assert.Equal(t, 2, rolledBack[0].Version, "version is incremented after commit")
+		return errors.New("abort")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, rolledBack[0].Version)
+
+	stale := []*Document{{Id: 1, Title: "Outdated", Version: 1}}
+	_, _, err = manager.PersistAll(&stale, "documents", nil)
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, dsc.ErrStaleObject))
+		var staleErr *dsc.StaleObjectError
+		if assert.True(t, errors.As(err, &staleErr)) {
+			assert.EqualValues(t, [][]interface{}{{1}}, staleErr.Keys)
+		}
+	}
+	assert.Equal(t, 1, stale[0].Version)
+
+	_, err = manager.DeleteAll(&stale, "documents", nil)
+	assert.True(t, errors.Is(err, dsc.ErrStaleObject))
+	deleted, err := manager.DeleteAll(&documents, "documents", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+}
+
+func TestDeleteAllSoftDelete(t *testing.T) {
+	type Note struct {
+		Id        int `primaryKey:"true"`
+		Title     string
+		DeletedAt *time.Time `column:"deleted_at" softDelete:"true"`
+	}
+	config := dsc.NewConfig("sqlite3", "[url]", "url:./test/foo.db")
+	config.Parameters[dsc.UpsertKey] = false
+	manager, err := dsc.NewManagerFactory().Create(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS notes",
+		"CREATE TABLE notes (id INTEGER NOT NULL PRIMARY KEY, title varchar(255), deleted_at timestamp)",
+		"INSERT INTO notes(id, title) VALUES(1, 'Draft'), (2, 'Memo'), (3, 'Todo')",
+	} {
+		_, err = manager.Execute(SQL)
+		assert.Nil(t, err)
+	}
+	notes := []Note{{Id: 1}, {Id: 2}}
+	deleted, err := manager.DeleteAll(&notes, "notes", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	deleted, err = manager.DeleteAll(&notes, "notes", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, deleted)
+
+	descriptor := manager.TableDescriptorRegistry().Get("notes")
+	assert.Equal(t, "deleted_at", descriptor.SoftDeleteColumn)
+	builder := dsc.NewQueryBuilder(descriptor, "")
+	query := builder.BuildQueryAll([]string{"id", "title"})
+	assert.Equal(t, "SELECT  id,title FROM notes WHERE deleted_at IS NULL", query.SQL)
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, query.SQL, query.Values, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][]interface{}{{int64(3), "Todo"}}, records)
+	builder = builder.WithDeleted()
+	query = builder.BuildQueryOnPk([]string{"id"}, [][]interface{}{{1}})
+	assert.Equal(t, "SELECT  id FROM notes WHERE Id IN (?)", query.SQL)
+
+	contextManager, ok := manager.(dsc.ContextManager)
+	if !assert.True(t, ok) {
+		return
+	}
+	restored := []Note{{Id: 1, Title: "Restored"}}
+	inserted, updated, err := contextManager.PersistAllContext(dsc.WithDeleted(context.Background()), &restored, "notes", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, inserted)
+	assert.Equal(t, 1, updated)
+
+	deleted, err = contextManager.DeleteAllContext(dsc.WithHardDelete(context.Background()), &notes, "notes", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, deleted)
+	records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELECT id FROM notes", nil, nil)
+	assert.Nil(t, err)
+	assert.EqualValues(t, [][]interface{}{{int64(3)}}, records)
+}
+
+func TestExecuteTranslatedError(t *testing.T) {
+	manager := GetManager(t)
+	_, err := manager.Execute("INSERT INTO users(id, username) VALUES(1, 'Dup')")
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, dsc.ErrDuplicateKey), err.Error())
+		var dscErr *dsc.Error
+		assert.True(t, errors.As(err, &dscErr))
+		var driverErr sqlite3.Error
+		if assert.True(t, errors.As(err, &driverErr)) {
+			assert.Equal(t, sqlite3.ErrConstraint, driverErr.Code)
+		}
+	}
+	var records = make([][]interface{}, 0)
+	err = manager.ReadAll(&records, "SELEC id FROM users", nil, nil)
+	if assert.NotNil(t, err) {
+		assert.True(t, errors.Is(err, dsc.ErrSyntax), err.Error())
+	}
+}
@@ -151,12 +151,28 @@ func bindValueIfNeeded(source interface{}, parameters toolbox.Iterator) (interfa
 	return source, nil
 }
 
+// SQLOrderColumn represents ORDER BY column
+type SQLOrderColumn struct {
+	*SQLColumn
+	Direction string // ASC or DESC
+}
+
+// IsDescending returns true if column is sorted in descending order
+func (c *SQLOrderColumn) IsDescending() bool {
+	return strings.ToUpper(c.Direction) == "DESC"
+}
+
 // QueryStatement represents SQL query statement.
 type QueryStatement struct {
 	*BaseStatement
 	AllField    bool
 	UnionTables []string
 	GroupBy     []*SQLColumn
+	Having      *SQLCriteria
+	OrderBy     []*SQLOrderColumn
+	Limit       int  // max number of rows, used only if HasLimit
+	HasLimit    bool // true if query specifies LIMIT, LIMIT 0 returns no rows
+	Offset      int  // number of rows to skip
 }
 
 // DmlStatement represents dml statement.
@@ -230,6 +246,11 @@ const (
 	updateKeyword
 	deleteKeyword
 	setKeyword
+	orderKeyword
+	limitKeyword
+	offsetKeyword
+	directionKeyword
+	havingKeyword
 )
 
 var sqlMatchers = map[int]toolbox.Matcher{
@@ -298,6 +319,15 @@ var sqlMatchers = map[int]toolbox.Matcher{
 	setKeyword:    toolbox.KeywordMatcher{Keyword: "SET", CaseSensitive: false},
 
 	deleteKeyword: toolbox.KeywordMatcher{Keyword: "DELETE", CaseSensitive: false},
+
+	orderKeyword:  toolbox.KeywordMatcher{Keyword: "ORDER", CaseSensitive: false},
+	limitKeyword:  toolbox.KeywordMatcher{Keyword: "LIMIT", CaseSensitive: false},
+	offsetKeyword: toolbox.KeywordMatcher{Keyword: "OFFSET", CaseSensitive: false},
+	havingKeyword: toolbox.KeywordMatcher{Keyword: "HAVING", CaseSensitive: false},
+	directionKeyword: toolbox.KeywordsMatcher{
+		Keywords:      []string{"ASC", "DESC"},
+		CaseSensitive: false,
+	},
 }
 
 type baseParser struct{}
@@ -393,6 +423,15 @@ func (bp *baseParser) readCriteria(tokenizer *toolbox.Tokenizer, sqlCriteria *SQ
 		if token.Matched == "" {
 			return fmt.Errorf("expected criteria at %v", tokenizer.Index)
 		}
+		if index := strings.Index(token.Matched, "("); token.Token == sqlValue && index > 0 && !strings.HasPrefix(token.Matched, "'") {
+			//function call i.e. COUNT(*), value matcher terminates at first space or closing bracket
+			tokenizer.Index -= len(token.Matched) - index
+			arguments := tokenizer.Next(groupExpression)
+			if arguments.Token != groupExpression {
+				return newIllegalTokenParsingError(tokenizer.Index, "(arguments)")
+			}
+			token = &toolbox.Token{Token: sqlValue, Matched: token.Matched[:index] + arguments.Matched}
+		}
 
 		index := len(sqlCriteria.Criteria)
 		sqlCriteria.Criteria = append(sqlCriteria.Criteria, &SQLCriterion{LeftOperand: token.Matched})
@@ -455,14 +494,14 @@ func (bp *baseParser) readCriteria(tokenizer *toolbox.Tokenizer, sqlCriteria *SQ
 			sqlCriteria.Criteria[index].RightOperands = []interface{}{fromValue, toValue}
 		}
 
-		token, err = bp.expectOptionalWhitespaceFollowedBy(tokenizer, "or | and | eof", eof, logicalOperator, groupKeyword)
+		token, err = bp.expectOptionalWhitespaceFollowedBy(tokenizer, "or | and | eof", eof, orderKeyword, logicalOperator, groupKeyword, havingKeyword, limitKeyword, offsetKeyword)
 		if err != nil {
 			return err
 		}
 		if token.Token == eof {
 			break
 		}
-		if token.Token == groupKeyword {
+		if token.Token == groupKeyword || token.Token == havingKeyword || token.Token == orderKeyword || token.Token == limitKeyword || token.Token == offsetKeyword {
 			tokenizer.Index -= len(token.Matched)
 			break
 		}
@@ -527,7 +566,11 @@ outer:
 			column.Name = ""
 
 		case coma:
-			token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "column", id, groupExpression)
+			if isGroupBy {
+				token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "column", columnRef, id, groupExpression)
+			} else {
+				token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "column", id, groupExpression)
+			}
 			if err != nil {
 				return err
 			}
@@ -545,6 +588,9 @@ outer:
 			nextToken := tokenizer.Nexts(next...)
 			for _, candidate := range terminationTokens {
 				if nextToken.Token == candidate {
+					if isGroupBy {
+						tokenizer.Index -= len(nextToken.Matched)
+					}
 					break outer
 				}
 			}
@@ -612,14 +658,17 @@ func (qp *QueryParser) Parse(query string) (*QueryStatement, error) {
 	}
 	result.Table = token.Matched
 
-	token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "WHERE | GROUP BY | eof", eof, whereKeyword, groupKeyword, id)
+	token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "WHERE | GROUP BY | ORDER BY | LIMIT | eof", eof, whereKeyword, groupKeyword, orderKeyword, limitKeyword, offsetKeyword, id)
 	if err != nil {
 		return nil, err
 	}
 	//alias
 	if token.Token == id {
 		result.BaseStatement.Alias = token.Matched
-		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "WHERE | GROUP BY | eof", eof, whereKeyword, groupKeyword, id)
+		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "WHERE | GROUP BY | ORDER BY | LIMIT | eof", eof, whereKeyword, groupKeyword, orderKeyword, limitKeyword, offsetKeyword)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if token.Token == eof {
@@ -631,7 +680,7 @@ func (qp *QueryParser) Parse(query string) (*QueryStatement, error) {
 		if err != nil {
 			return nil, err
 		}
-		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "WHERE | eof", eof, groupKeyword)
+		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "GROUP BY | ORDER BY | LIMIT | eof", eof, groupKeyword, orderKeyword, limitKeyword, offsetKeyword)
 		if err != nil {
 			return nil, err
 		}
@@ -645,21 +694,132 @@ func (qp *QueryParser) Parse(query string) (*QueryStatement, error) {
 		if err != nil {
 			return nil, err
 		}
-		token, err = qp.expectWhitespaceFollowedBy(tokenizer, "column | groupExpression ", id, groupExpression, columnRef)
+		token, err = qp.expectWhitespaceFollowedBy(tokenizer, "column | groupExpression ", columnRef, id, groupExpression)
 		if err != nil {
 			return nil, err
 		}
 
-		err = qp.readQueryColumns(tokenizer, result, &result.GroupBy, token, true, eof)
+		err = qp.readQueryColumns(tokenizer, result, &result.GroupBy, token, true, eof, havingKeyword, orderKeyword, limitKeyword, offsetKeyword)
+		if err != nil {
+			return nil, err
+		}
+		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "HAVING | ORDER BY | LIMIT | eof", eof, havingKeyword, orderKeyword, limitKeyword, offsetKeyword)
 		if err != nil {
 			return nil, err
 		}
+	}
 
+	if token.Token == havingKeyword {
+		result.Having = &SQLCriteria{Criteria: make([]*SQLCriterion, 0)}
+		err = qp.readCriteria(tokenizer, result.Having, token)
+		if err != nil {
+			return nil, err
+		}
+		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "ORDER BY | LIMIT | eof", eof, orderKeyword, limitKeyword, offsetKeyword)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	if token.Token == orderKeyword {
+		if token, err = qp.readOrderBy(tokenizer, result); err != nil {
+			return nil, err
+		}
+	}
+	if token.Token == limitKeyword || token.Token == offsetKeyword {
+		if err = qp.readLimit(tokenizer, result, token); err != nil {
+			return nil, err
+		}
+	}
 	return result, nil
 }
 
+func (qp *QueryParser) readOrderBy(tokenizer *toolbox.Tokenizer, query *QueryStatement) (*toolbox.Token, error) {
+	_, err := qp.expectWhitespaceFollowedBy(tokenizer, "BY", byKeyword)
+	if err != nil {
+		return nil, err
+	}
+	query.OrderBy = make([]*SQLOrderColumn, 0)
+	for {
+		var token *toolbox.Token
+		if len(query.OrderBy) == 0 {
+			token, err = qp.expectWhitespaceFollowedBy(tokenizer, "column", columnRef, id)
+		} else {
+			token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "column", columnRef, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		column, err := buildColumn(token, tokenizer, query, true)
+		if err != nil {
+			return nil, err
+		}
+		if token.Token == id {
+			if expression := tokenizer.Next(groupExpression); expression.Token == groupExpression {
+				column.Expression = column.Name + expression.Matched
+				column.Function = column.Name
+				column.FunctionArguments = expression.Matched[1 : len(expression.Matched)-1]
+				column.Name = ""
+			}
+		}
+		orderColumn := &SQLOrderColumn{SQLColumn: column, Direction: "ASC"}
+		query.OrderBy = append(query.OrderBy, orderColumn)
+		token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "ASC | DESC | , | LIMIT | eof", eof, coma, directionKeyword, limitKeyword, offsetKeyword)
+		if err != nil {
+			return nil, err
+		}
+		if token.Token == directionKeyword {
+			orderColumn.Direction = strings.ToUpper(token.Matched)
+			token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, ", | LIMIT | eof", eof, coma, limitKeyword, offsetKeyword)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if token.Token != coma {
+			return token, nil
+		}
+	}
+}
+
+func (qp *QueryParser) readLimit(tokenizer *toolbox.Tokenizer, query *QueryStatement, token *toolbox.Token) error {
+	var err error
+	if token.Token == limitKeyword {
+		if token, err = qp.expectWhitespaceFollowedBy(tokenizer, "number", columnRef); err != nil {
+			return err
+		}
+		query.Limit, query.HasLimit = toolbox.AsInt(token.Matched), true
+		if token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, ", | OFFSET | eof", eof, coma, offsetKeyword); err != nil {
+			return err
+		}
+		if token.Token == coma { //LIMIT offset, count
+			if token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "number", columnRef); err != nil {
+				return err
+			}
+			query.Offset = query.Limit
+			query.Limit = toolbox.AsInt(token.Matched)
+			_, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "eof", eof)
+			return err
+		}
+	}
+	if token.Token == offsetKeyword {
+		if token, err = qp.expectWhitespaceFollowedBy(tokenizer, "number", columnRef); err != nil {
+			return err
+		}
+		query.Offset = toolbox.AsInt(token.Matched)
+		if token, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "LIMIT | eof", eof, limitKeyword); err != nil {
+			return err
+		}
+		if token.Token == limitKeyword {
+			if token, err = qp.expectWhitespaceFollowedBy(tokenizer, "number", columnRef); err != nil {
+				return err
+			}
+			query.Limit, query.HasLimit = toolbox.AsInt(token.Matched), true
+			_, err = qp.expectOptionalWhitespaceFollowedBy(tokenizer, "eof", eof)
+		}
+	}
+	return err
+}
+
 // NewQueryParser represents basic SQL query parser.
 func NewQueryParser() *QueryParser {
 	return &QueryParser{}
@@ -54,6 +54,99 @@ func TestAggregationQueryParser(t *testing.T) {
 
 }
 
+func TestQueryParser_OrderByLimit(t *testing.T) {
+	parser := dsc.NewQueryParser()
+	var useCases = []struct {
+		description string
+		SQL         string
+		orderBy     []string
+		directions  []string
+		criteria    int
+		groupBy     int
+		limit       int
+		hasLimit    bool
+		offset      int
+	}{
+		{
+			description: "order by",
+			SQL:         "SELECT id, name FROM users ORDER BY name",
+			orderBy:     []string{"name"},
+			directions:  []string{"ASC"},
+		},
+		{
+			description: "order by with direction and limit",
+			SQL:         "SELECT id, name FROM users u WHERE id > 1 ORDER BY name DESC, id asc LIMIT 10",
+			orderBy:     []string{"name", "id"},
+			directions:  []string{"DESC", "ASC"},
+			criteria:    1,
+			limit:       10,
+			hasLimit:    true,
+		},
+		{
+			description: "limit with offset",
+			SQL:         "SELECT id, name FROM users WHERE id > ? LIMIT 5 OFFSET 20",
+			criteria:    1,
+			limit:       5,
+			hasLimit:    true,
+			offset:      20,
+		},
+		{
+			description: "mysql limit",
+			SQL:         "SELECT * FROM users LIMIT 20, 5",
+			limit:       5,
+			hasLimit:    true,
+			offset:      20,
+		},
+		{
+			description: "zero limit",
+			SQL:         "SELECT * FROM users LIMIT 0",
+			hasLimit:    true,
+		},
+		{
+			description: "group by column references",
+			SQL:         "SELECT name, status, COUNT(*) FROM users GROUP BY 1, 2 HAVING COUNT(*) > 1",
+			groupBy:     2,
+		},
+		{
+			description: "column reference with group by",
+			SQL:         "SELECT name, COUNT(*) FROM users GROUP BY 1 ORDER BY 2 DESC,1 OFFSET 3",
+			orderBy:     []string{"", "name"},
+			directions:  []string{"DESC", "ASC"},
+			groupBy:     1,
+			offset:      3,
+		},
+	}
+	for _, useCase := range useCases {
+		query, err := parser.Parse(useCase.SQL)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, "users", query.Table, useCase.description)
+		assert.Equal(t, useCase.criteria, len(query.Criteria), useCase.description)
+		assert.Equal(t, useCase.groupBy, len(query.GroupBy), useCase.description)
+		assert.Equal(t, useCase.limit, query.Limit, useCase.description)
+		assert.Equal(t, useCase.hasLimit, query.HasLimit, useCase.description)
+		assert.Equal(t, useCase.offset, query.Offset, useCase.description)
+		if assert.Equal(t, len(useCase.orderBy), len(query.OrderBy), useCase.description) {
+			for i, column := range query.OrderBy {
+				assert.Equal(t, useCase.orderBy[i], column.Name, useCase.description)
+				assert.Equal(t, useCase.directions[i], column.Direction, useCase.description)
+			}
+		}
+	}
+	query, err := parser.Parse("SELECT name, COUNT(DISTINCT id) AS cnt FROM users GROUP BY 1 HAVING COUNT(DISTINCT id) > ? AND cnt < 10")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "name", query.GroupBy[0].Name)
+		if assert.NotNil(t, query.Having) && assert.Equal(t, 2, len(query.Having.Criteria)) {
+			assert.Equal(t, "COUNT(DISTINCT id)", query.Having.Criteria[0].LeftOperand)
+			assert.Equal(t, "?", query.Having.Criteria[0].RightOperand)
+			assert.Equal(t, "cnt", query.Having.Criteria[1].LeftOperand)
+		}
+	}
+	_, err = parser.Parse("SELECT id FROM users ORDER id")
+	assert.NotNil(t, err)
+}
+
 func TestQueryParser(t *testing.T) {
 	parser := dsc.NewQueryParser()
 	{
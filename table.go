@@ -4,17 +4,24 @@ import "fmt"
 
 //TableDescriptor represents a table details.
 type TableDescriptor struct {
-	Table          string
-	Autoincrement  bool
-	PkColumns      []string
-	Columns        []string
-	ColumnTypes    map[string]string
-	Nullables      map[string]bool
-	OrderColumns   []string
-	Schema         []map[string]interface{} //Schema to be interpreted by NoSQL drivers for create table operation .
-	SchemaURL      string                   //url with JSON to the TableDescriptor.Schema.
-	FromQuery      string                   //If table is query base then specify FromQuery
-	FromQueryAlias string
+	Table            string
+	Autoincrement    bool
+	PkColumns        []string
+	Columns          []string
+	ColumnTypes      map[string]string //go type of column field
+	Nullables        map[string]bool
+	SQLTypes         map[string]string //explicit column SQL type set with sqlType tag
+	ColumnSizes      map[string]int    //column size set with size tag
+	ColumnDefaults   map[string]string //column default expression set with default tag
+	UniqueColumns    []string
+	NotNullColumns   []string //columns explicitly declared NOT NULL with nullable:"false" tag
+	OrderColumns     []string
+	Schema           []map[string]interface{} //Schema to be interpreted by NoSQL drivers for create table operation .
+	SchemaURL        string                   //url with JSON to the TableDescriptor.Schema.
+	FromQuery        string                   //If table is query base then specify FromQuery
+	FromQueryAlias   string
+	VersionColumn    string //optimistic lock column, checked and incremented by update, checked by delete
+	SoftDeleteColumn string //column set to deletion time by delete instead of removing row, rows with not null value are excluded from queries
 }
 
 func (t *TableDescriptor) From() string {
@@ -0,0 +1,167 @@
+package dsc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//ErrForeignKeyCycle represents foreign key dependency cycle between tables
+var ErrForeignKeyCycle = errors.New("foreign key cycle")
+
+//TableRecords represents records of a table persisted or deleted with other tables
+type TableRecords struct {
+	Table       string
+	Records     interface{} //pointer to a slice of records
+	DmlProvider DmlProvider //optional, used by PersistAllTables
+	KeyProvider KeyGetter   //optional, used by DeleteAllTables
+}
+
+//tableDependencyOrder returns tables ordered parents first and tables that could not be ordered due to foreign key cycle,
+//self references and references to tables outside of passed in tables are ignored
+func tableDependencyOrder(manager Manager, tables []string) ([]string, []string, error) {
+	dialect := GetDatastoreDialect(manager.Config().DriverName)
+	if dialect == nil {
+		return nil, nil, fmt.Errorf("failed to lookup dialect for driver: %v", manager.Config().DriverName)
+	}
+	datastore, err := dialect.GetCurrentDatastore(manager)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pending = make([]string, 0, len(tables))
+	for _, table := range tables {
+		if !hasColumn(pending, table) {
+			pending = append(pending, table)
+		}
+	}
+	var parents = make(map[string][]string)
+	for _, table := range pending {
+		keys, err := dialect.GetForeignKeys(manager, datastore, table)
+		if errors.Is(err, errUnsupportedOperation) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get foreign keys of %v, %w", table, err)
+		}
+		for _, key := range keys {
+			if !strings.EqualFold(key.ReferencedTable, table) && hasColumn(pending, key.ReferencedTable) {
+				parents[table] = append(parents[table], key.ReferencedTable)
+			}
+		}
+	}
+	var result = make([]string, 0, len(pending))
+	for len(pending) > 0 {
+		var remaining = make([]string, 0, len(pending))
+		for _, table := range pending {
+			var ready = true
+			for _, parent := range parents[table] {
+				if !hasColumn(result, parent) {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				result = append(result, table)
+			} else {
+				remaining = append(remaining, table)
+			}
+		}
+		if len(remaining) == len(pending) {
+			return append(result, remaining...), remaining, nil
+		}
+		pending = remaining
+	}
+	return result, nil, nil
+}
+
+//SortTablesByDependency returns tables ordered by foreign key dependencies, referenced (parent) tables first, tables without dependencies keep passed in order.
+//If tables form a cycle, it returns ErrForeignKeyCycle with unresolved tables appended in passed in order.
+func SortTablesByDependency(manager Manager, tables ...string) ([]string, error) {
+	ordered, cycle, err := tableDependencyOrder(manager, tables)
+	if err != nil {
+		return nil, err
+	}
+	if len(cycle) > 0 {
+		return ordered, fmt.Errorf("%w: %v", ErrForeignKeyCycle, strings.Join(cycle, ", "))
+	}
+	return ordered, nil
+}
+
+//runInDependencyOrder runs handler for each table records in one transaction, parents first or children first if reverse,
+//foreign key cycle is handled by deferring constraints if dialect supports it, begin is called at the start of each (retried) transaction
+func runInDependencyOrder(manager Manager, records []*TableRecords, reverse bool, begin func(), handler func(connection Connection, records *TableRecords) error) error {
+	var tables = make([]string, 0, len(records))
+	var recordsByTable = make(map[string][]*TableRecords)
+	for _, item := range records {
+		table := strings.ToLower(item.Table)
+		tables = append(tables, item.Table)
+		recordsByTable[table] = append(recordsByTable[table], item)
+	}
+	ordered, cycle, err := tableDependencyOrder(manager, tables)
+	if err != nil {
+		return err
+	}
+	dialect := GetDatastoreDialect(manager.Config().DriverName)
+	var deferSQL string
+	if len(cycle) > 0 {
+		if deferSQL = dialect.DeferConstraintsSQL(); deferSQL == "" || !dialect.CanHandleTransaction() {
+			return fmt.Errorf("%w: %v, %v dialect can not defer constraints", ErrForeignKeyCycle, strings.Join(cycle, ", "), manager.Config().DriverName)
+		}
+	}
+	if reverse {
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+	return manager.WithTransaction(func(connection Connection) error {
+		begin()
+		if deferSQL != "" {
+			if _, err := manager.ExecuteOnConnection(connection, deferSQL, nil); err != nil {
+				return fmt.Errorf("failed to defer constraints, %w", err)
+			}
+		}
+		for _, table := range ordered {
+			for _, item := range recordsByTable[strings.ToLower(table)] {
+				if err := handler(connection, item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+//PersistAllTables persists records of all tables in one transaction, referenced (parent) tables first.
+//If tables form a foreign key cycle, constraints are deferred if dialect supports it, otherwise ErrForeignKeyCycle is returned.
+func PersistAllTables(manager Manager, records ...*TableRecords) (inserted int, updated int, err error) {
+	err = runInDependencyOrder(manager, records, false, func() { inserted, updated = 0, 0 }, func(connection Connection, item *TableRecords) error {
+		tableInserted, tableUpdated, err := manager.PersistAllOnConnection(connection, item.Records, item.Table, item.DmlProvider)
+		inserted += tableInserted
+		updated += tableUpdated
+		if err != nil {
+			return fmt.Errorf("failed to persist %v, %w", item.Table, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return inserted, updated, nil
+}
+
+//DeleteAllTables deletes records of all tables in one transaction, referencing (child) tables first.
+//If tables form a foreign key cycle, constraints are deferred if dialect supports it, otherwise ErrForeignKeyCycle is returned.
+func DeleteAllTables(manager Manager, records ...*TableRecords) (deleted int, err error) {
+	err = runInDependencyOrder(manager, records, true, func() { deleted = 0 }, func(connection Connection, item *TableRecords) error {
+		tableDeleted, err := manager.DeleteAllOnConnection(connection, item.Records, item.Table, item.KeyProvider)
+		deleted += tableDeleted
+		if err != nil {
+			return fmt.Errorf("failed to delete %v, %w", item.Table, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
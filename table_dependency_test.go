@@ -0,0 +1,91 @@
+package dsc_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/dsc"
+)
+
+type depCustomer struct {
+	Id   int `primaryKey:"true"`
+	Name string
+}
+
+type depOrder struct {
+	Id         int `primaryKey:"true"`
+	CustomerId int `column:"customer_id"`
+}
+
+type depItem struct {
+	Id      int `primaryKey:"true"`
+	OrderId int `column:"order_id"`
+}
+
+type depNode struct {
+	Id     int `primaryKey:"true"`
+	PeerId int `column:"peer_id"`
+}
+
+func TestPersistAllTables(t *testing.T) {
+	manager, err := dsc.NewManagerFactory().Create(dsc.NewConfig("sqlite3", "[url]", "url:"+filepath.Join(t.TempDir(), "dependency.db")+"?_foreign_keys=1"))
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer manager.ConnectionProvider().Close()
+	for _, SQL := range []string{
+		"DROP TABLE IF EXISTS dep_items",
+		"DROP TABLE IF EXISTS dep_orders",
+		"DROP TABLE IF EXISTS dep_customers",
+		"DROP TABLE IF EXISTS dep_nodes_a",
+		"DROP TABLE IF EXISTS dep_nodes_b",
+		"CREATE TABLE dep_customers (id INTEGER NOT NULL PRIMARY KEY, name VARCHAR(64))",
+		"CREATE TABLE dep_orders (id INTEGER NOT NULL PRIMARY KEY, customer_id INTEGER NOT NULL REFERENCES dep_customers(id))",
+		"CREATE TABLE dep_items (id INTEGER NOT NULL PRIMARY KEY, order_id INTEGER NOT NULL REFERENCES dep_orders(id))",
+		"CREATE TABLE dep_nodes_a (id INTEGER NOT NULL PRIMARY KEY, peer_id INTEGER REFERENCES dep_nodes_b(id))",
+		"CREATE TABLE dep_nodes_b (id INTEGER NOT NULL PRIMARY KEY, peer_id INTEGER REFERENCES dep_nodes_a(id))",
+	} {
+		_, err = manager.Execute(SQL)
+		if !assert.Nil(t, err, SQL) {
+			return
+		}
+	}
+
+	ordered, err := dsc.SortTablesByDependency(manager, "dep_items", "dep_orders", "dep_customers")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"dep_customers", "dep_orders", "dep_items"}, ordered)
+
+	var items = []*depItem{{Id: 1, OrderId: 10}}
+	var orders = []*depOrder{{Id: 10, CustomerId: 100}}
+	var customers = []*depCustomer{{Id: 100, Name: "c1"}}
+	_, _, err = manager.PersistAll(&items, "dep_items", nil)
+	assert.NotNil(t, err, "parent rows do not exist yet")
+	inserted, _, err := dsc.PersistAllTables(manager,
+		&dsc.TableRecords{Table: "dep_items", Records: &items},
+		&dsc.TableRecords{Table: "dep_orders", Records: &orders},
+		&dsc.TableRecords{Table: "dep_customers", Records: &customers},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, inserted)
+
+	deleted, err := dsc.DeleteAllTables(manager,
+		&dsc.TableRecords{Table: "dep_customers", Records: &customers},
+		&dsc.TableRecords{Table: "dep_orders", Records: &orders},
+		&dsc.TableRecords{Table: "dep_items", Records: &items},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, deleted)
+
+	_, err = dsc.SortTablesByDependency(manager, "dep_nodes_a", "dep_nodes_b")
+	assert.True(t, errors.Is(err, dsc.ErrForeignKeyCycle))
+	var nodesA = []*depNode{{Id: 1, PeerId: 2}}
+	var nodesB = []*depNode{{Id: 2, PeerId: 1}}
+	inserted, _, err = dsc.PersistAllTables(manager, //SQLite defers foreign key checks till commit
+		&dsc.TableRecords{Table: "dep_nodes_a", Records: &nodesA},
+		&dsc.TableRecords{Table: "dep_nodes_b", Records: &nodesB},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, inserted)
+}
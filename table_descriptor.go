@@ -3,6 +3,7 @@ package dsc
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 
 	"github.com/viant/toolbox"
@@ -41,6 +42,7 @@ func (r *commonTableDescriptorRegistry) getDescriptor(table string) *TableDescri
 	for _, column := range columns {
 		descriptor.Columns = append(descriptor.Columns, column.Name())
 	}
+	descriptor.SoftDeleteColumn = configSoftDeleteColumn(dbConfig, descriptor.Columns)
 	return descriptor
 }
 
@@ -100,15 +102,33 @@ func (d *TableDescriptor) HasSchema() bool {
 	return len(d.SchemaURL) > 0 || d.Schema != nil
 }
 
-//NewTableDescriptor creates a new table descriptor for passed in instance, it can use the following tags:"column", "dateLayout","dateFormat", "autoincrement", "primaryKey", "sequence", "transient"
+//NewTableDescriptor creates a new table descriptor for passed in instance, it can use the following tags:"column", "dateLayout","dateFormat", "autoincrement", "primaryKey", "sequence", "transient", "version" (or "optimisticLock"), "softDelete",
+//and "sqlType", "size", "nullable", "default", "unique" DDL tags. Columns follow struct fields order.
 func NewTableDescriptor(table string, instance interface{}) (*TableDescriptor, error) {
 	targetType := toolbox.DiscoverTypeByKind(instance, reflect.Struct)
 	var autoincrement bool
+	var versionColumn, softDeleteColumn string
 	var pkColumns = make([]string, 0)
 	var columns = make([]string, 0)
+	var uniqueColumns = make([]string, 0)
+	var notNullColumns = make([]string, 0)
+	var columnTypes = make(map[string]string)
+	var nullables = make(map[string]bool)
+	var sqlTypes = make(map[string]string)
+	var sizes = make(map[string]int)
+	var defaults = make(map[string]string)
 	columnToFieldMap := toolbox.NewFieldSettingByKey(targetType, "column")
-
+	var keys = make([]string, 0, len(columnToFieldMap))
 	for key := range columnToFieldMap {
+		keys = append(keys, key)
+	}
+	fieldOrder := make(map[string]int)
+	indexFields(targetType, fieldOrder)
+	sort.Slice(keys, func(i, j int) bool {
+		return fieldOrder[columnToFieldMap[keys[i]]["fieldName"]] < fieldOrder[columnToFieldMap[keys[j]]["fieldName"]]
+	})
+
+	for _, key := range keys {
 		mapping, _ := columnToFieldMap[key]
 		column, ok := mapping["column"]
 		if !ok {
@@ -121,7 +141,7 @@ func NewTableDescriptor(table string, instance interface{}) (*TableDescriptor, e
 		}
 	}
 
-	for key := range columnToFieldMap {
+	for _, key := range keys {
 		mapping, _ := columnToFieldMap[key]
 		column, ok := mapping["column"]
 		if !ok {
@@ -129,6 +149,33 @@ func NewTableDescriptor(table string, instance interface{}) (*TableDescriptor, e
 		}
 
 		columns = append(columns, column)
+		if field, ok := targetType.FieldByName(mapping["fieldName"]); ok {
+			columnTypes[column] = toolbox.DereferenceType(field.Type).String()
+			nullables[column] = isNullableField(field)
+			if nullable, ok := field.Tag.Lookup("nullable"); ok && !toolbox.AsBoolean(nullable) {
+				notNullColumns = append(notNullColumns, column)
+			}
+			if sqlType := field.Tag.Get("sqlType"); sqlType != "" {
+				sqlTypes[column] = sqlType
+			}
+			if size := toolbox.AsInt(field.Tag.Get("size")); size > 0 {
+				sizes[column] = size
+			}
+			if value, ok := field.Tag.Lookup("default"); ok {
+				defaults[column] = value
+			}
+			if toolbox.AsBoolean(field.Tag.Get("unique")) {
+				uniqueColumns = append(uniqueColumns, column)
+			}
+		}
+		if isTagEnabled(targetType, mapping["fieldName"], "softDelete") {
+			softDeleteColumn = column
+			continue
+		}
+		if isVersionField(targetType, mapping["fieldName"]) {
+			versionColumn = column
+			continue
+		}
 		if _, ok := mapping["primaryKey"]; ok {
 			if !toolbox.HasSliceAnyElements(pkColumns, column) {
 				pkColumns = append(pkColumns, column)
@@ -144,9 +191,58 @@ func NewTableDescriptor(table string, instance interface{}) (*TableDescriptor, e
 	}
 
 	return &TableDescriptor{
-		Table:         table,
-		Autoincrement: autoincrement,
-		Columns:       columns,
-		PkColumns:     pkColumns,
+		Table:            table,
+		Autoincrement:    autoincrement,
+		Columns:          columns,
+		PkColumns:        pkColumns,
+		VersionColumn:    versionColumn,
+		SoftDeleteColumn: softDeleteColumn,
+		ColumnTypes:      columnTypes,
+		Nullables:        nullables,
+		SQLTypes:         sqlTypes,
+		ColumnSizes:      sizes,
+		ColumnDefaults:   defaults,
+		UniqueColumns:    uniqueColumns,
+		NotNullColumns:   notNullColumns,
 	}, nil
 }
+
+//indexFields assigns position to struct fields, including fields of embedded structs
+func indexFields(targetType reflect.Type, positions map[string]int) {
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+		if fieldType := toolbox.DereferenceType(field.Type); field.Anonymous && fieldType.Kind() == reflect.Struct {
+			indexFields(fieldType, positions)
+			continue
+		}
+		if _, ok := positions[field.Name]; !ok {
+			positions[field.Name] = len(positions)
+		}
+	}
+}
+
+//isNullableField returns nullable tag value, or true for pointer, slice, map, interface and sql.Null* fields
+func isNullableField(field reflect.StructField) bool {
+	if nullable, ok := field.Tag.Lookup("nullable"); ok {
+		return toolbox.AsBoolean(nullable)
+	}
+	switch field.Type.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return strings.HasPrefix(field.Type.String(), "sql.Null")
+}
+
+//isVersionField returns true if field uses version:"true" or optimisticLock:"true" tag
+func isVersionField(targetType reflect.Type, fieldName string) bool {
+	return isTagEnabled(targetType, fieldName, "version") || isTagEnabled(targetType, fieldName, "optimisticLock")
+}
+
+//isTagEnabled returns true if field uses passed in tag with true value
+func isTagEnabled(targetType reflect.Type, fieldName, tag string) bool {
+	field, ok := targetType.FieldByName(fieldName)
+	if !ok {
+		return false
+	}
+	return toolbox.AsBoolean(field.Tag.Get(tag))
+}
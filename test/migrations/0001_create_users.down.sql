@@ -0,0 +1 @@
+DROP TABLE mig_users;
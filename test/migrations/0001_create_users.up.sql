@@ -0,0 +1,3 @@
+-- users table
+CREATE TABLE mig_users (id INTEGER NOT NULL PRIMARY KEY, name VARCHAR(255));
+INSERT INTO mig_users(id, name) VALUES(1, 'a;b');
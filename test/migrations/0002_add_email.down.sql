@@ -0,0 +1,3 @@
+CREATE TABLE mig_users_copy AS SELECT id, name FROM mig_users;
+DROP TABLE mig_users;
+ALTER TABLE mig_users_copy RENAME TO mig_users;
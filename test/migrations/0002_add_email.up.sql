@@ -0,0 +1 @@
+ALTER TABLE mig_users ADD COLUMN email VARCHAR(255);
@@ -0,0 +1 @@
+CREATE TABLE mig_orders (id INTEGER NOT NULL PRIMARY KEY, user_id INTEGER)
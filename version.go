@@ -0,0 +1,145 @@
+package dsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/viant/toolbox"
+)
+
+//ErrStaleObject represents optimistic lock failure, a row was modified or deleted by another transaction
+var ErrStaleObject = errors.New("stale object")
+
+//StaleObjectError represents optimistic lock failure with offending keys, it matches ErrStaleObject with errors.Is
+type StaleObjectError struct {
+	Table string
+	Keys  [][]interface{}
+}
+
+//Error returns error message
+func (e *StaleObjectError) Error() string {
+	var keys = make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		keys[i] = toolbox.AsString(key)
+	}
+	return fmt.Sprintf("%v: %v %v", ErrStaleObject, e.Table, strings.Join(keys, ","))
+}
+
+//Is returns true for ErrStaleObject
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+//versionedProvider represents dml provider that can read and set optimistic lock version
+type versionedProvider interface {
+	builder() *DmlBuilder
+	version(instance interface{}) int64
+	withVersion(instance interface{}, version int64) interface{}
+}
+
+//asVersionedProvider returns versioned provider if passed in provider uses optimistic lock column
+func asVersionedProvider(provider interface{}) (versionedProvider, bool) {
+	versioned, ok := provider.(versionedProvider)
+	if !ok || versioned.builder().TableDescriptor.VersionColumn == "" {
+		return nil, false
+	}
+	return versioned, true
+}
+
+//persistVersioned updates or deletes rows one by one checking affected rows, it returns StaleObjectError with all rows that were not affected
+func (m *AbstractManager) persistVersioned(ctx context.Context, connection Connection, items []interface{}, table string, sqlType int, provider versionedProvider) (int, error) {
+	dmlProvider := provider.(DmlProvider)
+	var stale *StaleObjectError
+	var processed = 0
+	hint := OperationPersist
+	if sqlType == SQLTypeDelete {
+		hint = OperationDelete
+	}
+	for _, item := range items {
+		parametrizedSQL := dmlProvider.Get(sqlType, item)
+		result, err := m.contextManager().ExecuteOnConnectionContext(withOperationHint(ctx, hint, table), connection, parametrizedSQL.SQL, parametrizedSQL.Values)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		if affected == 0 {
+			if stale == nil {
+				stale = &StaleObjectError{Table: table}
+			}
+			stale.Keys = append(stale.Keys, dmlProvider.Key(item))
+			continue
+		}
+		processed += int(affected)
+	}
+	if stale != nil {
+		return 0, stale
+	}
+	return processed, nil
+}
+
+//incrementVersions increments versions of updated items once update is committed, immediately if connection has no active transaction,
+//setItem is called with each item that has incremented version
+func incrementVersions(connection Connection, items []interface{}, provider versionedProvider, setItem func(index int, item interface{})) {
+	increment := func() {
+		for i, item := range items {
+			setItem(i, provider.withVersion(item, provider.version(item)+1))
+		}
+	}
+	if transactional, ok := connection.(transactionalConnection); ok && transactional.inTransaction() {
+		transactional.afterTransaction(func(committed bool) {
+			if committed {
+				increment()
+			}
+		})
+		return
+	}
+	increment()
+}
+
+//version returns optimistic lock version of passed in instance
+func (p *metaDmlProvider) version(instance interface{}) int64 {
+	var reflectable = reflect.ValueOf(instance)
+	if reflectable.Kind() == reflect.Ptr {
+		reflectable = reflectable.Elem()
+	}
+	return int64(toolbox.AsInt(p.readValue(reflectable, p.dmlBuilder.TableDescriptor.VersionColumn)))
+}
+
+//withVersion sets optimistic lock version, it returns updated instance, a copy if instance was not a pointer
+func (p *metaDmlProvider) withVersion(instance interface{}, version int64) interface{} {
+	columnSetting := p.columnToFieldNameMap[strings.ToLower(p.dmlBuilder.TableDescriptor.VersionColumn)]
+	var reflectable = reflect.ValueOf(instance)
+	var target = reflectable
+	if reflectable.Kind() == reflect.Ptr {
+		target = reflectable.Elem()
+	} else {
+		target = reflect.New(reflectable.Type()).Elem()
+		target.Set(reflectable)
+	}
+	field := target.FieldByName(columnSetting["fieldName"])
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(version)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(version))
+	}
+	if reflectable.Kind() == reflect.Ptr {
+		return instance
+	}
+	return target.Interface()
+}
+
+func (p *mapDmlProvider) version(instance interface{}) int64 {
+	return int64(toolbox.AsInt(toolbox.AsMap(instance)[p.tableDescriptor.VersionColumn]))
+}
+
+func (p *mapDmlProvider) withVersion(instance interface{}, version int64) interface{} {
+	toolbox.AsMap(instance)[p.tableDescriptor.VersionColumn] = version
+	return instance
+}